@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// builtins.go - Built-in Template Variables
+// Purpose: Resolve reserved {{@name}} variables (date, uuid, git branch...) at fill time
+//
+// Built-ins never show up as form fields. The detail view resolves them when a card
+// opens (and again after each copy), so the preview shows exactly what gets copied
+// and the next copy gets a fresh {{@uuid}}.
+
+// builtinPrefix marks a reserved variable
+const builtinPrefix = "@"
+
+// builtinCommandTimeout bounds shell-outs like git and clipboard reads
+const builtinCommandTimeout = 2 * time.Second
+
+// isBuiltinVar reports whether the inside of {{...}} is a built-in variable
+func isBuiltinVar(raw string) bool {
+	return strings.HasPrefix(strings.TrimSpace(raw), builtinPrefix)
+}
+
+// resolveBuiltinVar evaluates a built-in like "@date:2006-01-02" or "@env:HOME::shellquote|fallback"
+// The default after | is used when the built-in resolves to nothing or fails; ::filters apply to either
+func resolveBuiltinVar(raw string, now time.Time) (string, error) {
+	spec, filters := splitFilters(strings.TrimSpace(raw))
+	if err := checkFilters(filters); err != nil {
+		return "", err
+	}
+	_, defaultVal := ParseDefaultValue(spec)
+	name, arg := splitBuiltin(spec)
+
+	value, err := evalBuiltin(name, arg, now)
+	if (err != nil || value == "") && defaultVal != "" {
+		return applyFilters(defaultVal, filters), nil
+	}
+	if err == nil && value == "" {
+		err = fmt.Errorf("empty")
+	}
+	return applyFilters(value, filters), err
+}
+
+// builtinNames lists the built-ins evalBuiltin knows (for linting)
+var builtinNames = map[string]bool{
+	"date": true, "time": true, "uuid": true, "env": true,
+	"cwd": true, "hostname": true, "git.branch": true, "clipboard": true,
+}
+
+// splitBuiltin splits "@env:HOME::upper|x" into its name ("env") and argument ("HOME")
+func splitBuiltin(raw string) (string, string) {
+	raw, _ = splitFilters(strings.TrimSpace(raw))
+	spec, _ := ParseDefaultValue(strings.TrimPrefix(raw, builtinPrefix))
+	name, arg, _ := strings.Cut(spec, ":")
+	return strings.TrimSpace(name), arg
+}
+
+// evalBuiltin computes one built-in value
+func evalBuiltin(name, arg string, now time.Time) (string, error) {
+	switch name {
+	case "date":
+		if arg == "" {
+			arg = "2006-01-02"
+		}
+		return now.Format(arg), nil
+
+	case "time":
+		if arg == "" {
+			arg = "15:04"
+		}
+		return now.Format(arg), nil
+
+	case "uuid":
+		return newUUID()
+
+	case "env":
+		if arg == "" {
+			return "", fmt.Errorf("@env needs a variable name")
+		}
+		value, ok := os.LookupEnv(arg)
+		if !ok {
+			return "", fmt.Errorf("$%s is not set", arg)
+		}
+		return value, nil
+
+	case "cwd":
+		return os.Getwd()
+
+	case "hostname":
+		return os.Hostname()
+
+	case "git.branch":
+		return runBuiltinCommand("git", "rev-parse", "--abbrev-ref", "HEAD")
+
+	case "clipboard":
+		return readClipboardSync()
+	}
+
+	return "", fmt.Errorf("unknown built-in @%s", name)
+}
+
+// runBuiltinCommand runs a command in the working directory and returns its trimmed output
+func runBuiltinCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), builtinCommandTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// builtinResult is a resolved built-in shown under the template form
+type builtinResult struct {
+	Raw   string // Inside of {{...}}, e.g. "@date:2006-01-02"
+	Value string
+	Err   error
+}
+
+// resolveBuiltins evaluates every built-in in content once (in order of appearance)
+func resolveBuiltins(content string, now time.Time) []builtinResult {
+	var results []builtinResult
+	seen := make(map[string]bool)
+	for _, tag := range templateTags(content) {
+		raw := tag.Text
+		if !isBuiltinVar(raw) || seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		value, err := resolveBuiltinVar(raw, now)
+		results = append(results, builtinResult{Raw: raw, Value: value, Err: err})
+	}
+	return results
+}
+
+// builtinsResolvedMsg carries built-in values resolved in the background
+type builtinsResolvedMsg struct {
+	cardID  string
+	results []builtinResult
+}
+
+// refreshBuiltinVars resolves the card's built-ins in the background
+// Until they arrive the built-ins are stored empty, so rendering never shells out
+// (and copying waits for them, see builtinsError)
+func (m *Model) refreshBuiltinVars(card *Card) tea.Cmd {
+	if card == nil {
+		return nil
+	}
+	// Drop the previous card's built-ins (user values carry over between cards as before)
+	for raw := range m.TemplateVars {
+		if isBuiltinVar(raw) {
+			delete(m.TemplateVars, raw)
+		}
+	}
+	m.BuiltinResults = nil
+
+	cardID, content := card.ID, m.expandCard(card).Content
+	pending := false
+	for _, tag := range templateTags(content) {
+		if isBuiltinVar(tag.Text) {
+			m.TemplateVars[tag.Text] = ""
+			pending = true
+		}
+	}
+	if !pending {
+		return nil
+	}
+
+	return func() tea.Msg {
+		return builtinsResolvedMsg{cardID: cardID, results: resolveBuiltins(content, time.Now())}
+	}
+}
+
+// hasBuiltinVars reports whether the open card uses any built-ins
+func (m *Model) hasBuiltinVars() bool {
+	for raw := range m.TemplateVars {
+		if isBuiltinVar(raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBuiltinResults stores resolved built-ins (keyed by their raw text) for the preview and copy
+func (m *Model) applyBuiltinResults(msg builtinsResolvedMsg) {
+	card := m.getSelectedCard()
+	if m.ViewMode != ViewDetail || card == nil || card.ID != msg.cardID {
+		return
+	}
+	m.BuiltinResults = msg.results
+	for _, result := range msg.results {
+		// A failure stays empty: the preview marks it missing and builtinsError blocks the copy
+		m.TemplateVars[result.Raw] = result.Value
+	}
+}
+
+// builtinsError returns why the open card can't be copied with its built-ins yet:
+// they're still resolving, or one failed and would be copied as its raw {{@tag}}
+func (m *Model) builtinsError() error {
+	if !m.hasBuiltinVars() {
+		return nil
+	}
+	if m.BuiltinResults == nil {
+		return fmt.Errorf("built-ins are still resolving")
+	}
+	for _, result := range m.BuiltinResults {
+		if result.Err != nil {
+			return fmt.Errorf("{{%s}}: %w", result.Raw, result.Err)
+		}
+	}
+	return nil
+}
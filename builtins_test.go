@@ -0,0 +1,124 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveBuiltinVar(t *testing.T) {
+	now := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	t.Setenv("CELLBLOCKS_TEST_VAR", "hello")
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"date default layout", "@date", "2024-03-05", false},
+		{"date custom layout", "@date:Jan 2, 2006", "Mar 5, 2024", false},
+		{"time default layout", "@time", "14:07", false},
+		{"time custom layout", "@time:15:04:05", "14:07:09", false},
+		{"surrounding spaces", " @date ", "2024-03-05", false},
+		{"env set", "@env:CELLBLOCKS_TEST_VAR", "hello", false},
+		{"env unset", "@env:CELLBLOCKS_UNSET_VAR", "", true},
+		{"env unset with default", "@env:CELLBLOCKS_UNSET_VAR|fallback", "fallback", false},
+		{"env without name", "@env", "", true},
+		{"unknown built-in", "@nope", "", true},
+		{"unknown with default", "@nope|x", "x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBuiltinVar(tt.raw, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveBuiltinVar(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveBuiltinVar(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUUID(t *testing.T) {
+	v4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, err := newUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := newUUID()
+	if !v4.MatchString(first) {
+		t.Errorf("newUUID() = %q, not a v4 UUID", first)
+	}
+	if first == second {
+		t.Errorf("newUUID() returned %q twice", first)
+	}
+}
+
+func TestBuiltinsInTemplates(t *testing.T) {
+	content := "Report {{@date}} by {{author}} on {{@env:CELLBLOCKS_UNSET_VAR}} ({{@uuid}})"
+
+	// Built-ins never become form fields
+	vars := ExtractVariables(content)
+	if len(vars) != 1 || vars[0] != "author" {
+		t.Errorf("ExtractVariables() = %v, want [author]", vars)
+	}
+
+	// Pre-resolved values (keyed by raw text) are used as-is; empty ones keep the placeholder
+	got := FillTemplate(content, map[string]string{
+		"author":                    "Matt",
+		"@date":                     "2024-03-05",
+		"@env:CELLBLOCKS_UNSET_VAR": "",
+		"@uuid":                     "fixed",
+	})
+	want := "Report 2024-03-05 by Matt on {{@env:CELLBLOCKS_UNSET_VAR}} (fixed)"
+	if got != want {
+		t.Errorf("FillTemplate() = %q, want %q", got, want)
+	}
+
+	// Without a stored value built-ins resolve live
+	got = FillTemplate("{{@date:2006}}", map[string]string{})
+	if got != time.Now().Format("2006") {
+		t.Errorf("FillTemplate() live built-in = %q", got)
+	}
+
+	results := resolveBuiltins(content+" {{@date}}", time.Now())
+	if len(results) != 3 {
+		t.Fatalf("resolveBuiltins() returned %d results, want 3 (unique)", len(results))
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "not set") {
+		t.Errorf("resolveBuiltins() unset env error = %v", results[1].Err)
+	}
+}
+
+func TestCopyWaitsForBuiltins(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{
+		{ID: "a", Title: "Branch", Content: "git push origin {{@env:CELLBLOCKS_TEST_BRANCH}}"},
+	}})
+	t.Setenv("CELLBLOCKS_TEST_BRANCH", "main")
+	resolve := m.openDetailView()
+	card := m.getSelectedCard()
+
+	// Still resolving: the copy would keep the raw {{@tag}}
+	if m.copyFilledTemplate(card) != nil || !strings.Contains(m.ReloadMessage, "still resolving") {
+		t.Errorf("copied before the built-ins resolved (message %q)", m.ReloadMessage)
+	}
+
+	// A failed built-in blocks the copy too
+	m.applyBuiltinResults(builtinsResolvedMsg{cardID: "a", results: resolveBuiltins("{{@env:CELLBLOCKS_UNSET_VAR}}", time.Now())})
+	if m.copyFilledTemplate(card) != nil || !strings.Contains(m.ReloadMessage, "not set") {
+		t.Errorf("copied with a failed built-in (message %q)", m.ReloadMessage)
+	}
+
+	if resolve == nil {
+		t.Fatal("opening the card didn't resolve its built-ins")
+	}
+	m.applyBuiltinResults(builtinsResolvedMsg{cardID: "a", results: resolveBuiltins(card.Content, time.Now())})
+	if m.copyFilledTemplate(card) == nil {
+		t.Errorf("resolved built-ins not copied: %q", m.ReloadMessage)
+	}
+}
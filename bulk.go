@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bulk.go - Multi-Selection and Bulk Actions
+// Purpose: Mark several cards and copy, move, tag, delete or export them at once
+
+// toggleMark marks or unmarks a card
+func (m *Model) toggleMark(cardID string) {
+	if m.MarkedCards[cardID] {
+		delete(m.MarkedCards, cardID)
+	} else {
+		m.MarkedCards[cardID] = true
+	}
+}
+
+// markRange marks every card between two filtered indices (inclusive)
+func (m *Model) markRange(from, to int) {
+	if from > to {
+		from, to = to, from
+	}
+	for i := max(0, from); i <= to && i < len(m.FilteredCards); i++ {
+		m.MarkedCards[m.FilteredCards[i].ID] = true
+	}
+}
+
+// toggleMarkAll marks every filtered card, or clears marks if they're all marked already
+func (m *Model) toggleMarkAll() {
+	allMarked := len(m.FilteredCards) > 0
+	for _, card := range m.FilteredCards {
+		if !m.MarkedCards[card.ID] {
+			allMarked = false
+			break
+		}
+	}
+
+	if allMarked {
+		m.clearMarks()
+		return
+	}
+	for _, card := range m.FilteredCards {
+		m.MarkedCards[card.ID] = true
+	}
+}
+
+// clearMarks removes all marks
+func (m *Model) clearMarks() {
+	m.MarkedCards = make(map[string]bool)
+}
+
+// getMarkedCards returns marked cards in display order
+// Cards hidden by the current filters are appended in library order
+func (m *Model) getMarkedCards() []Card {
+	if len(m.MarkedCards) == 0 || m.Data == nil {
+		return nil
+	}
+
+	var cards []Card
+	seen := make(map[string]bool)
+	for _, card := range m.FilteredCards {
+		if m.MarkedCards[card.ID] {
+			cards = append(cards, card)
+			seen[card.ID] = true
+		}
+	}
+	for _, card := range m.Data.Cards {
+		if m.MarkedCards[card.ID] && !seen[card.ID] {
+			cards = append(cards, card)
+		}
+	}
+
+	return cards
+}
+
+// getActionTargets returns the marked cards, or just the selected card if none are marked
+func (m *Model) getActionTargets() []Card {
+	if marked := m.getMarkedCards(); len(marked) > 0 {
+		return marked
+	}
+	if card := m.getSelectedCard(); card != nil {
+		return []Card{*card}
+	}
+	return nil
+}
+
+// describeTargets returns "'Title'" for one card or "N cards" for several
+func describeTargets(cards []Card) string {
+	if len(cards) == 1 {
+		return fmt.Sprintf("'%s'", cards[0].Title)
+	}
+	return fmt.Sprintf("%d cards", len(cards))
+}
+
+// copyMarkedCards copies the concatenated content of all marked cards
+func (m *Model) copyMarkedCards() tea.Cmd {
+	cards := m.getMarkedCards()
+	if len(cards) == 0 {
+		return nil
+	}
+
+	contents := make([]string, len(cards))
+	ids := make([]string, len(cards))
+	for i, card := range cards {
+		contents[i] = m.plainCopyText(&card)
+		ids[i] = card.ID
+	}
+	return copyCardsToClipboard(strings.Join(contents, m.Config.BulkCopySeparator), ids, false)
+}
+
+// confirmDeleteTargets asks before moving the marked (or selected) cards to the trash
+func (m *Model) confirmDeleteTargets() {
+	cards := m.getActionTargets()
+	if len(cards) == 0 {
+		return
+	}
+	if len(cards) == 1 {
+		m.confirmDeleteCard(&cards[0])
+		return
+	}
+
+	m.askConfirm(fmt.Sprintf("Move %d cards to trash?", len(cards)),
+		func(m *Model) tea.Cmd {
+			now := time.Now().UnixMilli()
+			var commands []libraryCommand
+			for _, card := range cards {
+				commands = append(commands, trashCardCommand{card: card, deletedAt: now})
+			}
+			m.clearMarks()
+			return m.execute(batchCommand{label: fmt.Sprintf("delete %d cards", len(cards)), commands: commands},
+				fmt.Sprintf("🗑 Moved %d cards to trash (u to undo)", len(cards)))
+		})
+}
+
+// pickCategoryForTargets opens a category picker and moves the marked (or selected) cards
+func (m *Model) pickCategoryForTargets() {
+	cards := m.getActionTargets()
+	if len(cards) == 0 || m.Data == nil {
+		return
+	}
+
+	options := make([]PickerOption, 0, len(m.Data.Categories))
+	for _, cat := range m.Data.Categories {
+		options = append(options, PickerOption{Label: cat.Name, Value: cat.ID, Color: cat.Color})
+	}
+
+	m.askPick(fmt.Sprintf("Move %s to category:", describeTargets(cards)), options,
+		func(m *Model, categoryID string) tea.Cmd {
+			now := time.Now().UnixMilli()
+			var commands []libraryCommand
+			for _, card := range cards {
+				if card.CategoryID == categoryID {
+					continue
+				}
+				updated := card
+				updated.CategoryID = categoryID
+				updated.UpdatedAt = now
+				commands = append(commands, updateCardCommand{verb: "move", before: card, after: updated})
+			}
+			if len(commands) == 0 {
+				return nil
+			}
+
+			categoryName := m.CategoryMap[categoryID].Name
+			return m.execute(batchCommand{label: fmt.Sprintf("move %s", describeTargets(cards)), commands: commands},
+				fmt.Sprintf("📁 Moved %s to %s", describeTargets(cards), categoryName))
+		})
+}
+
+// promptTagsForTargets asks for tags and adds/removes them on the marked (or selected) cards
+func (m *Model) promptTagsForTargets() {
+	cards := m.getActionTargets()
+	if len(cards) == 0 {
+		return
+	}
+
+	m.askPrompt(fmt.Sprintf("Tag %s (comma-separated, -tag removes):", describeTargets(cards)), "",
+		func(m *Model, value string) tea.Cmd {
+			add, remove := parseTagInput(value)
+			if len(add) == 0 && len(remove) == 0 {
+				return nil
+			}
+
+			now := time.Now().UnixMilli()
+			var commands []libraryCommand
+			for _, card := range cards {
+				tags := applyTagChanges(card.Tags, add, remove)
+				if strings.Join(tags, ",") == strings.Join(card.Tags, ",") {
+					continue
+				}
+				updated := card
+				updated.Tags = tags
+				updated.UpdatedAt = now
+				commands = append(commands, updateCardCommand{verb: "tag", before: card, after: updated})
+			}
+			if len(commands) == 0 {
+				return nil
+			}
+
+			return m.execute(batchCommand{label: fmt.Sprintf("tag %s", describeTargets(cards)), commands: commands},
+				fmt.Sprintf("🏷 Tagged %s", describeTargets(cards)))
+		})
+}
+
+// parseTagInput splits "a, b, -c" into tags to add and tags to remove
+func parseTagInput(input string) (add, remove []string) {
+	for _, part := range strings.Split(input, ",") {
+		tag := strings.TrimSpace(part)
+		if strings.HasPrefix(tag, "-") {
+			if tag = strings.TrimSpace(tag[1:]); tag != "" {
+				remove = append(remove, tag)
+			}
+		} else if tag != "" {
+			add = append(add, strings.TrimPrefix(tag, "#"))
+		}
+	}
+	return add, remove
+}
+
+// applyTagChanges returns a new tag list with additions appended and removals dropped
+// Tags are compared case-insensitively; existing order is preserved
+func applyTagChanges(tags, add, remove []string) []string {
+	removed := make(map[string]bool)
+	for _, tag := range remove {
+		removed[strings.ToLower(tag)] = true
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range append(append([]string{}, tags...), add...) {
+		key := strings.ToLower(tag)
+		if removed[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+
+	return result
+}
+
+// exportMarkedCards writes the marked (or selected) cards to a JSON file in ExportDir
+// The file uses the cellblocks-data.json format so it can be imported elsewhere
+func (m *Model) exportMarkedCards() tea.Cmd {
+	cards := m.getActionTargets()
+	if len(cards) == 0 || m.Data == nil {
+		return nil
+	}
+
+	// Include only the categories the exported cards use
+	used := make(map[string]bool)
+	for _, card := range cards {
+		used[card.CategoryID] = true
+	}
+	var categories []Category
+	for _, cat := range m.Data.Categories {
+		if used[cat.ID] {
+			categories = append(categories, cat)
+		}
+	}
+
+	now := time.Now()
+	export := CellBlocksData{
+		Version:    m.Data.Version,
+		ExportedAt: now.Format(time.RFC3339),
+		Cards:      cards,
+		Categories: categories,
+	}
+	dir := expandPath(m.Config.ExportDir)
+	path := filepath.Join(dir, fmt.Sprintf("cellblocks-export-%s.json", now.Format("20060102-150405")))
+
+	// Failures are shown in the status bar - a bad export dir mustn't end the session
+	return func() tea.Msg {
+		content, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return exportErrorMsg{err: fmt.Errorf("failed to marshal export: %w", err)}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return exportErrorMsg{err: fmt.Errorf("failed to create export directory: %w", err)}
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return exportErrorMsg{err: fmt.Errorf("failed to write export file: %w", err)}
+		}
+		return cardsExportedMsg{path: path, count: len(cards)}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTagInput(t *testing.T) {
+	add, remove := parseTagInput(" docker, #k8s , -old,, - ")
+	if !reflect.DeepEqual(add, []string{"docker", "k8s"}) {
+		t.Errorf("add = %v, want [docker k8s]", add)
+	}
+	if !reflect.DeepEqual(remove, []string{"old"}) {
+		t.Errorf("remove = %v, want [old]", remove)
+	}
+}
+
+func TestApplyTagChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		add      []string
+		remove   []string
+		expected []string
+	}{
+		{"add to empty", nil, []string{"a", "b"}, nil, []string{"a", "b"}},
+		{"no duplicates", []string{"a"}, []string{"A", "b"}, nil, []string{"a", "b"}},
+		{"remove case-insensitive", []string{"Docker", "git"}, nil, []string{"docker"}, []string{"git"}},
+		{"remove everything", []string{"a"}, nil, []string{"a"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := applyTagChanges(tt.tags, tt.add, tt.remove)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("applyTagChanges() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// bulkTestModel returns a model with five cards: a, c in docker and b, d, e in git
+func bulkTestModel() Model {
+	return testModel(&CellBlocksData{
+		Cards: []Card{
+			{ID: "a", Title: "A", CategoryID: "docker"},
+			{ID: "b", Title: "B", CategoryID: "git"},
+			{ID: "c", Title: "C", CategoryID: "docker"},
+			{ID: "d", Title: "D", CategoryID: "git"},
+			{ID: "e", Title: "E", CategoryID: "git"},
+		},
+		Categories: []Category{{ID: "docker", Name: "Docker"}, {ID: "git", Name: "Git"}},
+	})
+}
+
+// markedIDs returns the IDs of getMarkedCards in order
+func markedIDs(m *Model) []string {
+	var ids []string
+	for _, card := range m.getMarkedCards() {
+		ids = append(ids, card.ID)
+	}
+	return ids
+}
+
+func TestMarkRangeFiltered(t *testing.T) {
+	m := bulkTestModel()
+
+	// Indices refer to the filtered view, not the library
+	m.SelectedCategories = map[string]bool{"git": true}
+	m.updateFilteredCards()
+	m.markRange(2, 1)
+	if ids := markedIDs(&m); !reflect.DeepEqual(ids, []string{"d", "e"}) {
+		t.Errorf("git view range 1-2 marked %v, want [d e]", ids)
+	}
+
+	// Out-of-range ends are clamped
+	m.SelectedCategories = map[string]bool{"docker": true}
+	m.updateFilteredCards()
+	m.markRange(-3, 10)
+
+	// Visible marks come first, marks hidden by the filter follow in library order
+	if ids := markedIDs(&m); !reflect.DeepEqual(ids, []string{"a", "c", "d", "e"}) {
+		t.Errorf("getMarkedCards() = %v, want [a c d e]", ids)
+	}
+}
+
+func TestToggleMarkAll(t *testing.T) {
+	m := bulkTestModel()
+	m.SelectedCategories = map[string]bool{"git": true}
+	m.updateFilteredCards()
+
+	// Partly marked view: marks the rest
+	m.toggleMark("d")
+	m.toggleMarkAll()
+	if ids := markedIDs(&m); !reflect.DeepEqual(ids, []string{"b", "d", "e"}) {
+		t.Fatalf("toggleMarkAll() marked %v, want [b d e]", ids)
+	}
+
+	// Fully marked view: clears
+	m.toggleMarkAll()
+	if ids := markedIDs(&m); len(ids) != 0 {
+		t.Errorf("second toggleMarkAll() left %v marked", ids)
+	}
+
+	// An empty view has nothing to mark
+	m.SelectedCategories = map[string]bool{"missing": true}
+	m.updateFilteredCards()
+	m.toggleMarkAll()
+	if len(m.MarkedCards) != 0 {
+		t.Errorf("toggleMarkAll() on empty view marked %v", m.MarkedCards)
+	}
+}
+
+func TestBulkDeleteSingleUndo(t *testing.T) {
+	m := bulkTestModel()
+	for _, id := range []string{"a", "c", "e"} {
+		m.toggleMark(id)
+	}
+
+	m.confirmDeleteTargets()
+	if m.Confirm == nil || m.Confirm.Message != "Move 3 cards to trash?" {
+		t.Fatalf("confirm dialog = %+v, want one question for 3 cards", m.Confirm)
+	}
+	if m.Confirm.OnConfirm(&m) == nil {
+		t.Fatal("confirming didn't save")
+	}
+	if len(m.Data.Cards) != 2 || len(m.Data.Trash) != 3 || len(m.MarkedCards) != 0 {
+		t.Fatalf("after delete: %d cards, %d trashed, %d marked; want 2, 3, 0",
+			len(m.Data.Cards), len(m.Data.Trash), len(m.MarkedCards))
+	}
+	if len(m.UndoStack) != 1 {
+		t.Fatalf("undo stack has %d entries, want 1 batch", len(m.UndoStack))
+	}
+
+	m.undo()
+	if len(m.Data.Cards) != 5 || len(m.Data.Trash) != 0 || len(m.UndoStack) != 0 {
+		t.Errorf("after one undo: %d cards, %d trashed, %d undo entries; want 5, 0, 0",
+			len(m.Data.Cards), len(m.Data.Trash), len(m.UndoStack))
+	}
+}
+
+func TestExportMarkedCards(t *testing.T) {
+	m := bulkTestModel()
+	m.toggleMark("a")
+	m.toggleMark("d")
+
+	// A missing export directory is created
+	m.Config.ExportDir = filepath.Join(t.TempDir(), "exports")
+	msg, ok := m.exportMarkedCards()().(cardsExportedMsg)
+	if !ok || msg.count != 2 {
+		t.Fatalf("export = %+v, want 2 cards exported", msg)
+	}
+	if _, err := os.Stat(msg.path); err != nil {
+		t.Errorf("export file: %v", err)
+	}
+
+	// A directory that can't be created is reported in the status bar, not as a fatal error
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.Config.ExportDir = filepath.Join(blocker, "exports")
+	model, _ := m.Update(m.exportMarkedCards()())
+	m = model.(Model)
+	if m.Error != nil || !strings.Contains(m.ReloadMessage, "export directory") {
+		t.Errorf("failed export: error %v, message %q", m.Error, m.ReloadMessage)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// cli.go - Command Line Subcommands
+// Purpose: Run CellBlocks templates from scripts without opening the TUI
+//
+//	cellblocks-tui fill "<title or id>" --var port=8080 --var image=nginx
+//
+// fill prints the filled card to stdout. Missing or invalid variables are an error
+// (exit 1) unless --interactive asks for them on the terminal; --copy sends the
+// result to the clipboard instead of stdout. {{secret:name}} values come from the
+// providers in config.json, as in the TUI.
+
+// cliUsage is printed by "cellblocks-tui help" and for unknown commands
+const cliUsage = `Usage:
+  cellblocks-tui                         Open the TUI
+  cellblocks-tui fill <title or id> [flags]
+                                         Print a card with its {{variables}} filled
+
+Run "cellblocks-tui fill -h" for the fill flags.`
+
+// runCLI runs a subcommand and returns the process exit code
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "fill":
+		return runFill(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprintln(stdout, cliUsage)
+		return 0
+	}
+	fmt.Fprintf(stderr, "cellblocks-tui: unknown command %q\n\n%s\n", args[0], cliUsage)
+	return 2
+}
+
+// fillOptions are the parsed arguments of "fill"
+type fillOptions struct {
+	Target      string            // Card title (case-insensitive) or ID
+	Vars        map[string]string // --var name=value
+	Interactive bool              // Ask for missing variables on the terminal
+	Copy        bool              // Copy the result instead of printing it
+	DataPath    string            // Data file (--data, defaults to DefaultDataPath)
+	Secrets     SecretsConfig     // Where {{secret:name}} values come from (config.json)
+}
+
+// varFlags collects repeated --var name=value flags
+type varFlags map[string]string
+
+func (v varFlags) String() string {
+	return ""
+}
+
+func (v varFlags) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if name = strings.TrimSpace(name); !ok || name == "" {
+		return fmt.Errorf("want name=value, got %q", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// parseFillArgs parses the arguments of "fill" (flags may come before or after the card)
+func parseFillArgs(args []string, output io.Writer) (fillOptions, error) {
+	opts := fillOptions{Vars: make(map[string]string)}
+
+	fs := flag.NewFlagSet("fill", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Var(varFlags(opts.Vars), "var", "set a variable: name=value (repeatable)")
+	fs.BoolVar(&opts.Interactive, "interactive", false, "ask for missing variables on the terminal")
+	fs.BoolVar(&opts.Interactive, "i", false, "shorthand for --interactive")
+	fs.BoolVar(&opts.Copy, "copy", false, "copy the result to the clipboard instead of printing it")
+	fs.StringVar(&opts.DataPath, "data", DefaultDataPath, "CellBlocks data file")
+	fs.Usage = func() {
+		fmt.Fprintln(output, "Usage: cellblocks-tui fill <title or id> [--var name=value]... [--interactive] [--copy]")
+		fs.PrintDefaults()
+	}
+
+	// The flag package stops at the first argument that isn't a flag
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return opts, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return opts, fmt.Errorf("fill needs exactly one card title or id, got %d", len(positional))
+	}
+	opts.Target = positional[0]
+	return opts, nil
+}
+
+// runFill runs "fill" and returns the exit code
+func runFill(args []string, stdout, stderr io.Writer) int {
+	opts, err := parseFillArgs(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "cellblocks-tui fill: %v\n", err)
+		return 2
+	}
+
+	data, err := LoadData(opts.DataPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "cellblocks-tui fill: %v\n", err)
+		return 1
+	}
+	config, err := LoadConfig(DefaultConfigPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: %v (using defaults)\n", err)
+	}
+	opts.Secrets = config.Secrets
+
+	var prompt fillPrompt
+	if opts.Interactive {
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			// No terminal to ask on (e.g. Windows) - prompts go through stdin/stderr
+			prompt = newFillPrompter(os.Stdin, stderr)
+		} else {
+			defer tty.Close()
+			prompt = newFillPrompter(tty, tty)
+		}
+	}
+
+	filled, err := fillCard(data, opts, prompt, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "cellblocks-tui fill: %v\n", err)
+		return 1
+	}
+
+	if opts.Copy {
+		if err := copyToClipboardSync(filled); err != nil {
+			fmt.Fprintf(stderr, "cellblocks-tui fill: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stderr, "✓ Copied to clipboard")
+		return 0
+	}
+	fmt.Fprint(stdout, filled)
+	if !strings.HasSuffix(filled, "\n") {
+		fmt.Fprintln(stdout)
+	}
+	return 0
+}
+
+// findCLICard finds a card by exact ID, "id:..." or title (case-insensitive)
+func findCLICard(cards []Card, target string) (*Card, error) {
+	if i := findCardIndex(cards, target); i >= 0 {
+		return &cards[i], nil
+	}
+	if card := resolveLink(cards, target); card != nil {
+		return card, nil
+	}
+	return nil, fmt.Errorf("no card with title or id %q", target)
+}
+
+// fillPrompt asks for a variable's value; problem is why the current value won't do
+// (nil when there is none yet)
+type fillPrompt func(v TemplateVar, problem error) (string, error)
+
+// fillCard fills the target card with opts.Vars, asking prompt (if set) for what's
+// missing or invalid. Warnings (unknown --var names, broken includes) go to warn
+func fillCard(data *CellBlocksData, opts fillOptions, prompt fillPrompt, warn io.Writer) (string, error) {
+	card, err := findCLICard(data.Cards, opts.Target)
+	if err != nil {
+		return "", err
+	}
+
+	expansion := expandIncludes(data.Cards, card)
+	for _, problem := range expansion.Errors {
+		fmt.Fprintf(warn, "warning: %s\n", problem)
+	}
+	content := expansion.Content
+	decls := ExtractTemplateVars(content)
+
+	values := make(map[string]string)
+	for name, value := range opts.Vars {
+		values[name] = value
+	}
+	declared := make(map[string]bool)
+	for _, decl := range decls {
+		declared[decl.Name] = true
+	}
+	for name := range opts.Vars {
+		if !declared[name] {
+			fmt.Fprintf(warn, "warning: %q has no variable %q\n", card.Title, name)
+		}
+	}
+
+	for {
+		i, err := ValidateTemplate(content, decls, values)
+		if err == nil {
+			break
+		}
+		if i < 0 || decls[i].Err != "" {
+			// Block syntax or declaration error - no value fixes it
+			return "", err
+		}
+		if prompt == nil {
+			return "", templateProblemsError(content, decls, values)
+		}
+
+		decl := decls[i]
+		var problem error
+		if values[decl.Name] != "" {
+			problem = err
+		}
+		value, err := prompt(decl, problem)
+		if err != nil {
+			return "", err
+		}
+		values[decl.Name] = value
+	}
+
+	return fillWithSecrets(content, values, opts.Secrets)
+}
+
+// templateProblemsError lists every missing and invalid variable in one error
+func templateProblemsError(content string, decls []TemplateVar, values map[string]string) error {
+	active := activeTemplateVars(content, values)
+	var missing, invalid []string
+	for _, decl := range decls {
+		if !active[decl.Name] && decl.Type != VarBool && decl.Type != VarList {
+			continue
+		}
+		err := decl.Validate(values[decl.Name])
+		switch {
+		case err == nil:
+		case values[decl.Name] == "" && decl.Err == "":
+			missing = append(missing, decl.Name)
+		default:
+			invalid = append(invalid, fmt.Sprintf("%s: %v", decl.Name, err))
+		}
+	}
+
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "missing required variables: "+strings.Join(missing, ", ")+
+			" (pass --var name=value or use --interactive)")
+	}
+	parts = append(parts, invalid...)
+	return errors.New(strings.Join(parts, "; "))
+}
+
+// newFillPrompter asks for values line by line: "port (int): "
+func newFillPrompter(in io.Reader, out io.Writer) fillPrompt {
+	reader := bufio.NewReader(in)
+	return func(v TemplateVar, problem error) (string, error) {
+		if problem != nil {
+			fmt.Fprintf(out, "⚠ %v\n", problem)
+		}
+		fmt.Fprintf(out, "%s: ", fillPromptLabel(v))
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(out)
+			return "", fmt.Errorf("no value for %q", v.Name)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
+
+// fillPromptLabel describes a variable for its prompt
+func fillPromptLabel(v TemplateVar) string {
+	switch v.Type {
+	case VarChoice:
+		return fmt.Sprintf("%s (%s)", v.Name, strings.Join(v.Options, "/"))
+	case VarList:
+		return v.Name + " (comma-separated)"
+	case VarMatch:
+		return fmt.Sprintf("%s (matching %s)", v.Name, v.Pattern)
+	case VarText:
+		return v.Name
+	}
+	return fmt.Sprintf("%s (%s)", v.Name, v.Type)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFillArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantTarget  string
+		wantVars    map[string]string
+		interactive bool
+		wantErr     bool
+	}{
+		{name: "flags after the card", args: []string{"Docker Run", "--var", "port=8080", "--var", "image=nginx"}, wantTarget: "Docker Run", wantVars: map[string]string{"port": "8080", "image": "nginx"}},
+		{name: "flags before the card", args: []string{"-i", "--var=port=80", "c1"}, wantTarget: "c1", wantVars: map[string]string{"port": "80"}, interactive: true},
+		{name: "value with = and spaces", args: []string{"c1", "--var", "cmd=a=b c"}, wantTarget: "c1", wantVars: map[string]string{"cmd": "a=b c"}},
+		{name: "empty value", args: []string{"c1", "--var", "tag="}, wantTarget: "c1", wantVars: map[string]string{"tag": ""}},
+		{name: "var without =", args: []string{"c1", "--var", "port"}, wantErr: true},
+		{name: "var without a name", args: []string{"c1", "--var", "=8080"}, wantErr: true},
+		{name: "no card", args: []string{"--var", "port=1"}, wantErr: true},
+		{name: "two cards", args: []string{"a", "b"}, wantErr: true},
+		{name: "unknown flag", args: []string{"c1", "--nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseFillArgs(tt.args, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if opts.Target != tt.wantTarget || opts.Interactive != tt.interactive {
+				t.Errorf("target = %q interactive = %v, want %q %v", opts.Target, opts.Interactive, tt.wantTarget, tt.interactive)
+			}
+			if len(opts.Vars) != len(tt.wantVars) {
+				t.Errorf("vars = %v, want %v", opts.Vars, tt.wantVars)
+			}
+			for name, want := range tt.wantVars {
+				if got, ok := opts.Vars[name]; !ok || got != want {
+					t.Errorf("var %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFillCard(t *testing.T) {
+	data := &CellBlocksData{Cards: []Card{
+		{ID: "c1", Title: "Docker Run", Content: "docker run -p {{port:int}}:80 {{image}}"},
+		{ID: "c2", Title: "Env", Content: "deploy {{env:choice(dev,prod)}}{{#if dry}} --dry-run{{/if}}"},
+		{ID: "c3", Title: "Wrapper", Content: "{{> Docker Run}} --rm"},
+		{ID: "c4", Title: "Broken", Content: "{{n:match([)}}"},
+	}}
+
+	tests := []struct {
+		name    string
+		target  string
+		vars    map[string]string
+		input   string // Answers to prompts; "" means not interactive
+		want    string
+		wantErr string
+	}{
+		{name: "by title", target: "docker run", vars: map[string]string{"port": "8080", "image": "nginx"}, want: "docker run -p 8080:80 nginx"},
+		{name: "by id", target: "c1", vars: map[string]string{"port": "1", "image": "x"}, want: "docker run -p 1:80 x"},
+		{name: "by id: link", target: "id:c2", vars: map[string]string{"env": "dev"}, want: "deploy dev"},
+		{name: "optional bool", target: "Env", vars: map[string]string{"env": "prod", "dry": "true"}, want: "deploy prod --dry-run"},
+		{name: "includes are expanded", target: "Wrapper", vars: map[string]string{"port": "80", "image": "a"}, want: "docker run -p 80:80 a --rm"},
+		{name: "missing variables", target: "Docker Run", vars: map[string]string{"image": "nginx"}, wantErr: "missing required variables: port"},
+		{name: "invalid value", target: "Docker Run", vars: map[string]string{"port": "http", "image": "a"}, wantErr: "port: must be a whole number"},
+		{name: "unknown card", target: "Nope", wantErr: `no card with title or id "Nope"`},
+		{name: "prompts for what's missing", target: "Docker Run", vars: map[string]string{"image": "nginx"}, input: "8080\n", want: "docker run -p 8080:80 nginx"},
+		{name: "prompts again after a bad answer", target: "Env", input: "staging\nprod\n", want: "deploy prod"},
+		{name: "runs out of answers", target: "Docker Run", input: "80\n", wantErr: `no value for "image"`},
+		{name: "broken declaration isn't prompted for", target: "Broken", input: "x\n", wantErr: "n:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompt fillPrompt
+			var prompts bytes.Buffer
+			if tt.input != "" {
+				prompt = newFillPrompter(strings.NewReader(tt.input), &prompts)
+			}
+			opts := fillOptions{Target: tt.target, Vars: tt.vars}
+			got, err := fillCard(data, opts, prompt, &bytes.Buffer{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v (prompts: %q)", err, prompts.String())
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCLI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	raw, err := json.Marshal(CellBlocksData{Cards: []Card{{ID: "c1", Title: "Greet", Content: "hello {{name}}"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{name: "fill", args: []string{"fill", "greet", "--data", path, "--var", "name=world"}, wantCode: 0, wantStdout: "hello world\n"},
+		{name: "unknown var warns", args: []string{"fill", "greet", "--data", path, "--var", "name=a", "--var", "x=1"}, wantCode: 0, wantStdout: "hello a\n", wantStderr: `no variable "x"`},
+		{name: "missing var", args: []string{"fill", "greet", "--data", path}, wantCode: 1, wantStderr: "missing required variables: name"},
+		{name: "bad arguments", args: []string{"fill"}, wantCode: 2, wantStderr: "exactly one card"},
+		{name: "fill help", args: []string{"fill", "-h"}, wantCode: 0, wantStderr: "--var name=value"},
+		{name: "unknown command", args: []string{"bogus"}, wantCode: 2, wantStderr: `unknown command "bogus"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := runCLI(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (stderr: %q)", code, tt.wantCode, stderr.String())
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
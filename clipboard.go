@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -87,13 +88,55 @@ func copyToClipboardSync(text string) error {
 	return nil
 }
 
+// detectPasteCommand returns the clipboard read command for the current platform
+func detectPasteCommand() (string, []string) {
+	if isTermux() {
+		return "termux-clipboard-get", []string{}
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return "pbpaste", []string{}
+	case "linux":
+		if _, err := exec.LookPath("xclip"); err == nil {
+			return "xclip", []string{"-selection", "clipboard", "-o"}
+		}
+		if _, err := exec.LookPath("xsel"); err == nil {
+			return "xsel", []string{"--clipboard", "--output"}
+		}
+	case "windows":
+		return "powershell.exe", []string{"-NoProfile", "-Command", "Get-Clipboard"}
+	}
+
+	return "", nil
+}
+
+// readClipboardSync returns the current clipboard text
+func readClipboardSync() (string, error) {
+	cmd, args := detectPasteCommand()
+	if cmd == "" {
+		return "", fmt.Errorf("clipboard not supported on this platform")
+	}
+
+	output, err := exec.Command(cmd, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("clipboard command failed: %w", err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
 // copyToClipboard is the async Bubbletea command wrapper
 func copyToClipboard(text string) tea.Cmd {
+	return copyCardsToClipboard(text, nil, false)
+}
+
+// copyCardsToClipboard copies text taken from cards so the copy counts as card usage
+func copyCardsToClipboard(text string, cardIDs []string, templateFilled bool) tea.Cmd {
 	return func() tea.Msg {
 		err := copyToClipboardSync(text)
 		if err != nil {
 			return copyErrorMsg{err: err}
 		}
-		return cardCopiedMsg{cardTitle: "Card copied to clipboard"}
+		return cardCopiedMsg{cardTitle: "Card copied to clipboard", cardIDs: cardIDs, templateFilled: templateFilled}
 	}
 }
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// codeblocks.go - Fenced Code Blocks
+// Purpose: Find ``` / ~~~ blocks in card content so a single block can be copied
+//
+// Blocks are numbered in the detail view and preview ("▸ [1] bash"); Alt+1-9 in
+// detail view and the B picker copy just that block instead of the whole card.
+
+// maxCodeBlockKeys is how many blocks get a number key
+const maxCodeBlockKeys = 9
+
+// codeBlock is one fenced code block in card content
+type codeBlock struct {
+	Lang string // Info string language ("bash"), may be empty
+	Code string // Block body without the fences
+	Line int    // 0-based line of the opening fence
+}
+
+// parseFence reports whether a line opens or closes a fence and returns its
+// indentation, fence marker (e.g. "```") and info string
+func parseFence(line string) (indent string, fence string, info string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	indent = line[:len(line)-len(trimmed)]
+	if len(indent) > 3 || len(trimmed) < 3 {
+		return "", "", "", false
+	}
+
+	char := trimmed[0]
+	if char != '`' && char != '~' {
+		return "", "", "", false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == char {
+		n++
+	}
+	if n < 3 {
+		return "", "", "", false
+	}
+
+	info = strings.TrimSpace(trimmed[n:])
+	// Backtick fences can't have backticks in the info string (that's inline code)
+	if char == '`' && strings.Contains(info, "`") {
+		return "", "", "", false
+	}
+	return indent, trimmed[:n], info, true
+}
+
+// parseCodeBlocks returns the fenced code blocks in content, in order
+// An unclosed fence runs to the end of the content, as in CommonMark
+func parseCodeBlocks(content string) []codeBlock {
+	var blocks []codeBlock
+	lines := strings.Split(content, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		_, fence, info, ok := parseFence(lines[i])
+		if !ok {
+			continue
+		}
+
+		block := codeBlock{Line: i}
+		if fields := strings.Fields(info); len(fields) > 0 {
+			block.Lang = fields[0]
+		}
+
+		var body []string
+		j := i + 1
+		for ; j < len(lines); j++ {
+			// Closing fence: same character, at least as long, no info string
+			if _, closing, closingInfo, ok := parseFence(lines[j]); ok &&
+				closing[0] == fence[0] && len(closing) >= len(fence) && closingInfo == "" {
+				break
+			}
+			body = append(body, lines[j])
+		}
+
+		block.Code = strings.Join(body, "\n")
+		blocks = append(blocks, block)
+		i = j
+	}
+
+	return blocks
+}
+
+// codeBlockLabel returns the marker shown above a block, e.g. "▸ [1] bash"
+func codeBlockLabel(n int, block codeBlock) string {
+	label := fmt.Sprintf("▸ [%d]", n)
+	if block.Lang != "" {
+		label += " " + block.Lang
+	}
+	return label
+}
+
+// numberCodeBlocks inserts a numbered label line before each fenced block
+// Only used for display - the card content itself is never changed
+func numberCodeBlocks(content string) string {
+	blocks := parseCodeBlocks(content)
+	if len(blocks) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	var out []string
+	next := 0
+	for i, line := range lines {
+		if next < len(blocks) && blocks[next].Line == i {
+			indent, _, _, _ := parseFence(line)
+			// Blank line first so markdown doesn't join the label to a paragraph
+			out = append(out, "", indent+codeBlockLabel(next+1, blocks[next]))
+			next++
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// defaultCopyText returns what a plain copy of a card puts on the clipboard:
+// the first code block for categories with copyFirstCodeBlock set, else the content
+func (m *Model) defaultCopyText(card *Card, content string) string {
+	if m.copiesFirstCodeBlock(card) {
+		return firstCodeBlockOr(content)
+	}
+	return content
+}
+
+// copiesFirstCodeBlock reports whether the card's category has copyFirstCodeBlock set
+func (m *Model) copiesFirstCodeBlock(card *Card) bool {
+	cat := m.getCategoryForCard(card)
+	return cat != nil && cat.CopyFirstCodeBlock
+}
+
+// firstCodeBlockOr returns the code of the first fenced block, or content if it has none
+func firstCodeBlockOr(content string) string {
+	if blocks := parseCodeBlocks(content); len(blocks) > 0 {
+		return blocks[0].Code
+	}
+	return content
+}
+
+// plainCopyText returns what copying a card without filling its template puts on the
+// clipboard: includes expanded and \{{ escapes turned back into {{
+func (m *Model) plainCopyText(card *Card) string {
+	return m.defaultCopyText(card, unescapeTemplate(m.expandCard(card).Content))
+}
+
+// codeBlockOr returns the code of the nth (1-based) fenced block, or "" if there's none
+func codeBlockOr(content string, n int) string {
+	if blocks := parseCodeBlocks(content); n >= 1 && n <= len(blocks) {
+		return blocks[n-1].Code
+	}
+	return ""
+}
+
+// codeBlockSource returns the content whose blocks the detail view numbers: the card
+// as written, or with the form open its expanded, filled template (secrets masked)
+func (m *Model) codeBlockSource(card *Card) string {
+	if !m.showFilledPreview(card) {
+		return card.Content
+	}
+	content := m.expandCard(card).Content
+	return FillTemplate(content, maskSecrets(content, m.TemplateVars))
+}
+
+// copyCodeBlock copies the nth (1-based) code block of a card
+// With the form open that's the block of the filled template, once it validates
+func (m *Model) copyCodeBlock(card *Card, n int) tea.Cmd {
+	if card == nil {
+		return nil
+	}
+
+	blocks := parseCodeBlocks(m.codeBlockSource(card))
+	if n < 1 || n > len(blocks) {
+		m.ReloadMessage = fmt.Sprintf("No code block %d in '%s'", n, card.Title)
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+	if m.showFilledPreview(card) {
+		return m.copyFilled(card, func(filled string) string { return codeBlockOr(filled, n) })
+	}
+	return copyCardsToClipboard(unescapeTemplate(blocks[n-1].Code), []string{card.ID}, false)
+}
+
+// pickCodeBlock opens a picker to copy one code block of a card
+func (m *Model) pickCodeBlock(card *Card) tea.Cmd {
+	if card == nil {
+		return nil
+	}
+
+	blocks := parseCodeBlocks(m.codeBlockSource(card))
+	switch len(blocks) {
+	case 0:
+		m.ReloadMessage = fmt.Sprintf("No code blocks in '%s'", card.Title)
+		m.ReloadMessageTime = time.Now()
+		return nil
+	case 1:
+		// Nothing to choose
+		return m.copyCodeBlock(card, 1)
+	}
+
+	options := make([]PickerOption, len(blocks))
+	for i, block := range blocks {
+		firstLine, _, _ := strings.Cut(strings.TrimSpace(block.Code), "\n")
+		options[i] = PickerOption{
+			Label: codeBlockLabel(i+1, block) + "  " + truncate(firstLine, 40),
+			Value: fmt.Sprint(i + 1),
+		}
+	}
+
+	cardID := card.ID
+	m.askPick("Copy code block:", options, func(m *Model, value string) tea.Cmd {
+		index := findCardIndex(m.Data.Cards, cardID)
+		if index < 0 {
+			return nil
+		}
+		var n int
+		fmt.Sscan(value, &n)
+		return m.copyCodeBlock(&m.Data.Cards[index], n)
+	})
+	return nil
+}
+
+// toggleCopyFirstCodeBlock flips a category's "copy first code block by default" setting (undoable)
+func (m *Model) toggleCopyFirstCodeBlock(categoryID string) tea.Cmd {
+	if m.Data == nil {
+		return nil
+	}
+	for _, cat := range m.Data.Categories {
+		if cat.ID != categoryID {
+			continue
+		}
+		updated := cat
+		updated.CopyFirstCodeBlock = !cat.CopyFirstCodeBlock
+		message := fmt.Sprintf("'%s' copies whole cards", cat.Name)
+		if updated.CopyFirstCodeBlock {
+			message = fmt.Sprintf("'%s' copies the first code block", cat.Name)
+		}
+		return m.execute(updateCategoryCommand{verb: "code block copy", before: cat, after: updated}, message)
+	}
+	return nil
+}
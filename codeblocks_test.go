@@ -0,0 +1,185 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLangs []string
+		wantCode  []string
+	}{
+		{"no blocks", "just prose\n`inline` code", nil, nil},
+		{
+			"prose and two blocks",
+			"Install it:\n```bash\napt install jq\n```\nThen:\n~~~\njq . file.json\n~~~",
+			[]string{"bash", ""},
+			[]string{"apt install jq", "jq . file.json"},
+		},
+		{
+			"info string keeps first word",
+			"```python title=\"x\"\nprint(1)\n```",
+			[]string{"python"},
+			[]string{"print(1)"},
+		},
+		{
+			"shorter fence doesn't close",
+			"````md\n```bash\nls\n```\n````",
+			[]string{"md"},
+			[]string{"```bash\nls\n```"},
+		},
+		{
+			"other fence char doesn't close",
+			"```\na\n~~~\nb\n```",
+			[]string{""},
+			[]string{"a\n~~~\nb"},
+		},
+		{"unclosed runs to end", "```sh\necho hi\necho bye", []string{"sh"}, []string{"echo hi\necho bye"}},
+		{"indented up to 3 spaces", "   ```\nx\n   ```", []string{""}, []string{"x"}},
+		{"4 spaces is not a fence", "    ```\nx\n    ```", nil, nil},
+		{"inline triple backticks", "use ```foo``` here", nil, nil},
+		{"empty block", "```\n```", []string{""}, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := parseCodeBlocks(tt.content)
+			if len(blocks) != len(tt.wantCode) {
+				t.Fatalf("parseCodeBlocks() found %d blocks, want %d: %+v", len(blocks), len(tt.wantCode), blocks)
+			}
+			for i, block := range blocks {
+				if block.Lang != tt.wantLangs[i] {
+					t.Errorf("block %d lang = %q, want %q", i+1, block.Lang, tt.wantLangs[i])
+				}
+				if block.Code != tt.wantCode[i] {
+					t.Errorf("block %d code = %q, want %q", i+1, block.Code, tt.wantCode[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNumberCodeBlocks(t *testing.T) {
+	content := "Run:\n```bash\nls\n```\n  ~~~\nx\n  ~~~"
+	want := "Run:\n\n▸ [1] bash\n```bash\nls\n```\n\n  ▸ [2]\n  ~~~\nx\n  ~~~"
+	if got := numberCodeBlocks(content); got != want {
+		t.Errorf("numberCodeBlocks() = %q, want %q", got, want)
+	}
+
+	if got := numberCodeBlocks("no code"); got != "no code" {
+		t.Errorf("numberCodeBlocks() changed content without blocks: %q", got)
+	}
+}
+
+func TestDefaultCopyText(t *testing.T) {
+	m := testModel(&CellBlocksData{Categories: []Category{
+		{ID: "cmd", CopyFirstCodeBlock: true},
+		{ID: "prompt"},
+	}})
+
+	content := "Explanation\n```bash\necho first\n```\n```bash\necho second\n```"
+	tests := []struct {
+		name    string
+		card    Card
+		content string
+		want    string
+	}{
+		{"setting on", Card{CategoryID: "cmd"}, content, "echo first"},
+		{"setting off", Card{CategoryID: "prompt"}, content, content},
+		{"no blocks", Card{CategoryID: "cmd"}, "plain", "plain"},
+		{"unknown category", Card{CategoryID: "gone"}, content, content},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.defaultCopyText(&tt.card, tt.content); got != tt.want {
+				t.Errorf("defaultCopyText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleCopyFirstCodeBlockUndo(t *testing.T) {
+	m := testModel(&CellBlocksData{Categories: []Category{{ID: "cmd", Name: "Commands"}}})
+
+	m.toggleCopyFirstCodeBlock("cmd")
+	if !m.Data.Categories[0].CopyFirstCodeBlock || !m.CategoryMap["cmd"].CopyFirstCodeBlock {
+		t.Fatal("toggle didn't enable the setting")
+	}
+
+	m.undo()
+	if m.Data.Categories[0].CopyFirstCodeBlock || m.CategoryMap["cmd"].CopyFirstCodeBlock {
+		t.Error("undo didn't disable the setting")
+	}
+}
+
+func TestDetailNumberKeys(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{
+		{ID: "a", Title: "Setup", Content: "```bash\nmake\n```"},
+		{ID: "b", Title: "Fav", Content: "notes", Starred: true},
+	}})
+	m.openDetailView()
+
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		model, cmd := m.handleKeyPress(msg)
+		m = model.(Model)
+		return cmd
+	}
+	alt := func(digit rune) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{digit}, Alt: true}
+	}
+
+	// Alt+digits copy code blocks (the command isn't run - it would touch the clipboard)
+	if cmd := press(alt('1')); cmd == nil {
+		t.Error("Alt+1 didn't copy the code block")
+	}
+	press(alt('3'))
+	if !strings.Contains(m.ReloadMessage, "No code block 3") {
+		t.Errorf("Alt+3 message = %q", m.ReloadMessage)
+	}
+
+	// Plain digits open favorites even though the card has code blocks
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+	if card := m.getSelectedCard(); m.ViewMode != ViewDetail || card == nil || card.ID != "b" {
+		t.Errorf("1 didn't open favorite #1 (view %d)", m.ViewMode)
+	}
+}
+
+func TestCopyCodeBlockFilled(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{
+		{ID: "a", Title: "Deploy", Content: "{{> Setup}}\n```bash\ndeploy {{env}}\n```"},
+		{ID: "b", Title: "Setup", Content: "```bash\nmake setup\n```"},
+	}})
+	m.openDetailView()
+	card := m.getSelectedCard()
+
+	// The form is open, so block 2 is the card's own block after the included one
+	if !m.ShowTemplateForm {
+		t.Fatal("form not shown for a card with variables")
+	}
+	if m.copyCodeBlock(card, 2) != nil || !strings.Contains(m.ReloadMessage, "Can't copy yet") {
+		t.Errorf("copied block 2 with {{env}} unfilled (message %q)", m.ReloadMessage)
+	}
+
+	m.TemplateVars["env"] = "prod"
+	if m.copyCodeBlock(card, 2) == nil {
+		t.Fatalf("block 2 not copied: %q", m.ReloadMessage)
+	}
+	if got := codeBlockOr(m.codeBlockSource(card), 2); got != "deploy prod" {
+		t.Errorf("block 2 = %q, want the filled card block", got)
+	}
+	if got := codeBlockOr(m.codeBlockSource(card), 1); got != "make setup" {
+		t.Errorf("block 1 = %q, want the included block", got)
+	}
+
+	// With the form closed the blocks are the card as written
+	m.ShowTemplateForm = false
+	if m.copyCodeBlock(card, 2) != nil {
+		t.Error("copied block 2 of a card with one block of its own")
+	}
+}
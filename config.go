@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// config.go - Local User Settings
+// Purpose: Load per-machine preferences that don't belong in the shared data file
+
+const (
+	// DefaultConfigPath is the local settings file location
+	DefaultConfigPath = "~/.config/cellblocks-tui/config.json"
+)
+
+// Config holds local preferences (never synced with cellblocks-data.json)
+type Config struct {
+	// TrashRetentionDays is how long trashed cards are kept before auto-purge
+	// Zero or negative disables auto-purge
+	TrashRetentionDays int `json:"trashRetentionDays"`
+
+	// BulkCopySeparator is placed between cards when copying a multi-selection
+	BulkCopySeparator string `json:"bulkCopySeparator"`
+
+	// ExportDir is where exported multi-selections are written
+	ExportDir string `json:"exportDir"`
+
+	// PinFavorites shows starred cards at the top of list/grid views on startup
+	PinFavorites bool `json:"pinFavorites"`
+
+	// DefaultSort is the list/grid ordering on startup: "" (library order), "frecency" or "manual"
+	DefaultSort string `json:"defaultSort"`
+
+	// TableFields are custom field keys shown as extra table columns on startup
+	TableFields []string `json:"tableFields"`
+
+	// CodeTheme is the chroma style used to highlight code cards (e.g. "monokai", "github")
+	CodeTheme string `json:"codeTheme"`
+
+	// Profiles are named sets of template variable values (dev, staging, prod...), in
+	// the order Ctrl+O cycles through them
+	Profiles []VarProfile `json:"profiles"`
+
+	// ActiveProfile is the profile used on startup ("" = none)
+	ActiveProfile string `json:"activeProfile"`
+
+	// Secrets chooses where {{secret:name}} values come from (see secrets.go)
+	Secrets SecretsConfig `json:"secrets"`
+}
+
+// defaultConfig returns the settings used when no config file exists
+func defaultConfig() Config {
+	return Config{
+		TrashRetentionDays: 30,
+		BulkCopySeparator:  "\n\n",
+		ExportDir:          "~/",
+		CodeTheme:          defaultCodeTheme,
+		Secrets:            defaultSecretsConfig(),
+	}
+}
+
+// LoadConfig reads the config file, falling back to defaults for missing values
+// A missing file is not an error - defaults are returned
+func LoadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	content, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Unmarshal over defaults so omitted keys keep their default values
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return defaultConfig(), fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return cfg, nil
+}
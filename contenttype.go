@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// contenttype.go - Content Types & Syntax Highlighting
+// Purpose: Markdown / code / plain cards, language detection and chroma highlighting
+//
+// A card's type comes from the card, then its category, then defaults to markdown
+// (every card was markdown before types existed). Code cards are highlighted even
+// with markdown rendering off (m), since highlighting never changes the layout.
+
+// Content types
+const (
+	ContentMarkdown = "markdown"
+	ContentCode     = "code"
+	ContentPlain    = "plain"
+)
+
+// contentTypeCycle is the order y steps through ("" = use the category default)
+var contentTypeCycle = []string{"", ContentMarkdown, ContentCode, ContentPlain}
+
+// defaultCodeTheme is the chroma style used when config.json doesn't set codeTheme
+const defaultCodeTheme = "monokai"
+
+// contentTypeOf resolves a card's content type and language
+// The language is auto-detected for code cards that don't set one
+func (m *Model) contentTypeOf(card *Card) (string, string) {
+	kind, language := card.ContentType, card.Language
+	if cat := m.getCategoryForCard(card); cat != nil {
+		if kind == "" {
+			kind = cat.ContentType
+		}
+		if language == "" {
+			language = cat.Language
+		}
+	}
+	if kind == "" {
+		kind = ContentMarkdown
+	}
+	if kind == ContentCode && language == "" {
+		language = cardLanguage(card)
+	}
+	return kind, language
+}
+
+// needsRender reports whether a card's preview/detail content goes through a renderer
+func (m *Model) needsRender(card *Card) bool {
+	kind, _ := m.contentTypeOf(card)
+	return kind == ContentCode || (kind == ContentMarkdown && m.UseMarkdownRender)
+}
+
+// renderCardContent renders content for its type (markdown via glamour, code via chroma)
+func renderCardContent(content, kind, language, theme string, width int) string {
+	switch kind {
+	case ContentCode:
+		return highlightCode(content, language, theme)
+	case ContentPlain:
+		return content
+	default:
+		return renderMarkdown(numberCodeBlocks(content), width)
+	}
+}
+
+// contentTypeLabel returns the detail view badge text, e.g. "MD", "TXT" or "go"
+func (m *Model) contentTypeLabel(card *Card) string {
+	kind, language := m.contentTypeOf(card)
+	switch kind {
+	case ContentCode:
+		if language == "" {
+			return "CODE"
+		}
+		return language
+	case ContentPlain:
+		return "TXT"
+	}
+	if m.UseMarkdownRender {
+		return "MD"
+	}
+	return "TXT"
+}
+
+// Language detection
+
+// shellCommands are first words that mark a snippet as a shell command
+var shellCommands = map[string]bool{
+	"apt": true, "brew": true, "cat": true, "cd": true, "chmod": true, "cp": true, "curl": true,
+	"docker": true, "docker-compose": true, "echo": true, "export": true, "find": true, "git": true,
+	"go": true, "grep": true, "kubectl": true, "ls": true, "make": true, "mkdir": true, "mv": true,
+	"npm": true, "npx": true, "pip": true, "pkg": true, "rm": true, "rsync": true, "ssh": true,
+	"sudo": true, "systemctl": true, "tar": true, "termux-setup-storage": true, "wget": true, "yarn": true,
+}
+
+// languagePatterns are checked in order; the first match wins
+var languagePatterns = []struct {
+	language string
+	pattern  *regexp.Regexp
+}{
+	{"go", regexp.MustCompile(`(?m)^package \w+$|^func (\(\w+ \*?\w+\) )?\w+\(`)},
+	{"python", regexp.MustCompile(`(?m)^(def \w+\(.*\):|class \w+.*:|from [\w.]+ import |import \w+$|if __name__ == )`)},
+	{"dockerfile", regexp.MustCompile(`(?m)^FROM \S+`)},
+	{"sql", regexp.MustCompile(`(?mi)^\s*(SELECT .+ FROM |INSERT INTO |UPDATE \w+ SET |DELETE FROM |CREATE (TABLE|INDEX|VIEW) )`)},
+	{"html", regexp.MustCompile(`(?i)<(!DOCTYPE|html|head|body|div|span)[\s>]`)},
+	{"javascript", regexp.MustCompile(`console\.log\(|require\(['"]|(?m)^(const|let|var) \w+ = |=> \{|^export (default|function|const) `)},
+	{"rust", regexp.MustCompile(`(?m)^\s*(fn \w+\(|use \w+::|let mut )`)},
+	{"yaml", regexp.MustCompile(`(?m)\A(---\n)?([\w-]+:( .*)?\n)+[\w-]+:`)},
+}
+
+var shebangPattern = regexp.MustCompile(`\A#!\s*(?:/usr/bin/env\s+)?\S*?([\w.-]+)(?:\s|$)`)
+
+// detectedLanguages caches detectLanguage per card: contentTypeOf runs from View() for
+// every visible code card, and detection (regexes, then chroma's analysers) is too slow
+// to repeat each frame. Entries are keyed by card ID and checked against a content hash
+var (
+	detectedMu        sync.Mutex
+	detectedLanguages = make(map[string]detectedLanguage)
+)
+
+type detectedLanguage struct {
+	hash     uint64
+	language string
+}
+
+// cardLanguage returns the detected language of a card's content, cached until it changes
+func cardLanguage(card *Card) string {
+	h := fnv.New64a()
+	h.Write([]byte(card.Content))
+	hash := h.Sum64()
+
+	detectedMu.Lock()
+	cached, ok := detectedLanguages[card.ID]
+	detectedMu.Unlock()
+	if ok && cached.hash == hash {
+		return cached.language
+	}
+
+	language := detectLanguage(card.Content)
+	detectedMu.Lock()
+	// Same simple eviction as the highlight cache
+	if len(detectedLanguages) >= 1000 {
+		detectedLanguages = make(map[string]detectedLanguage)
+	}
+	detectedLanguages[card.ID] = detectedLanguage{hash: hash, language: language}
+	detectedMu.Unlock()
+	return language
+}
+
+// detectLanguage guesses a code card's language ("" when unsure)
+func detectLanguage(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return ""
+	}
+
+	// Shebang names the interpreter
+	if match := shebangPattern.FindStringSubmatch(trimmed); match != nil {
+		interpreter := strings.TrimRight(match[1], "0123456789.")
+		switch interpreter {
+		case "sh", "bash", "zsh", "dash":
+			return "bash"
+		case "node":
+			return "javascript"
+		}
+		if lexers.Get(interpreter) != nil {
+			return interpreter
+		}
+	}
+
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return "json"
+	}
+
+	for _, lp := range languagePatterns {
+		if lp.pattern.MatchString(trimmed) {
+			return lp.language
+		}
+	}
+
+	// Command lines: first word is a known command (optionally after a "$ " prompt)
+	firstLine, _, _ := strings.Cut(trimmed, "\n")
+	if words := strings.Fields(strings.TrimPrefix(firstLine, "$ ")); len(words) > 0 && shellCommands[words[0]] {
+		return "bash"
+	}
+
+	if lexer := lexers.Analyse(trimmed); lexer != nil {
+		return strings.ToLower(lexer.Config().Name)
+	}
+	return ""
+}
+
+// Highlighting
+
+var (
+	highlightMu    sync.Mutex
+	highlightCache = make(map[string]string)
+)
+
+// highlightCode colors code with chroma for 256-color terminals
+// Every line is styled on its own so splitting the result by "\n" keeps colors intact
+func highlightCode(code, language, theme string) string {
+	if theme == "" {
+		theme = defaultCodeTheme
+	}
+	key := language + "\x00" + theme + "\x00" + code
+
+	highlightMu.Lock()
+	cached, ok := highlightCache[key]
+	highlightMu.Unlock()
+	if ok {
+		return cached
+	}
+
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		// Unknown language - nothing to color
+		return code
+	}
+	tokens, err := chroma.Tokenise(chroma.Coalesce(lexer), nil, code)
+	if err != nil {
+		return code
+	}
+
+	style := styles.Get(theme)
+	formatter := formatters.Get("terminal256")
+	var lines []string
+	for _, line := range chroma.SplitTokensIntoLines(tokens) {
+		// Drop the line break before styling so it doesn't end up inside an escape sequence
+		var parts []chroma.Token
+		for _, token := range line {
+			token.Value = strings.TrimSuffix(token.Value, "\n")
+			if token.Value != "" {
+				parts = append(parts, token)
+			}
+		}
+		var b strings.Builder
+		if err := formatter.Format(&b, style, chroma.Literator(parts...)); err != nil {
+			return code
+		}
+		lines = append(lines, b.String())
+	}
+	result := strings.Join(lines, "\n")
+
+	highlightMu.Lock()
+	// Same simple eviction as the markdown cache
+	if len(highlightCache) >= 200 {
+		highlightCache = make(map[string]string)
+	}
+	highlightCache[key] = result
+	highlightMu.Unlock()
+
+	return result
+}
+
+// Changing types
+
+// cycleContentType steps the card's content type: category default → markdown → code → plain
+func (m *Model) cycleContentType(card *Card) tea.Cmd {
+	if card == nil {
+		return nil
+	}
+
+	next := contentTypeCycle[0]
+	for i, kind := range contentTypeCycle {
+		if kind == card.ContentType {
+			next = contentTypeCycle[(i+1)%len(contentTypeCycle)]
+			break
+		}
+	}
+
+	updated := *card
+	updated.ContentType = next
+	updated.UpdatedAt = time.Now().UnixMilli()
+
+	kind, language := m.contentTypeOf(&updated)
+	label := kind
+	if kind == ContentCode && language != "" {
+		label += " (" + language + ")"
+	}
+	if next == "" {
+		label += ", from category"
+	}
+
+	cmd := m.execute(updateCardCommand{verb: "content type", before: *card, after: updated},
+		fmt.Sprintf("📄 '%s' is now %s", updated.Title, label))
+	if cmd == nil {
+		return nil
+	}
+	return tea.Batch(cmd, m.refreshRenderedContent())
+}
+
+// promptLanguage asks for a code card's language (empty = auto-detect)
+func (m *Model) promptLanguage(card *Card) {
+	if card == nil {
+		return
+	}
+	original := *card
+
+	m.askPrompt("Language (empty = auto-detect):", original.Language, func(m *Model, value string) tea.Cmd {
+		language := strings.ToLower(strings.TrimSpace(value))
+		if language != "" && lexers.Get(language) == nil {
+			m.ReloadMessage = fmt.Sprintf("⚠ Unknown language '%s'", language)
+			m.ReloadMessageTime = time.Now()
+			return nil
+		}
+
+		updated := original
+		updated.ContentType = ContentCode
+		updated.Language = language
+		updated.UpdatedAt = time.Now().UnixMilli()
+		if updated.ContentType == original.ContentType && updated.Language == original.Language {
+			return nil
+		}
+
+		_, detected := m.contentTypeOf(&updated)
+		message := fmt.Sprintf("📄 '%s' is now code (%s)", updated.Title, detected)
+		if detected == "" {
+			message = fmt.Sprintf("📄 '%s' is now code", updated.Title)
+		}
+		cmd := m.execute(updateCardCommand{verb: "language", before: original, after: updated}, message)
+		return tea.Batch(cmd, m.refreshRenderedContent())
+	})
+}
+
+// refreshRenderedContent drops cached preview/detail renders and renders again
+func (m *Model) refreshRenderedContent() tea.Cmd {
+	m.CachedPreviewContent = ""
+	m.CachedPreviewWidth = 0
+	m.CachedDetailContent = ""
+	m.CachedDetailWidth = 0
+
+	var cmds []tea.Cmd
+	if m.ShowPreview {
+		cmds = append(cmds, m.populatePreviewCacheAsync())
+	}
+	if m.ViewMode == ViewDetail {
+		cmds = append(cmds, m.populateDetailCacheAsync())
+	}
+	return tea.Batch(cmds...)
+}
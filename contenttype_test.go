@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "  ", ""},
+		{"bash shebang", "#!/bin/bash\necho hi", "bash"},
+		{"env python shebang", "#!/usr/bin/env python3\nprint(1)", "python"},
+		{"node shebang", "#!/usr/bin/env node\nconsole.log(1)", "javascript"},
+		{"json object", `{"name": "x", "n": 1}`, "json"},
+		{"invalid json isn't json", `{name: x}`, ""},
+		{"go", "package main\n\nimport \"fmt\"\n\nfunc main() {}", "go"},
+		{"go func", "func add(a, b int) int {\n\treturn a + b\n}", "go"},
+		{"python", "def foo():\n    pass", "python"},
+		{"python import", "import os\nprint(os.getcwd())", "python"},
+		{"dockerfile", "FROM alpine:3\nRUN apk add git", "dockerfile"},
+		{"sql", "SELECT * FROM users WHERE id = 1;", "sql"},
+		{"html", "<div class=\"x\">hi</div>", "html"},
+		{"javascript", "const x = require('y');\nconsole.log(x)", "javascript"},
+		{"yaml", "name: test\non: push\njobs:\n  build: {}", "yaml"},
+		{"shell command", "docker run -it ubuntu bash", "bash"},
+		{"prompt prefix", "$ git status", "bash"},
+		{"prose", "Write me a poem about cats", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectLanguage(tt.content); got != tt.want {
+				t.Errorf("detectLanguage(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentTypeOf(t *testing.T) {
+	m := testModel(&CellBlocksData{Categories: []Category{
+		{ID: "scripts", ContentType: ContentCode, Language: "bash"},
+		{ID: "notes"},
+	}})
+
+	tests := []struct {
+		name     string
+		card     Card
+		wantKind string
+		wantLang string
+	}{
+		{"defaults to markdown", Card{CategoryID: "notes"}, ContentMarkdown, ""},
+		{"category default", Card{CategoryID: "scripts"}, ContentCode, "bash"},
+		{"card overrides category", Card{CategoryID: "scripts", ContentType: ContentPlain}, ContentPlain, "bash"},
+		{"card language wins", Card{CategoryID: "scripts", Language: "python"}, ContentCode, "python"},
+		{"auto-detected", Card{CategoryID: "notes", ContentType: ContentCode, Content: "package main"}, ContentCode, "go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, lang := m.contentTypeOf(&tt.card)
+			if kind != tt.wantKind || lang != tt.wantLang {
+				t.Errorf("contentTypeOf() = (%q, %q), want (%q, %q)", kind, lang, tt.wantKind, tt.wantLang)
+			}
+		})
+	}
+}
+
+func TestCardLanguageCache(t *testing.T) {
+	card := Card{ID: "lang-cache", Content: "package main"}
+	if got := cardLanguage(&card); got != "go" {
+		t.Fatalf("cardLanguage() = %q, want go", got)
+	}
+
+	// A hit doesn't detect again
+	detectedMu.Lock()
+	entry := detectedLanguages[card.ID]
+	entry.language = "cached"
+	detectedLanguages[card.ID] = entry
+	detectedMu.Unlock()
+	if got := cardLanguage(&card); got != "cached" {
+		t.Errorf("unchanged card detected again: %q", got)
+	}
+
+	// Editing the content invalidates the entry
+	card.Content = "def main():\n    pass"
+	if got := cardLanguage(&card); got != "python" {
+		t.Errorf("after an edit cardLanguage() = %q, want python", got)
+	}
+}
+
+func TestHighlightCode(t *testing.T) {
+	code := "# comment\necho \"multi\nline string\"\nls"
+	highlighted := highlightCode(code, "bash", "monokai")
+
+	if !strings.Contains(highlighted, "\x1b[") {
+		t.Fatal("highlightCode() added no colors")
+	}
+	if got := ansiPattern.ReplaceAllString(highlighted, ""); got != code {
+		t.Errorf("highlightCode() changed the text: %q", got)
+	}
+
+	// Each line is styled on its own: no escape sequence left open at a line break
+	for i, line := range strings.Split(highlighted, "\n") {
+		opened := strings.LastIndex(line, "\x1b[")
+		if opened >= 0 && !strings.HasSuffix(line, "\x1b[0m") {
+			t.Errorf("line %d leaves a style open: %q", i+1, line)
+		}
+	}
+
+	if got := highlightCode(code, "no-such-language", "monokai"); got != code {
+		t.Errorf("highlightCode() with unknown language = %q, want unchanged", got)
+	}
+}
+
+func TestCycleContentType(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{{ID: "a", Title: "A"}}})
+
+	want := []string{ContentMarkdown, ContentCode, ContentPlain, ""}
+	for _, kind := range want {
+		m.cycleContentType(&m.Data.Cards[0])
+		if got := m.Data.Cards[0].ContentType; got != kind {
+			t.Fatalf("cycleContentType() = %q, want %q", got, kind)
+		}
+	}
+
+	m.undo()
+	if got := m.Data.Cards[0].ContentType; got != ContentPlain {
+		t.Errorf("undo = %q, want %q", got, ContentPlain)
+	}
+}
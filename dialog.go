@@ -0,0 +1,245 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dialog.go - Modal Dialogs
+// Purpose: Confirmations, text prompts and pickers shown on top of any view
+
+// ConfirmDialog asks a yes/no question before running a destructive action
+type ConfirmDialog struct {
+	Message   string
+	OnConfirm func(m *Model) tea.Cmd // Runs when the user answers yes
+}
+
+// askConfirm opens a confirmation dialog
+func (m *Model) askConfirm(message string, onConfirm func(m *Model) tea.Cmd) {
+	m.Confirm = &ConfirmDialog{
+		Message:   message,
+		OnConfirm: onConfirm,
+	}
+}
+
+// handleConfirmInput processes input while a confirmation dialog is open
+func (m Model) handleConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		confirm := m.Confirm
+		m.Confirm = nil
+		return m, confirm.OnConfirm(&m)
+
+	case "n", "N", "esc", "q":
+		m.Confirm = nil
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// renderConfirmDialog renders the confirmation box centered on screen
+func renderConfirmDialog(m Model) string {
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		styleHelpKey.Render(m.Confirm.Message),
+		"",
+		styleHelpKey.Render("y/Enter")+styleHelpDesc.Render(" confirm  ")+
+			styleHelpKey.Render("n/Esc")+styleHelpDesc.Render(" cancel"),
+	)
+
+	box := styleDialogBox.Width(min(m.Width-4, 60)).Render(content)
+
+	return lipgloss.Place(m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		box)
+}
+
+// PromptDialog asks for a single line of text
+type PromptDialog struct {
+	Label    string
+	Value    string
+	OnSubmit func(m *Model, value string) tea.Cmd // Runs on Enter
+}
+
+// PickerOption is one choice in a PickerDialog
+type PickerOption struct {
+	Label string
+	Value string
+	Color string // Optional category color for the label
+}
+
+// PickerDialog asks the user to choose one option from a list
+type PickerDialog struct {
+	Title   string
+	Options []PickerOption
+	Cursor  int
+	OnPick  func(m *Model, value string) tea.Cmd // Runs on Enter
+}
+
+// askPrompt opens a text prompt dialog
+func (m *Model) askPrompt(label, initial string, onSubmit func(m *Model, value string) tea.Cmd) {
+	m.Prompt = &PromptDialog{
+		Label:    label,
+		Value:    initial,
+		OnSubmit: onSubmit,
+	}
+}
+
+// askPick opens a picker dialog
+func (m *Model) askPick(title string, options []PickerOption, onPick func(m *Model, value string) tea.Cmd) {
+	if len(options) == 0 {
+		return
+	}
+	m.Picker = &PickerDialog{
+		Title:   title,
+		Options: options,
+		OnPick:  onPick,
+	}
+}
+
+// hasDialog reports whether any modal dialog is open
+func (m Model) hasDialog() bool {
+	return m.Confirm != nil || m.Prompt != nil || m.Picker != nil
+}
+
+// handleDialogInput routes input to whichever modal dialog is open
+func (m Model) handleDialogInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	switch {
+	case m.Confirm != nil:
+		return m.handleConfirmInput(msg)
+	case m.Prompt != nil:
+		return m.handlePromptInput(msg)
+	case m.Picker != nil:
+		return m.handlePickerInput(msg)
+	}
+	return m, nil
+}
+
+// handlePromptInput processes input while a text prompt is open
+func (m Model) handlePromptInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		prompt := m.Prompt
+		m.Prompt = nil
+		return m, prompt.OnSubmit(&m, prompt.Value)
+
+	case tea.KeyEsc:
+		m.Prompt = nil
+		return m, nil
+
+	case tea.KeyBackspace:
+		// Remove the last rune (not byte) so multi-byte input stays valid
+		if runes := []rune(m.Prompt.Value); len(runes) > 0 {
+			m.Prompt.Value = string(runes[:len(runes)-1])
+		}
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.Prompt.Value += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// handlePickerInput processes input while a picker is open
+func (m Model) handlePickerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.Picker.Cursor > 0 {
+			m.Picker.Cursor--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.Picker.Cursor < len(m.Picker.Options)-1 {
+			m.Picker.Cursor++
+		}
+		return m, nil
+
+	case "enter", " ":
+		picker := m.Picker
+		m.Picker = nil
+		return m, picker.OnPick(&m, picker.Options[picker.Cursor].Value)
+
+	case "esc", "q":
+		m.Picker = nil
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// renderDialog renders whichever modal dialog is open
+func renderDialog(m Model) string {
+	switch {
+	case m.Confirm != nil:
+		return renderConfirmDialog(m)
+	case m.Prompt != nil:
+		return renderPromptDialog(m)
+	case m.Picker != nil:
+		return renderPickerDialog(m)
+	}
+	return ""
+}
+
+// renderPromptDialog renders the text prompt box centered on screen
+func renderPromptDialog(m Model) string {
+	boxWidth := min(m.Width-4, 60)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		styleHelpKey.Render(m.Prompt.Label),
+		"",
+		styleCardItemSelected.Width(boxWidth-6).Render(m.Prompt.Value+"█"),
+		"",
+		styleHelpKey.Render("Enter")+styleHelpDesc.Render(" ok  ")+
+			styleHelpKey.Render("Esc")+styleHelpDesc.Render(" cancel"),
+	)
+
+	box := styleDialogBox.Width(boxWidth).Render(content)
+
+	return lipgloss.Place(m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		box)
+}
+
+// renderPickerDialog renders the option list centered on screen
+func renderPickerDialog(m Model) string {
+	lines := []string{styleHelpKey.Render(m.Picker.Title), ""}
+
+	// Keep cursor visible on short screens (box chrome + title + hints use 8 lines)
+	visibleCount := max(1, m.Height-8)
+	start := 0
+	if m.Picker.Cursor >= visibleCount {
+		start = m.Picker.Cursor - visibleCount + 1
+	}
+	end := min(start+visibleCount, len(m.Picker.Options))
+
+	for i := start; i < end; i++ {
+		option := m.Picker.Options[i]
+		label := option.Label
+		if option.Color != "" {
+			label = styleCategoryName(label, option.Color)
+		}
+		if i == m.Picker.Cursor {
+			lines = append(lines, styleCardItemSelected.Render(styleCardTitleSelected.Render(">")+" "+label))
+		} else {
+			lines = append(lines, styleCardItem.Render("  "+label))
+		}
+	}
+
+	lines = append(lines, "",
+		styleHelpKey.Render("↑↓")+styleHelpDesc.Render(" choose  ")+
+			styleHelpKey.Render("Enter")+styleHelpDesc.Render(" select  ")+
+			styleHelpKey.Render("Esc")+styleHelpDesc.Render(" cancel"))
+
+	box := styleDialogBox.Width(min(m.Width-4, 60)).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+
+	return lipgloss.Place(m.Width, m.Height,
+		lipgloss.Center, lipgloss.Center,
+		box)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// favorites.go - Starred Cards
+// Purpose: Star toggle, favorites filter, pinned section and 1-9 quick access
+
+// maxQuickFavorites is how many favorites are reachable with number keys
+const maxQuickFavorites = 9
+
+// toggleStar stars or unstars a card (undoable, saved to the data file)
+func (m *Model) toggleStar(card *Card) tea.Cmd {
+	if card == nil {
+		return nil
+	}
+
+	updated := *card
+	updated.Starred = !card.Starred
+
+	verb, message := "star", fmt.Sprintf("★ Starred '%s'", card.Title)
+	if !updated.Starred {
+		verb, message = "unstar", fmt.Sprintf("☆ Unstarred '%s'", card.Title)
+	}
+
+	return m.execute(updateCardCommand{verb: verb, before: *card, after: updated}, message)
+}
+
+// getFavorites returns starred cards in library order
+func (m *Model) getFavorites() []Card {
+	if m.Data == nil {
+		return nil
+	}
+
+	var favorites []Card
+	for _, card := range m.Data.Cards {
+		if card.Starred {
+			favorites = append(favorites, card)
+		}
+	}
+	return favorites
+}
+
+// favoriteNumber returns the 1-9 quick-access number for a card, or 0 if it has none
+func (m *Model) favoriteNumber(cardID string) int {
+	for i, card := range m.getFavorites() {
+		if i >= maxQuickFavorites {
+			break
+		}
+		if card.ID == cardID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// filterFavorites keeps only starred cards
+func filterFavorites(cards []Card) []Card {
+	var results []Card
+	for _, card := range cards {
+		if card.Starred {
+			results = append(results, card)
+		}
+	}
+	return results
+}
+
+// pinFavorites moves starred cards to the front, keeping relative order otherwise
+func pinFavorites(cards []Card) []Card {
+	pinned := make([]Card, 0, len(cards))
+	var rest []Card
+	for _, card := range cards {
+		if card.Starred {
+			pinned = append(pinned, card)
+		} else {
+			rest = append(rest, card)
+		}
+	}
+	return append(pinned, rest...)
+}
+
+// isPinningFavorites reports whether favorites are pinned in the current view
+// (list and grid only - table view keeps its column sort)
+func (m *Model) isPinningFavorites() bool {
+	return m.PinFavorites && (m.ViewMode == ViewList || m.ViewMode == ViewGrid)
+}
+
+// pinnedBoundary returns the index of the first unpinned card when favorites are
+// pinned and both sections are non-empty, or -1 when no separator is needed
+func (m *Model) pinnedBoundary() int {
+	if !m.isPinningFavorites() || len(m.FilteredCards) == 0 || !m.FilteredCards[0].Starred {
+		return -1
+	}
+	for i, card := range m.FilteredCards {
+		if !card.Starred {
+			return i
+		}
+	}
+	return -1
+}
+
+// listSeparatorLine returns the visible line of the pinned-favorites separator in
+// list view (for the current scroll offset), or -1 if it isn't on screen
+func (m *Model) listSeparatorLine(visibleCount int) int {
+	boundary := m.pinnedBoundary()
+	if boundary <= m.ScrollOffset || boundary >= m.ScrollOffset+visibleCount {
+		return -1
+	}
+	return boundary - m.ScrollOffset
+}
+
+// jumpToFavorite opens the nth favorite (1-based) in detail view
+func (m *Model) jumpToFavorite(n int) tea.Cmd {
+	favorites := m.getFavorites()
+	if n < 1 || n > len(favorites) {
+		return nil
+	}
+	cmd, _ := m.openCardByID(favorites[n-1].ID)
+	return cmd
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPinFavorites(t *testing.T) {
+	cards := []Card{
+		{ID: "a"},
+		{ID: "b", Starred: true},
+		{ID: "c"},
+		{ID: "d", Starred: true},
+	}
+
+	pinned := pinFavorites(cards)
+
+	var order []string
+	for _, card := range pinned {
+		order = append(order, card.ID)
+	}
+	if got := len(order); got != 4 {
+		t.Fatalf("pinFavorites() returned %d cards, want 4", got)
+	}
+	want := []string{"b", "d", "a", "c"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("pinFavorites() order = %v, want %v", order, want)
+		}
+	}
+
+	if favorites := filterFavorites(cards); len(favorites) != 2 {
+		t.Errorf("filterFavorites() = %d cards, want 2", len(favorites))
+	}
+}
+
+// favoritesTestModel returns a model whose favorites are b (git) and d (docker)
+func favoritesTestModel() Model {
+	return testModel(&CellBlocksData{
+		Cards: []Card{
+			{ID: "a", Title: "A", CategoryID: "git"},
+			{ID: "b", Title: "B", CategoryID: "git", Starred: true},
+			{ID: "c", Title: "C", CategoryID: "docker"},
+			{ID: "d", Title: "D", CategoryID: "docker", Starred: true},
+		},
+		Categories: []Category{{ID: "docker", Name: "Docker"}, {ID: "git", Name: "Git"}},
+	})
+}
+
+func TestJumpToFavorite(t *testing.T) {
+	m := favoritesTestModel()
+
+	// Numbers past the favorites do nothing
+	for _, n := range []int{0, 3, 9} {
+		if m.jumpToFavorite(n) != nil || m.ViewMode != ViewList {
+			t.Errorf("jumpToFavorite(%d) opened view %d, want no change", n, m.ViewMode)
+		}
+	}
+
+	// A favorite hidden by the filters opens with them set aside...
+	m.SelectedCategories = map[string]bool{"git": true}
+	m.FavoritesOnly = true
+	m.updateFilteredCards()
+	m.jumpToFavorite(2)
+	if card := m.getSelectedCard(); m.ViewMode != ViewDetail || card == nil || card.ID != "d" {
+		t.Fatalf("jumpToFavorite(2) opened %+v in view %d, want d in detail view", card, m.ViewMode)
+	}
+	if len(m.SelectedCategories) != 0 || m.FavoritesOnly {
+		t.Errorf("filters kept: categories %v, favorites only %v", m.SelectedCategories, m.FavoritesOnly)
+	}
+
+	// ...and back when the detail view closes
+	model, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if m.ViewMode != ViewList || !m.SelectedCategories["git"] || !m.FavoritesOnly {
+		t.Errorf("esc: view %d, categories %v, favorites only %v; want the filtered list back",
+			m.ViewMode, m.SelectedCategories, m.FavoritesOnly)
+	}
+
+	// A trashed favorite gives up its number, later favorites move up
+	m.execute(trashCardCommand{card: m.Data.Cards[1], deletedAt: 1}, "")
+	if m.favoriteNumber("b") != 0 || m.favoriteNumber("d") != 1 {
+		t.Errorf("after trashing b: numbers b=%d d=%d, want 0 and 1", m.favoriteNumber("b"), m.favoriteNumber("d"))
+	}
+	if m.jumpToFavorite(2) != nil || m.ViewMode != ViewList {
+		t.Error("jumpToFavorite(2) opened a card with only one favorite left")
+	}
+	m.jumpToFavorite(1)
+	if card := m.getSelectedCard(); card == nil || card.ID != "d" {
+		t.Errorf("jumpToFavorite(1) opened %+v, want d", card)
+	}
+}
+
+func TestFavoriteNumber(t *testing.T) {
+	m := favoritesTestModel()
+	if m.favoriteNumber("b") != 1 || m.favoriteNumber("d") != 2 || m.favoriteNumber("a") != 0 {
+		t.Errorf("numbers a=%d b=%d d=%d, want 0, 1, 2", m.favoriteNumber("a"), m.favoriteNumber("b"), m.favoriteNumber("d"))
+	}
+
+	// Numbers follow library order, not the current filter
+	m.SelectedCategories = map[string]bool{"docker": true}
+	m.updateFilteredCards()
+	if m.favoriteNumber("d") != 2 {
+		t.Errorf("filtered to docker: d = %d, want 2", m.favoriteNumber("d"))
+	}
+
+	// Only the first nine favorites get a number
+	for i := 0; i < maxQuickFavorites; i++ {
+		m.Data.Cards = append(m.Data.Cards, Card{ID: fmt.Sprintf("s%d", i), Starred: true})
+	}
+	if m.favoriteNumber("s6") != 9 || m.favoriteNumber("s7") != 0 {
+		t.Errorf("s6 = %d, s7 = %d; want 9 and 0", m.favoriteNumber("s6"), m.favoriteNumber("s7"))
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fields.go - Custom Metadata Fields
+// Purpose: Per-category field schemas, validation, display, search filters and table columns
+//
+// Values are stored as strings on Card.Fields so the shared JSON stays simple for
+// other CellBlocks clients; the schema on the category says how to edit and show them.
+
+// Field types
+const (
+	FieldText = "text"
+	FieldEnum = "enum"
+	FieldBool = "bool"
+	FieldURL  = "url"
+)
+
+// displayLabel returns the field's label, falling back to its key
+func (f FieldDef) displayLabel() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.Key
+}
+
+// validate checks a value against the field type (empty values are always allowed)
+func (f FieldDef) validate(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch f.Type {
+	case FieldEnum:
+		for _, option := range f.Options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of: %s", f.displayLabel(), strings.Join(f.Options, ", "))
+
+	case FieldBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", f.displayLabel())
+		}
+
+	case FieldURL:
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s must be an http(s) URL", f.displayLabel())
+		}
+	}
+
+	return nil
+}
+
+// formatFieldValue returns a short display form of a field value
+func formatFieldValue(f FieldDef, value string) string {
+	if f.Type == FieldBool {
+		if value == "true" {
+			return "yes"
+		}
+		return "no"
+	}
+	return value
+}
+
+// getCategoryFields returns the schema for a category (nil if none)
+func (m *Model) getCategoryFields(categoryID string) []FieldDef {
+	if m.Data == nil {
+		return nil
+	}
+	for _, cat := range m.Data.Categories {
+		if cat.ID == categoryID {
+			return cat.Fields
+		}
+	}
+	return nil
+}
+
+// cardFieldSummary returns "label: value" pairs for a card's non-empty schema fields
+func (m *Model) cardFieldSummary(card *Card) []string {
+	var parts []string
+	for _, field := range m.getCategoryFields(card.CategoryID) {
+		if value := card.Fields[field.Key]; value != "" {
+			parts = append(parts, field.displayLabel()+": "+formatFieldValue(field, value))
+		}
+	}
+	return parts
+}
+
+// allFieldKeys returns every field key defined in any category schema, sorted
+func (m *Model) allFieldKeys() []string {
+	if m.Data == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var keys []string
+	for _, cat := range m.Data.Categories {
+		for _, field := range cat.Fields {
+			if !seen[field.Key] {
+				seen[field.Key] = true
+				keys = append(keys, field.Key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Search filters
+
+// fieldFilter is a "key:value" term in a search query
+type fieldFilter struct {
+	Key   string
+	Value string
+}
+
+var fieldFilterPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*):(.+)$`)
+
+// parseSearchQuery splits a query into free text and key:value field filters
+// URLs ("https://...") stay free text
+func parseSearchQuery(query string) (string, []fieldFilter) {
+	var words []string
+	var filters []fieldFilter
+	for _, word := range strings.Fields(query) {
+		match := fieldFilterPattern.FindStringSubmatch(word)
+		if match == nil || strings.HasPrefix(match[2], "//") {
+			words = append(words, word)
+			continue
+		}
+		filters = append(filters, fieldFilter{Key: match[1], Value: match[2]})
+	}
+	return strings.Join(words, " "), filters
+}
+
+// isBoolField reports whether the schema declares key as a bool field
+func isBoolField(schema []FieldDef, key string) bool {
+	for _, field := range schema {
+		if strings.EqualFold(field.Key, key) {
+			return field.Type == FieldBool
+		}
+	}
+	return false
+}
+
+// normalizeBool maps yes/no style words to "true"/"false" (other values unchanged)
+func normalizeBool(value string) string {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1":
+		return "true"
+	case "false", "no", "n", "0":
+		return "false"
+	}
+	return value
+}
+
+// matchesFieldFilters reports whether a card satisfies every field filter
+// Keys match case-insensitively. Fields the schema declares as bool match by
+// yes/no/true/false (unset = false); everything else matches by substring.
+func matchesFieldFilters(card Card, schema []FieldDef, filters []fieldFilter) bool {
+	for _, filter := range filters {
+		stored := ""
+		for key, value := range card.Fields {
+			if strings.EqualFold(key, filter.Key) {
+				stored = value
+				break
+			}
+		}
+
+		if isBoolField(schema, filter.Key) {
+			if stored == "" {
+				stored = "false"
+			}
+			if normalizeBool(stored) != normalizeBool(filter.Value) {
+				return false
+			}
+			continue
+		}
+
+		if !strings.Contains(strings.ToLower(stored), strings.ToLower(filter.Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Create/edit form
+
+// createFormFieldCount is the number of focusable fields in the create/edit form
+func (m *Model) createFormFieldCount() int {
+	return 3 + len(m.getCategoryFields(m.NewCardCategoryID))
+}
+
+// focusedSchemaField returns the custom field focused in the form, or nil
+func (m *Model) focusedSchemaField() *FieldDef {
+	fields := m.getCategoryFields(m.NewCardCategoryID)
+	i := m.CreateFormField - 3
+	if i < 0 || i >= len(fields) {
+		return nil
+	}
+	return &fields[i]
+}
+
+// createContentWidth is the soft-wrap width of the form's content field
+func createContentWidth(screenWidth int) int {
+	return max(20, min(screenWidth-8, 100))
+}
+
+// editCreateField applies a key to the focused title, content or text field
+// Returns false when the focus isn't on one or the key isn't an editing key
+func (m *Model) editCreateField(msg tea.KeyMsg) bool {
+	field := m.focusedSchemaField()
+	switch {
+	case m.CreateFormField == 0:
+		return m.input("title").Update(&m.NewCardTitle, msg)
+
+	case m.CreateFormField == 1:
+		in := m.input("content")
+		in.Multiline = true
+		in.Width = createContentWidth(m.Width)
+		return in.Update(&m.NewCardContent, msg)
+
+	case field != nil && (field.Type == FieldText || field.Type == FieldURL):
+		value := m.NewCardFields[field.Key]
+		if !m.input("field:" + field.Key).Update(&value, msg) {
+			return false
+		}
+		m.NewCardFields[field.Key] = value
+		return true
+	}
+	return false
+}
+
+// cycleEnumField moves an enum field to the next/previous option (including empty)
+func (m *Model) cycleEnumField(field *FieldDef, delta int) {
+	options := append([]string{""}, field.Options...)
+	current := 0
+	for i, option := range options {
+		if option == m.NewCardFields[field.Key] {
+			current = i
+			break
+		}
+	}
+	m.NewCardFields[field.Key] = options[(current+delta+len(options))%len(options)]
+}
+
+// toggleBoolField flips a bool field between true and false
+func (m *Model) toggleBoolField(field *FieldDef) {
+	if m.NewCardFields[field.Key] == "true" {
+		m.NewCardFields[field.Key] = "false"
+	} else {
+		m.NewCardFields[field.Key] = "true"
+	}
+}
+
+// buildCardFields validates form values against the category schema and returns
+// the map to store on the card. Values for keys outside the schema are kept so
+// editing doesn't drop data written by other clients.
+func buildCardFields(existing map[string]string, schema []FieldDef, values map[string]string) (map[string]string, error) {
+	result := make(map[string]string)
+	for key, value := range existing {
+		result[key] = value
+	}
+
+	for _, field := range schema {
+		value := strings.TrimSpace(values[field.Key])
+		if err := field.validate(value); err != nil {
+			return nil, err
+		}
+		if value == "" || (field.Type == FieldBool && value == "false") {
+			delete(result, field.Key)
+		} else {
+			result[field.Key] = value
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// openEditForm opens the create form pre-filled with a card for editing
+func (m *Model) openEditForm(card *Card) {
+	if card == nil {
+		return
+	}
+
+	m.EditReturnMode = m.ViewMode
+	m.ViewMode = ViewCardCreate
+	m.EditingCardID = card.ID
+	m.CreateFormField = 0
+	m.CreateFormError = ""
+	m.NewCardTitle = card.Title
+	m.NewCardContent = card.Content
+	m.NewCardCategoryID = card.CategoryID
+	m.NewCardFields = make(map[string]string)
+	m.TextInputs = nil
+	for key, value := range card.Fields {
+		m.NewCardFields[key] = value
+	}
+}
+
+// saveEditedCard applies the edit form to the card being edited (undoable)
+func (m *Model) saveEditedCard() tea.Cmd {
+	if m.NewCardTitle == "" || m.NewCardContent == "" {
+		return nil
+	}
+	index := findCardIndex(m.Data.Cards, m.EditingCardID)
+	if index < 0 {
+		m.CreateFormError = "Card no longer exists"
+		return nil
+	}
+	before := m.Data.Cards[index]
+
+	fields, err := buildCardFields(before.Fields, m.getCategoryFields(m.NewCardCategoryID), m.NewCardFields)
+	if err != nil {
+		m.CreateFormError = err.Error()
+		return nil
+	}
+
+	after := before
+	after.Title = m.NewCardTitle
+	after.Content = m.NewCardContent
+	after.CategoryID = m.NewCardCategoryID
+	after.Fields = fields
+	after.UpdatedAt = time.Now().UnixMilli()
+
+	m.EditingCardID = ""
+	m.ViewMode = m.EditReturnMode
+	if m.ViewMode == ViewDetail {
+		m.ViewMode = ViewList
+	}
+	cmd := m.execute(updateCardCommand{verb: "edit", before: before, after: after}, fmt.Sprintf("✎ Saved '%s'", after.Title))
+	if m.EditReturnMode == ViewDetail {
+		// Reopen the edited card even if the edit moved it out of the current filter
+		detailCmd, _ := m.openCardByID(after.ID)
+		return tea.Batch(cmd, detailCmd)
+	}
+	m.selectCardByID(after.ID)
+	return cmd
+}
+
+// Table columns
+
+// tableColumns returns the sortable table columns: built-ins then custom field columns
+func (m *Model) tableColumns() []string {
+	columns := append([]string{}, tableSortColumns...)
+	for _, key := range m.TableFields {
+		columns = append(columns, fieldColumnPrefix+key)
+	}
+	return columns
+}
+
+// fieldColumnPrefix marks a table sort column as a custom field
+const fieldColumnPrefix = "field:"
+
+// pickTableFields opens a picker to show/hide custom field columns in table view
+func (m *Model) pickTableFields() {
+	keys := m.allFieldKeys()
+	if len(keys) == 0 {
+		m.ReloadMessage = "No custom fields defined in any category"
+		m.ReloadMessageTime = time.Now()
+		return
+	}
+
+	shown := make(map[string]bool)
+	for _, key := range m.TableFields {
+		shown[key] = true
+	}
+	options := make([]PickerOption, len(keys))
+	for i, key := range keys {
+		label := "  " + key
+		if shown[key] {
+			label = "✓ " + key
+		}
+		options[i] = PickerOption{Label: label, Value: key}
+	}
+
+	m.askPick("Toggle table column:", options, func(m *Model, key string) tea.Cmd {
+		m.toggleTableField(key)
+		return nil
+	})
+}
+
+// toggleTableField shows or hides a custom field column
+func (m *Model) toggleTableField(key string) {
+	for i, shown := range m.TableFields {
+		if shown == key {
+			m.TableFields = append(m.TableFields[:i:i], m.TableFields[i+1:]...)
+			if m.SortColumn == fieldColumnPrefix+key {
+				m.SortColumn = "title"
+				m.updateFilteredCards()
+			}
+			return
+		}
+	}
+	m.TableFields = append(m.TableFields, key)
+}
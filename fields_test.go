@@ -0,0 +1,229 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFieldValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   FieldDef
+		value   string
+		wantErr bool
+	}{
+		{"empty always ok", FieldDef{Key: "url", Type: FieldURL}, "", false},
+		{"text anything", FieldDef{Key: "note", Type: FieldText}, "whatever", false},
+		{"enum option", FieldDef{Key: "platform", Type: FieldEnum, Options: []string{"linux", "termux"}}, "termux", false},
+		{"enum unknown", FieldDef{Key: "platform", Type: FieldEnum, Options: []string{"linux", "termux"}}, "windows", true},
+		{"bool true", FieldDef{Key: "root", Type: FieldBool}, "true", false},
+		{"bool yes", FieldDef{Key: "root", Type: FieldBool}, "yes", true},
+		{"url https", FieldDef{Key: "docs", Type: FieldURL}, "https://example.com/x", false},
+		{"url no scheme", FieldDef{Key: "docs", Type: FieldURL}, "example.com", true},
+		{"url ftp", FieldDef{Key: "docs", Type: FieldURL}, "ftp://example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.validate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantText    string
+		wantFilters []fieldFilter
+	}{
+		{"docker logs", "docker logs", nil},
+		{"platform:termux logs", "logs", []fieldFilter{{Key: "platform", Value: "termux"}}},
+		{"root:yes platform:linux", "", []fieldFilter{{Key: "root", Value: "yes"}, {Key: "platform", Value: "linux"}}},
+		{"https://example.com", "https://example.com", nil},
+		{"key:", "key:", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			text, filters := parseSearchQuery(tt.query)
+			if text != tt.wantText {
+				t.Errorf("parseSearchQuery(%q) text = %q, want %q", tt.query, text, tt.wantText)
+			}
+			if len(filters) != len(tt.wantFilters) {
+				t.Fatalf("parseSearchQuery(%q) filters = %v, want %v", tt.query, filters, tt.wantFilters)
+			}
+			for i := range filters {
+				if filters[i] != tt.wantFilters[i] {
+					t.Errorf("parseSearchQuery(%q) filters = %v, want %v", tt.query, filters, tt.wantFilters)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchesFieldFilters(t *testing.T) {
+	card := Card{Fields: map[string]string{"Platform": "termux", "root": "true", "version": "1.2", "port": "0"}}
+	schema := []FieldDef{
+		{Key: "root", Type: FieldBool},
+		{Key: "sudo", Type: FieldBool},
+		{Key: "version", Type: FieldText},
+		{Key: "port", Type: FieldText},
+	}
+
+	tests := []struct {
+		name    string
+		filters []fieldFilter
+		want    bool
+	}{
+		{"no filters", nil, true},
+		{"key case-insensitive", []fieldFilter{{Key: "platform", Value: "termux"}}, true},
+		{"value substring", []fieldFilter{{Key: "platform", Value: "term"}}, true},
+		{"value mismatch", []fieldFilter{{Key: "platform", Value: "linux"}}, false},
+		{"bool yes", []fieldFilter{{Key: "root", Value: "yes"}}, true},
+		{"bool no", []fieldFilter{{Key: "root", Value: "no"}}, false},
+		{"unset bool is false", []fieldFilter{{Key: "sudo", Value: "no"}}, true},
+		{"unset text", []fieldFilter{{Key: "author", Value: "matt"}}, false},
+		{"all must match", []fieldFilter{{Key: "platform", Value: "termux"}, {Key: "root", Value: "false"}}, false},
+		{"number-like text is a substring", []fieldFilter{{Key: "version", Value: "1"}}, true},
+		{"zero text isn't false", []fieldFilter{{Key: "port", Value: "false"}}, false},
+		{"zero text matches itself", []fieldFilter{{Key: "port", Value: "0"}}, true},
+		{"no on unset text", []fieldFilter{{Key: "author", Value: "no"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesFieldFilters(card, schema, tt.filters); got != tt.want {
+				t.Errorf("matchesFieldFilters(%v) = %v, want %v", tt.filters, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCardFields(t *testing.T) {
+	schema := []FieldDef{
+		{Key: "platform", Type: FieldEnum, Options: []string{"linux", "termux"}},
+		{Key: "root", Type: FieldBool},
+		{Key: "docs", Type: FieldURL},
+	}
+
+	existing := map[string]string{"platform": "linux", "docs": "https://old.example.com", "legacy": "kept"}
+	values := map[string]string{"platform": " termux ", "root": "false", "docs": ""}
+
+	got, err := buildCardFields(existing, schema, values)
+	if err != nil {
+		t.Fatalf("buildCardFields() error = %v", err)
+	}
+	want := map[string]string{"platform": "termux", "legacy": "kept"}
+	if len(got) != len(want) {
+		t.Fatalf("buildCardFields() = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("buildCardFields()[%q] = %q, want %q", key, got[key], value)
+		}
+	}
+	if existing["platform"] != "linux" {
+		t.Error("buildCardFields() modified the existing map")
+	}
+
+	if _, err := buildCardFields(nil, schema, map[string]string{"docs": "not a url"}); err == nil {
+		t.Error("buildCardFields() accepted an invalid URL")
+	}
+
+	if got, _ := buildCardFields(nil, schema, map[string]string{"root": "false"}); got != nil {
+		t.Errorf("buildCardFields() = %v, want nil for no values", got)
+	}
+}
+
+func TestSortCardsByField(t *testing.T) {
+	cards := []Card{
+		{ID: "a", Fields: map[string]string{"platform": "termux"}},
+		{ID: "b"},
+		{ID: "c", Fields: map[string]string{"platform": "Linux"}},
+	}
+
+	if got := cardIDs(sortCards(cards, nil, fieldColumnPrefix+"platform", "asc")); !equalStrings(got, []string{"b", "c", "a"}) {
+		t.Errorf("sortCards(asc) = %v", got)
+	}
+	if got := cardIDs(sortCards(cards, nil, fieldColumnPrefix+"platform", "desc")); !equalStrings(got, []string{"a", "c", "b"}) {
+		t.Errorf("sortCards(desc) = %v", got)
+	}
+}
+
+func TestSearchCardsFieldFilter(t *testing.T) {
+	cards := []Card{
+		{ID: "a", Title: "Tail logs", Fields: map[string]string{"platform": "termux"}},
+		{ID: "b", Title: "Tail logs", Fields: map[string]string{"platform": "linux"}},
+		{ID: "c", Title: "Install package", Fields: map[string]string{"platform": "termux"}},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"platform:termux", []string{"a", "c"}},
+		{"platform:termux logs", []string{"a"}},
+		{"platform:windows", nil},
+		{"termux", []string{"a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := cardIDs(searchCards(cards, tt.query, nil, nil)); !equalStrings(got, tt.want) {
+				t.Errorf("searchCards(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchBox(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{
+		{ID: "a", Title: "Update packages", Fields: map[string]string{"platform": "termux"}},
+		{ID: "b", Title: "Update system", Fields: map[string]string{"platform": "linux"}},
+		{ID: "c", Title: "Notes"},
+	}})
+
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		model, cmd := m.handleKeyPress(msg)
+		m = model.(Model)
+		return cmd
+	}
+	ids := func() string {
+		var got string
+		for _, card := range m.FilteredCards {
+			got += card.ID
+		}
+		return got
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if !m.SearchActive {
+		t.Fatal("/ didn't focus the search box")
+	}
+
+	// Shortcut letters type into the query ("q" doesn't quit)
+	if cmd := press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("update q")}); cmd != nil || m.SearchQuery != "update q" || ids() != "" {
+		t.Errorf("query %q matched %q", m.SearchQuery, ids())
+	}
+	press(tea.KeyMsg{Type: tea.KeyBackspace})
+	press(tea.KeyMsg{Type: tea.KeyBackspace})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" platform:termux")})
+	if ids() != "a" {
+		t.Errorf("query %q matched %q, want a", m.SearchQuery, ids())
+	}
+
+	// Enter keeps the results, Esc clears them
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.SearchActive || m.SearchQuery == "" {
+		t.Errorf("enter: active %v query %q", m.SearchActive, m.SearchQuery)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.SearchQuery != "" || ids() != "abc" {
+		t.Errorf("esc left query %q, cards %q", m.SearchQuery, ids())
+	}
+}
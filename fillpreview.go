@@ -0,0 +1,163 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fillpreview.go - Live Filled-Template Preview
+// Purpose: Show the filled template in the detail view while the form is open
+//
+// Filled values and variables still missing a value are wrapped in marker runes
+// before rendering (glamour and chroma pass them through) and turned into colors
+// afterwards. Markdown and code re-render once typing pauses; Ctrl+P switches
+// between the filled and the raw template.
+
+// detailRenderDebounce is how long typing must pause before the detail view re-renders
+const detailRenderDebounce = 150 * time.Millisecond
+
+// Marker runes (Unicode private use area) around filled and missing values
+const (
+	markFilledStart  = '\uE000'
+	markFilledEnd    = '\uE001'
+	markMissingStart = '\uE002'
+	markMissingEnd   = '\uE003'
+)
+
+// fillMarkers holds every marker rune
+const fillMarkers = "\uE000\uE001\uE002\uE003"
+
+// markdownLinePrefix matches what has to stay at the start of a markdown line
+// (indentation, list bullets, quotes, headings) for the line to keep its meaning
+var markdownLinePrefix = regexp.MustCompile(`^\s*(?:(?:[-*+>]|\d+[.)]|#{1,6})\s+)*`)
+
+// markFill wraps a filled value or a missing variable in marker runes, line by line
+// Code fences and markdown line prefixes are left outside the markers
+func markFill(text string, filled bool) string {
+	start, end := markMissingStart, markMissingEnd
+	if filled {
+		start, end = markFilledStart, markFilledEnd
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			continue
+		}
+		prefix := markdownLinePrefix.FindString(line)
+		if prefix == line {
+			continue
+		}
+		lines[i] = prefix + string(start) + line[len(prefix):] + string(end)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ansiPrefix returns the escape sequence a style starts with ("" without colors)
+func ansiPrefix(style lipgloss.Style) string {
+	prefix, _, _ := strings.Cut(style.Render("x"), "x")
+	return prefix
+}
+
+// highlightFillMarkers turns marker runes in rendered content into colors
+func highlightFillMarkers(s string) string {
+	if !strings.ContainsAny(s, fillMarkers) {
+		return s
+	}
+	return colorFillMarkers(s, ansiPrefix(styleFillValue), ansiPrefix(styleFillMissing))
+}
+
+// colorFillMarkers replaces marker runes with the filled and missing escape sequences
+// Escape sequences inside a marked span (glamour and chroma style every word)
+// are followed by the span's color again, and the outer style is restored after it
+func colorFillMarkers(s, filledStyle, missingStyle string) string {
+	const reset = "\x1b[0m"
+
+	var b strings.Builder
+	inSpan := false
+	span := ""  // Escape sequence of the open span
+	outer := "" // Escape sequences in effect outside the span
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == markFilledStart || r == markMissingStart:
+			inSpan = true
+			span = filledStyle
+			if r == markMissingStart {
+				span = missingStyle
+			}
+			b.WriteString(span)
+
+		case r == markFilledEnd || r == markMissingEnd:
+			if inSpan && span != "" {
+				b.WriteString(reset + outer)
+			}
+			inSpan = false
+
+		case r == '\x1b' && i+1 < len(s) && s[i+1] == '[':
+			// CSI sequence: ends with a byte in @-~
+			end := i + 2
+			for end < len(s) && (s[end] < '@' || s[end] > '~') {
+				end++
+			}
+			size = min(end+1, len(s)) - i
+			seq := s[i : i+size]
+			b.WriteString(seq)
+			switch {
+			case inSpan:
+				b.WriteString(span)
+			case seq == reset || seq == "\x1b[m":
+				outer = ""
+			default:
+				outer += seq
+			}
+
+		case r == '\n' && inSpan && span != "":
+			// Lines are scrolled separately, so each one carries its own color
+			b.WriteString(reset + "\n" + span)
+
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// showFilledPreview reports whether the detail view shows the card filled in
+func (m *Model) showFilledPreview(card *Card) bool {
+	return m.ShowTemplateForm && !m.ShowRawTemplate && HasTemplateVariables(card.Content)
+}
+
+// detailSource returns the content the detail view shows: the card as written,
+// or (with the form open) its filled template with fill markers
+func (m *Model) detailSource(card *Card) string {
+	if !m.showFilledPreview(card) {
+		return card.Content
+	}
+	content := m.expandCard(card).Content
+	return fillTemplate(content, maskSecrets(content, m.TemplateVars), markFill)
+}
+
+// scheduleDetailRender re-renders the detail view once its content stops changing
+// (typing into the form renders once per pause instead of once per key)
+func (m *Model) scheduleDetailRender() tea.Cmd {
+	card := m.getSelectedCard()
+	if m.ViewMode != ViewDetail || card == nil || !m.needsRender(card) {
+		return nil
+	}
+	if m.DetailRenderSource == m.detailSource(card) {
+		return nil
+	}
+	m.DetailRenderSeq++
+	m.DetailRenderPending = true
+	seq := m.DetailRenderSeq
+	return tea.Tick(detailRenderDebounce, func(time.Time) tea.Msg {
+		return detailRenderDebounceMsg{seq: seq}
+	})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// showMarkers makes fill markers readable: [filled] and <missing>
+func showMarkers(s string) string {
+	return strings.NewReplacer(
+		string(markFilledStart), "[", string(markFilledEnd), "]",
+		string(markMissingStart), "<", string(markMissingEnd), ">",
+	).Replace(s)
+}
+
+func TestFillTemplateMarks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		vars    map[string]string
+		want    string
+	}{
+		{
+			name:    "filled and missing",
+			content: "Review {{file}} for {{focus}}",
+			vars:    map[string]string{"file": "main.go"},
+			want:    "Review [main.go] for <{{focus}}>",
+		},
+		{
+			name:    "defaults count as filled",
+			content: "port {{port:int|8080}}",
+			want:    "port [8080]",
+		},
+		{
+			name:    "filters apply before marking",
+			content: "{{name::upper}}",
+			vars:    map[string]string{"name": "ada"},
+			want:    "[ADA]",
+		},
+		{
+			name:    "each items are marked, block tags aren't",
+			content: "{{#each files}}- {{this}}\n{{/each}}",
+			vars:    map[string]string{"files": "a.go, b.go"},
+			want:    "- [a.go]\n- [b.go]\n",
+		},
+		{
+			name:    "leftover include stays unmarked",
+			content: "{{> Missing}} {{x}}",
+			vars:    map[string]string{"x": "1"},
+			want:    "{{> Missing}} [1]",
+		},
+		{
+			name:    "escaped braces are text",
+			content: `\{{x}} {{x}}`,
+			vars:    map[string]string{"x": "1"},
+			want:    "{{x}} [1]",
+		},
+		{
+			name:    "multi-line value keeps markdown prefixes outside",
+			content: "Steps:\n{{steps}}",
+			vars:    map[string]string{"steps": "- one\n\n  2. two\n# Title"},
+			want:    "Steps:\n- [one]\n\n  2. [two]\n# [Title]",
+		},
+		{
+			name:    "code fences aren't marked",
+			content: "{{snippet}}",
+			vars:    map[string]string{"snippet": "```sh\nls\n```"},
+			want:    "```sh\n[ls]\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := tt.vars
+			if vars == nil {
+				vars = map[string]string{}
+			}
+			got := showMarkers(fillTemplate(tt.content, vars, markFill))
+			if got != tt.want {
+				t.Errorf("fillTemplate() = %q, want %q", got, tt.want)
+			}
+			// Without markers it's FillTemplate
+			if plain := fillTemplate(tt.content, vars, nil); plain != FillTemplate(tt.content, vars) {
+				t.Errorf("fillTemplate(nil) = %q, want FillTemplate's %q", plain, FillTemplate(tt.content, vars))
+			}
+		})
+	}
+}
+
+func TestColorFillMarkers(t *testing.T) {
+	const (
+		filled  = "\x1b[32m"
+		missing = "\x1b[33m"
+		reset   = "\x1b[0m"
+		word    = "\x1b[37m"
+	)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "no markers",
+			input: "plain " + word + "text" + reset,
+			want:  "plain " + word + "text" + reset,
+		},
+		{
+			name:  "filled and missing spans",
+			input: "a \uE000b\uE001 \uE002{{c}}\uE003",
+			want:  "a " + filled + "b" + reset + " " + missing + "{{c}}" + reset,
+		},
+		{
+			name:  "span color survives word styles and restores the outer style",
+			input: word + "x \uE000y" + reset + word + " z\uE001 w" + reset,
+			want:  word + "x " + filled + "y" + reset + filled + word + filled + " z" + reset + word + " w" + reset,
+		},
+		{
+			name:  "spans are closed and reopened at line ends",
+			input: "\uE000one\ntwo\uE001",
+			want:  filled + "one" + reset + "\n" + filled + "two" + reset,
+		},
+		{
+			name:  "without colors markers are dropped",
+			input: "\uE000one\ntwo\uE001 \uE002three\uE003",
+			want:  "one\ntwo three",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filledStyle, missingStyle := filled, missing
+			if strings.HasPrefix(tt.name, "without colors") {
+				filledStyle, missingStyle = "", ""
+			}
+			if got := colorFillMarkers(tt.input, filledStyle, missingStyle); got != tt.want {
+				t.Errorf("colorFillMarkers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetailSource(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{{ID: "a", Title: "A", Content: "Hello {{name}}"}}})
+	m.openDetailView()
+	card := m.getSelectedCard()
+	m.TemplateVars["name"] = "Ada"
+
+	if got := showMarkers(m.detailSource(card)); got != "Hello [Ada]" {
+		t.Errorf("with the form open detailSource() = %q, want the filled template", got)
+	}
+	m.ShowRawTemplate = true
+	if got := m.detailSource(card); got != card.Content {
+		t.Errorf("raw detailSource() = %q, want the card content", got)
+	}
+	m.ShowRawTemplate = false
+	m.ShowTemplateForm = false
+	if got := m.detailSource(card); got != card.Content {
+		t.Errorf("with the form hidden detailSource() = %q, want the card content", got)
+	}
+}
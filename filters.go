@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// filters.go - Template Value Filters
+// Purpose: Transform filled values with {{name::filter}} pipelines
+//
+// Filters sit between the name (and type) and the default, so "|" keeps meaning
+// "default" and the default itself may contain "::":
+//
+//	{{name::shellquote}}  {{query::trim::urlencode|cats}}  {{port:int::json|80}}
+//
+// Filters run left to right on the value (or the default), never on what's typed.
+
+// filterSeparator separates filters from the name and each other
+const filterSeparator = "::"
+
+// templateFilters is the registry of filters by name
+var templateFilters = map[string]func(string) string{
+	"shellquote": shellQuote,
+	"urlencode":  url.QueryEscape,
+	"urlpath":    url.PathEscape,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"title":      titleCase,
+	"trim":       strings.TrimSpace,
+	"slug":       func(s string) string { return joinWords(s, "-", strings.ToLower) },
+	"snake":      func(s string) string { return joinWords(s, "_", strings.ToLower) },
+	"camel":      camelCase,
+	"base64":     func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"base64url":  func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) },
+	"json":       jsonString,
+	"html":       html.EscapeString,
+}
+
+// filterNames returns the registered filter names, sorted
+func filterNames() []string {
+	names := make([]string, 0, len(templateFilters))
+	for name := range templateFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitFilters removes the "::filter" part from the inside of a tag
+// "q::trim::urlencode|x" -> ("q|x", ["trim", "urlencode"])
+// Parens (as in match(...)) and anything after the default's "|" are skipped, and
+// the chain starts at the first known filter: "ns::Class::upper" -> ("ns::Class", ["upper"])
+func splitFilters(raw string) (string, []string) {
+	if !strings.Contains(raw, filterSeparator) {
+		return raw, nil
+	}
+
+	depth, start, end := 0, -1, len(raw)
+scan:
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth = max(0, depth-1)
+		case '|':
+			if depth == 0 {
+				end = i
+				break scan
+			}
+		case ':':
+			if depth == 0 && start < 0 && strings.HasPrefix(raw[i:], filterSeparator) {
+				if templateFilters[nextFilterName(raw[i+len(filterSeparator):])] != nil {
+					start = i
+				}
+				i++
+			}
+		}
+	}
+	if start < 0 {
+		return raw, nil
+	}
+
+	var filters []string
+	for _, name := range strings.Split(raw[start+len(filterSeparator):end], filterSeparator) {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			filters = append(filters, name)
+		}
+	}
+	return strings.TrimSpace(raw[:start]) + raw[end:], filters
+}
+
+// nextFilterName returns the (lower-cased) filter name at the start of s, up to the
+// next "::" or "|"
+func nextFilterName(s string) string {
+	if end := strings.IndexAny(s, ":|"); end >= 0 {
+		s = s[:end]
+	}
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// checkFilters returns an error naming the first unknown filter
+func checkFilters(filters []string) error {
+	for _, name := range filters {
+		if templateFilters[name] == nil {
+			return fmt.Errorf("unknown filter '%s' (have %s)", name, strings.Join(filterNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// applyFilters runs value through the filters in order (unknown filters are skipped)
+func applyFilters(value string, filters []string) string {
+	for _, name := range filters {
+		if filter := templateFilters[name]; filter != nil {
+			value = filter(value)
+		}
+	}
+	return value
+}
+
+// Filters
+
+// shellSafe matches values that need no quoting in POSIX shells
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes a value as one POSIX shell word (like Python's shlex.quote)
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// splitWords splits on anything that isn't a letter or digit
+func splitWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// joinWords joins the words of s with sep after mapping each one
+func joinWords(s, sep string, mapWord func(string) string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		words[i] = mapWord(word)
+	}
+	return strings.Join(words, sep)
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if size == 0 {
+		return word
+	}
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// titleCase capitalizes every space-separated word, keeping the spacing
+func titleCase(s string) string {
+	words := strings.Split(s, " ")
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// camelCase joins words as lowerCamelCase
+func camelCase(s string) string {
+	words := splitWords(s)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			word = capitalize(word)
+		}
+		words[i] = word
+	}
+	return strings.Join(words, "")
+}
+
+// jsonString encodes a value as a JSON string literal (without escaping <, > and &)
+func jsonString(s string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
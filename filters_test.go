@@ -0,0 +1,126 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTemplateFilters(t *testing.T) {
+	tests := []struct {
+		filter string
+		input  string
+		want   string
+	}{
+		{"shellquote", "simple-path/file.txt", "simple-path/file.txt"},
+		{"shellquote", "it's a test", `'it'"'"'s a test'`},
+		{"shellquote", "", "''"},
+		{"shellquote", "$(rm -rf /)", "'$(rm -rf /)'"},
+		{"urlencode", "cats & dogs?", "cats+%26+dogs%3F"},
+		{"urlpath", "a b/c", "a%20b%2Fc"},
+		{"upper", "Hello", "HELLO"},
+		{"lower", "Hello", "hello"},
+		{"title", "hello  big world", "Hello  Big World"},
+		{"trim", "  padded \n", "padded"},
+		{"slug", "Hello, World! 2024", "hello-world-2024"},
+		{"slug", "Crème brûlée", "crème-brûlée"},
+		{"snake", "Max Retry-Count", "max_retry_count"},
+		{"camel", "max retry_count", "maxRetryCount"},
+		{"base64", "hello?", "aGVsbG8/"},
+		{"base64url", "hello?", "aGVsbG8_"},
+		{"json", `say "hi" <b>`, `"say \"hi\" <b>"`},
+		{"html", `<a href="x">`, "&lt;a href=&#34;x&#34;&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter+"/"+tt.input, func(t *testing.T) {
+			if got := applyFilters(tt.input, []string{tt.filter}); got != tt.want {
+				t.Errorf("%s(%q) = %q, want %q", tt.filter, tt.input, got, tt.want)
+			}
+		})
+	}
+
+	// Every registered filter is covered above
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		covered[tt.filter] = true
+	}
+	for _, name := range filterNames() {
+		if !covered[name] {
+			t.Errorf("filter %q has no test", name)
+		}
+	}
+}
+
+func TestSplitFilters(t *testing.T) {
+	tests := []struct {
+		raw         string
+		wantRest    string
+		wantFilters []string
+	}{
+		{"name", "name", nil},
+		{"name::shellquote", "name", []string{"shellquote"}},
+		{"query :: Trim :: urlencode|cats::dogs", "query|cats::dogs", []string{"trim", "urlencode"}},
+		{"port:int::json|80", "port:int|80", []string{"json"}},
+		{"tag:match(^a::b$)::upper", "tag:match(^a::b$)", []string{"upper"}},
+		{"name|default::upper", "name|default::upper", nil},
+		{"ns::Class", "ns::Class", nil},
+		{"ns::Class::upper", "ns::Class", []string{"upper"}},
+		{"name::upper::nope", "name", []string{"upper", "nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			rest, filters := splitFilters(tt.raw)
+			if rest != tt.wantRest || !reflect.DeepEqual(filters, tt.wantFilters) {
+				t.Errorf("splitFilters(%q) = (%q, %v), want (%q, %v)", tt.raw, rest, filters, tt.wantRest, tt.wantFilters)
+			}
+		})
+	}
+}
+
+func TestFillTemplateFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		vars    map[string]string
+		want    string
+	}{
+		{"filter a value", "grep {{pattern::shellquote}} .", map[string]string{"pattern": "foo bar"}, "grep 'foo bar' ."},
+		{"filter the default", "?q={{query::urlencode|cats & dogs}}", nil, "?q=cats+%26+dogs"},
+		{"chain", "{{name::trim::slug::upper}}", map[string]string{"name": " My Post "}, "MY-POST"},
+		{"same variable, different filters", "{{t}} {{t::upper}}", map[string]string{"t": "ab"}, "ab AB"},
+		{"typed with filter", "{{port:int::json|80}}", nil, `"80"`},
+		{"each item", "{{#each f}}{{this::shellquote}} {{/each}}", map[string]string{"f": "a b,c"}, "'a b' c "},
+		{"unfilled keeps tag", "{{x::upper}}", nil, "{{x::upper}}"},
+		{"unknown filter is part of the name", "{{ns::Class}}", map[string]string{"ns::Class": "Foo"}, "Foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FillTemplate(tt.content, tt.vars); got != tt.want {
+				t.Errorf("FillTemplate(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+
+	// Filters don't create separate fields
+	if got := ExtractVariables("{{t}} {{t::upper}} {{q::urlencode|x}}"); !reflect.DeepEqual(got, []string{"t", "q"}) {
+		t.Errorf("ExtractVariables() = %v, want [t q]", got)
+	}
+	// An unknown name after "::" is part of the variable name, not a filter
+	if v := ParseTemplateVar("ns::Class"); v.Name != "ns::Class" || v.Err != "" || v.Filters != nil {
+		t.Errorf("ParseTemplateVar(ns::Class) = %+v, want the whole name", v)
+	}
+	if v := ParseTemplateVar("x::upper::nope"); v.Name != "x" || v.Err == "" {
+		t.Errorf("ParseTemplateVar(unknown filter in a chain) = %+v, want an error", v)
+	}
+
+	now := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	if got, err := resolveBuiltinVar("@date:Jan 2::upper", now); got != "MAR 5" || err != nil {
+		t.Errorf("resolveBuiltinVar() with filter = (%q, %v), want MAR 5", got, err)
+	}
+	if _, err := resolveBuiltinVar("@date::upper::nope", now); err == nil {
+		t.Error("resolveBuiltinVar() with unknown filter should fail")
+	}
+}
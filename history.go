@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// history.go - Undo/Redo
+// Purpose: Session-wide stack of invertible library mutations
+//
+// Every change to the card library goes through a libraryCommand so it can be
+// undone (u) and redone (ctrl+r). Commands are applied to the in-memory data and
+// then written through the normal save path.
+
+// maxHistory caps the undo stack so long sessions don't grow without bound
+const maxHistory = 100
+
+// libraryCommand is a reversible change to the card library
+type libraryCommand interface {
+	// apply performs the change; returns false if it no longer applies
+	// (e.g. the card was removed by an external reload)
+	apply(data *CellBlocksData) bool
+	// invert returns the command that undoes this one
+	invert() libraryCommand
+	// describe returns a short label like "delete 'Docker Run'"
+	describe() string
+	// cardID is the card to select after the command runs
+	cardID() string
+}
+
+// createCardCommand adds a new card to the library
+type createCardCommand struct {
+	card Card
+}
+
+func (c createCardCommand) apply(data *CellBlocksData) bool {
+	if findCardIndex(data.Cards, c.card.ID) >= 0 {
+		return false
+	}
+	data.Cards = append(data.Cards, c.card)
+	return true
+}
+
+func (c createCardCommand) invert() libraryCommand { return removeCardCommand{card: c.card} }
+func (c createCardCommand) describe() string       { return fmt.Sprintf("create '%s'", c.card.Title) }
+func (c createCardCommand) cardID() string         { return c.card.ID }
+
+// removeCardCommand removes a card outright (only used to undo a create)
+type removeCardCommand struct {
+	card Card
+}
+
+func (c removeCardCommand) apply(data *CellBlocksData) bool {
+	i := findCardIndex(data.Cards, c.card.ID)
+	if i < 0 {
+		return false
+	}
+	data.Cards = append(data.Cards[:i], data.Cards[i+1:]...)
+	return true
+}
+
+func (c removeCardCommand) invert() libraryCommand { return createCardCommand{card: c.card} }
+func (c removeCardCommand) describe() string       { return fmt.Sprintf("remove '%s'", c.card.Title) }
+func (c removeCardCommand) cardID() string         { return c.card.ID }
+
+// trashCardCommand moves a card into the trash
+type trashCardCommand struct {
+	card      Card
+	deletedAt int64
+}
+
+func (c trashCardCommand) apply(data *CellBlocksData) bool {
+	return trashCard(data, c.card.ID, time.UnixMilli(c.deletedAt)) != nil
+}
+
+func (c trashCardCommand) invert() libraryCommand {
+	return restoreCardCommand{entry: TrashedCard{Card: c.card, DeletedAt: c.deletedAt}}
+}
+func (c trashCardCommand) describe() string { return fmt.Sprintf("delete '%s'", c.card.Title) }
+func (c trashCardCommand) cardID() string   { return c.card.ID }
+
+// restoreCardCommand moves a trashed card back into the library
+type restoreCardCommand struct {
+	entry TrashedCard
+}
+
+func (c restoreCardCommand) apply(data *CellBlocksData) bool {
+	return restoreCard(data, c.entry.ID) != nil
+}
+
+func (c restoreCardCommand) invert() libraryCommand {
+	return trashCardCommand{card: c.entry.Card, deletedAt: c.entry.DeletedAt}
+}
+func (c restoreCardCommand) describe() string { return fmt.Sprintf("restore '%s'", c.entry.Title) }
+func (c restoreCardCommand) cardID() string   { return c.entry.ID }
+
+// purgeCardCommand permanently removes a card from the trash
+// Undoing it puts the entry back in the trash for the rest of the session
+type purgeCardCommand struct {
+	entry TrashedCard
+}
+
+func (c purgeCardCommand) apply(data *CellBlocksData) bool {
+	return purgeCard(data, c.entry.ID) != nil
+}
+
+func (c purgeCardCommand) invert() libraryCommand { return unpurgeCardCommand{entry: c.entry} }
+func (c purgeCardCommand) describe() string       { return fmt.Sprintf("purge '%s'", c.entry.Title) }
+func (c purgeCardCommand) cardID() string         { return c.entry.ID }
+
+// unpurgeCardCommand puts a purged entry back into the trash
+type unpurgeCardCommand struct {
+	entry TrashedCard
+}
+
+func (c unpurgeCardCommand) apply(data *CellBlocksData) bool {
+	for _, entry := range data.Trash {
+		if entry.ID == c.entry.ID {
+			return false
+		}
+	}
+	data.Trash = append(data.Trash, c.entry)
+	return true
+}
+
+func (c unpurgeCardCommand) invert() libraryCommand { return purgeCardCommand{entry: c.entry} }
+func (c unpurgeCardCommand) describe() string       { return fmt.Sprintf("unpurge '%s'", c.entry.Title) }
+func (c unpurgeCardCommand) cardID() string         { return c.entry.ID }
+
+// updateCardCommand replaces a card with an edited copy (move, tag, edit...)
+type updateCardCommand struct {
+	verb   string // e.g. "move", "tag"
+	before Card
+	after  Card
+}
+
+func (c updateCardCommand) apply(data *CellBlocksData) bool {
+	i := findCardIndex(data.Cards, c.before.ID)
+	if i < 0 {
+		return false
+	}
+	data.Cards[i] = c.after
+	return true
+}
+
+func (c updateCardCommand) invert() libraryCommand {
+	return updateCardCommand{verb: c.verb, before: c.after, after: c.before}
+}
+func (c updateCardCommand) describe() string { return fmt.Sprintf("%s '%s'", c.verb, c.before.Title) }
+func (c updateCardCommand) cardID() string   { return c.before.ID }
+
+// updateCategoryCommand replaces a category's settings (name and ID stay the same)
+type updateCategoryCommand struct {
+	verb   string // e.g. "code block copy"
+	before Category
+	after  Category
+}
+
+func (c updateCategoryCommand) apply(data *CellBlocksData) bool {
+	for i := range data.Categories {
+		if data.Categories[i].ID == c.before.ID {
+			data.Categories[i] = c.after
+			return true
+		}
+	}
+	return false
+}
+
+func (c updateCategoryCommand) invert() libraryCommand {
+	return updateCategoryCommand{verb: c.verb, before: c.after, after: c.before}
+}
+func (c updateCategoryCommand) describe() string { return fmt.Sprintf("%s '%s'", c.verb, c.before.Name) }
+func (c updateCategoryCommand) cardID() string   { return "" }
+
+// batchCommand groups several commands into one undo step
+type batchCommand struct {
+	label    string
+	commands []libraryCommand
+}
+
+// apply runs all commands or none: if one no longer applies, the ones before it
+// are rolled back, so undoing the batch never inverts a change that didn't happen
+func (c batchCommand) apply(data *CellBlocksData) bool {
+	for i, cmd := range c.commands {
+		if cmd.apply(data) {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			c.commands[j].invert().apply(data)
+		}
+		return false
+	}
+	return len(c.commands) > 0
+}
+
+func (c batchCommand) invert() libraryCommand {
+	// Undo in reverse order so dependent changes unwind cleanly
+	inverted := make([]libraryCommand, len(c.commands))
+	for i, cmd := range c.commands {
+		inverted[len(c.commands)-1-i] = cmd.invert()
+	}
+	return batchCommand{label: c.label, commands: inverted}
+}
+
+func (c batchCommand) describe() string { return c.label }
+
+func (c batchCommand) cardID() string {
+	if len(c.commands) == 0 {
+		return ""
+	}
+	return c.commands[0].cardID()
+}
+
+// findCardIndex returns the index of a card by ID, or -1 if not found
+func findCardIndex(cards []Card, cardID string) int {
+	for i, card := range cards {
+		if card.ID == cardID {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyCommand runs a command and records it on the undo stack
+// Returns false if the command didn't apply
+func (m *Model) applyCommand(cmd libraryCommand) bool {
+	if m.Data == nil || !cmd.apply(m.Data) {
+		return false
+	}
+
+	m.UndoStack = append(m.UndoStack, cmd)
+	if len(m.UndoStack) > maxHistory {
+		m.UndoStack = m.UndoStack[len(m.UndoStack)-maxHistory:]
+	}
+	// A new change invalidates anything that was undone
+	m.RedoStack = nil
+
+	m.buildCategoryMap() // Category commands change cached category settings
+	m.updateFilteredCards()
+	return true
+}
+
+// execute applies a command, records it for undo and saves
+func (m *Model) execute(cmd libraryCommand, message string) tea.Cmd {
+	if !m.applyCommand(cmd) {
+		return nil
+	}
+	return m.saveDataAsync(message)
+}
+
+// mergeLastCommands folds the last n undo entries into one batch, for follow-up
+// changes (like rewriting links after a rename) that a single undo should revert
+func (m *Model) mergeLastCommands(n int, label string) {
+	if n < 2 || len(m.UndoStack) < n {
+		return
+	}
+	start := len(m.UndoStack) - n
+	commands := append([]libraryCommand(nil), m.UndoStack[start:]...)
+	m.UndoStack = append(m.UndoStack[:start], batchCommand{label: label, commands: commands})
+}
+
+// undo reverts the most recent library change and saves
+func (m *Model) undo() tea.Cmd {
+	if len(m.UndoStack) == 0 {
+		m.ReloadMessage = "Nothing to undo"
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+
+	cmd := m.UndoStack[len(m.UndoStack)-1]
+	m.UndoStack = m.UndoStack[:len(m.UndoStack)-1]
+
+	if m.Data == nil || !cmd.invert().apply(m.Data) {
+		// The library changed underneath us (e.g. external reload) - drop the entry
+		m.ReloadMessage = fmt.Sprintf("⚠ Can't undo: %s (card changed)", cmd.describe())
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+	m.RedoStack = append(m.RedoStack, cmd)
+
+	m.buildCategoryMap()
+	m.updateFilteredCards()
+	m.selectCardByID(cmd.cardID())
+	return m.saveDataAsync(fmt.Sprintf("↶ undone: %s", cmd.describe()))
+}
+
+// redo re-applies the most recently undone library change and saves
+func (m *Model) redo() tea.Cmd {
+	if len(m.RedoStack) == 0 {
+		m.ReloadMessage = "Nothing to redo"
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+
+	cmd := m.RedoStack[len(m.RedoStack)-1]
+	m.RedoStack = m.RedoStack[:len(m.RedoStack)-1]
+
+	if m.Data == nil || !cmd.apply(m.Data) {
+		m.ReloadMessage = fmt.Sprintf("⚠ Can't redo: %s (card changed)", cmd.describe())
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+	m.UndoStack = append(m.UndoStack, cmd)
+
+	m.buildCategoryMap()
+	m.updateFilteredCards()
+	m.selectCardByID(cmd.cardID())
+	return m.saveDataAsync(fmt.Sprintf("↷ redone: %s", cmd.describe()))
+}
+
+// selectCardByID moves the selection to a card if it's visible with current filters
+func (m *Model) selectCardByID(cardID string) {
+	// Trashed cards live in the trash screen, not the card list
+	if m.ViewMode == ViewTrash && m.Data != nil {
+		for i, entry := range m.Data.Trash {
+			if entry.ID == cardID {
+				m.TrashCursorIndex = i
+				return
+			}
+		}
+		m.clampTrashCursor()
+		return
+	}
+
+	index := findCardIndex(m.FilteredCards, cardID)
+	if index < 0 {
+		return
+	}
+
+	m.SelectedIndex = index
+	m.PreviewedIndex = index
+	m.PreviewScrollOffset = 0
+	if m.ViewMode == ViewGrid {
+		m.ensureGridSelectionVisible()
+	} else {
+		m.ensureListSelectionVisible()
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLibraryCommandsInvert(t *testing.T) {
+	data := &CellBlocksData{
+		Cards: []Card{{ID: "a", Title: "Docker Run", CategoryID: "docker"}},
+	}
+
+	commands := []libraryCommand{
+		createCardCommand{card: Card{ID: "b", Title: "Git Log"}},
+		trashCardCommand{card: data.Cards[0], deletedAt: 1000},
+	}
+
+	for _, cmd := range commands {
+		if !cmd.apply(data) {
+			t.Fatalf("%s: apply() = false", cmd.describe())
+		}
+	}
+	if len(data.Cards) != 1 || data.Cards[0].ID != "b" || len(data.Trash) != 1 {
+		t.Fatalf("after apply: cards=%+v trash=%+v", data.Cards, data.Trash)
+	}
+
+	// Undo in reverse order
+	for i := len(commands) - 1; i >= 0; i-- {
+		if !commands[i].invert().apply(data) {
+			t.Fatalf("%s: invert().apply() = false", commands[i].describe())
+		}
+	}
+	if len(data.Cards) != 1 || data.Cards[0].ID != "a" || len(data.Trash) != 0 {
+		t.Errorf("after undo: cards=%+v trash=%+v, want only 'a' and empty trash", data.Cards, data.Trash)
+	}
+
+	// Re-applying a create for an existing card must not duplicate it
+	if (createCardCommand{card: Card{ID: "a"}}).apply(data) {
+		t.Error("createCardCommand applied twice for the same ID")
+	}
+}
+
+func TestBatchCommandInvert(t *testing.T) {
+	data := &CellBlocksData{
+		Trash: []TrashedCard{
+			{Card: Card{ID: "a", Title: "A"}, DeletedAt: 1},
+			{Card: Card{ID: "b", Title: "B"}, DeletedAt: 2},
+		},
+	}
+
+	batch := batchCommand{label: "empty trash", commands: []libraryCommand{
+		purgeCardCommand{entry: data.Trash[0]},
+		purgeCardCommand{entry: data.Trash[1]},
+	}}
+
+	if !batch.apply(data) || len(data.Trash) != 0 {
+		t.Fatalf("batch apply left trash = %+v", data.Trash)
+	}
+	if !batch.invert().apply(data) || len(data.Trash) != 2 {
+		t.Errorf("batch undo left trash = %+v, want 2 entries", data.Trash)
+	}
+}
+
+func TestBatchCommandAllOrNothing(t *testing.T) {
+	data := &CellBlocksData{
+		Cards: []Card{{ID: "a", Title: "A"}, {ID: "b", Title: "B"}},
+	}
+
+	// The create no longer applies (the card exists), so the trash is rolled back
+	batch := batchCommand{label: "mixed", commands: []libraryCommand{
+		trashCardCommand{card: data.Cards[0], deletedAt: 1},
+		createCardCommand{card: Card{ID: "b", Title: "B"}},
+	}}
+	if batch.apply(data) {
+		t.Fatal("batch applied although one command didn't")
+	}
+	if ids := cardIDs(data.Cards); !equalStrings(ids, []string{"a", "b"}) || len(data.Trash) != 0 {
+		t.Errorf("after failed batch: cards %v, trash %+v; want the library unchanged", ids, data.Trash)
+	}
+}
+
+func TestUndoDeleteKeepsPosition(t *testing.T) {
+	data := &CellBlocksData{
+		Cards: []Card{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}},
+	}
+
+	batch := batchCommand{label: "delete 3 cards", commands: []libraryCommand{
+		trashCardCommand{card: data.Cards[0], deletedAt: 1},
+		trashCardCommand{card: data.Cards[2], deletedAt: 1},
+		trashCardCommand{card: data.Cards[3], deletedAt: 1},
+	}}
+	if !batch.apply(data) {
+		t.Fatal("batch apply() = false")
+	}
+	if !batch.invert().apply(data) {
+		t.Fatal("batch undo = false")
+	}
+	if ids := cardIDs(data.Cards); !equalStrings(ids, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("after undo: cards %v, want the original order", ids)
+	}
+}
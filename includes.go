@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// includes.go - Template Includes
+// Purpose: Expand {{> Card Title}} / {{> id:...}} with the content of another card
+//
+// Includes expand before variables are detected or filled, so an included card's
+// {{variables}} show up in the form like the card's own. Includes nest; cycles and
+// runaway nesting are reported and leave the directive in place.
+
+// includePrefix marks an include directive inside {{...}}
+const includePrefix = ">"
+
+// maxIncludeDepth bounds nested includes
+const maxIncludeDepth = 8
+
+// isIncludeDirective reports whether the inside of {{...}} is an include
+func isIncludeDirective(raw string) bool {
+	return strings.HasPrefix(strings.TrimSpace(raw), includePrefix)
+}
+
+// includeExpansion is a card's content with its includes expanded
+type includeExpansion struct {
+	Content  string
+	Included []string // IDs of included cards (including nested ones), in order
+	Errors   []string // Broken includes, cycles, too deep
+}
+
+// expandIncludes expands the includes in a card's content, recursively
+func expandIncludes(cards []Card, card *Card) includeExpansion {
+	result := includeExpansion{}
+	seen := make(map[string]bool)
+	result.Content = expandIncludesIn(cards, card.Content, []*Card{card}, &result, seen)
+	return result
+}
+
+// expandIncludesIn expands the includes in content; path holds the cards being expanded
+// The result is still template source (escapes and other tags are kept as written)
+func expandIncludesIn(cards []Card, content string, path []*Card, result *includeExpansion, seen map[string]bool) string {
+	tokens, _ := tokenizeTemplate(content)
+	var b strings.Builder
+	for _, token := range tokens {
+		if token.Tag && isIncludeDirective(token.Text) {
+			b.WriteString(expandInclude(cards, token, path, result, seen))
+		} else {
+			b.WriteString(token.Raw)
+		}
+	}
+	return b.String()
+}
+
+// expandInclude returns the expanded content of one include tag (or the tag itself if it's broken)
+func expandInclude(cards []Card, tag templateToken, path []*Card, result *includeExpansion, seen map[string]bool) string {
+	target := strings.TrimSpace(strings.TrimPrefix(tag.Text, includePrefix))
+
+	included := resolveLink(cards, target)
+	if included == nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("no card '%s'", target))
+		return tag.Raw
+	}
+	for i, card := range path {
+		if card.ID == included.ID {
+			var titles []string
+			for _, c := range path[i:] {
+				titles = append(titles, c.Title)
+			}
+			result.Errors = append(result.Errors, "include cycle: "+strings.Join(append(titles, included.Title), " → "))
+			return tag.Raw
+		}
+	}
+	if len(path) > maxIncludeDepth {
+		result.Errors = append(result.Errors, fmt.Sprintf("includes nested deeper than %d at '%s'", maxIncludeDepth, included.Title))
+		return tag.Raw
+	}
+
+	if !seen[included.ID] {
+		seen[included.ID] = true
+		result.Included = append(result.Included, included.ID)
+	}
+	// Trailing newlines would leave a gap where the directive sat on its own line
+	expanded := expandIncludesIn(cards, included.Content, append(path, included), result, seen)
+	return strings.TrimRight(expanded, "\n")
+}
+
+// expandCard returns a card's content with includes expanded
+func (m *Model) expandCard(card *Card) includeExpansion {
+	if m.Data == nil || !strings.Contains(card.Content, "{{") {
+		return includeExpansion{Content: card.Content}
+	}
+	return expandIncludes(m.Data.Cards, card)
+}
+
+// includeSummary returns the titles of included cards and include problems for the detail view
+func (m *Model) includeSummary(card *Card) ([]string, []string) {
+	expansion := m.expandCard(card)
+	var titles []string
+	for _, id := range expansion.Included {
+		if i := findCardIndex(m.Data.Cards, id); i >= 0 {
+			titles = append(titles, m.Data.Cards[i].Title)
+		}
+	}
+	return titles, expansion.Errors
+}
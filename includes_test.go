@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandIncludes(t *testing.T) {
+	cards := []Card{
+		{ID: "pre", Title: "Go Reviewer", Content: "You are a senior Go reviewer.\n"},
+		{ID: "style", Title: "Style", Content: "{{> Go Reviewer}} Focus on {{focus|naming}}."},
+		{ID: "review", Title: "Review", Content: "{{> Style}}\n\nReview {{file}}. {{> id:pre}}"},
+		{ID: "self", Title: "Self", Content: "a {{> Self}}"},
+		{ID: "ping", Title: "Ping", Content: "ping {{> Pong}}"},
+		{ID: "pong", Title: "Pong", Content: "pong {{> Ping}}"},
+		{ID: "broken", Title: "Broken", Content: "x {{> Missing}} y"},
+		{ID: "plain", Title: "Plain", Content: "{{name}} and {{ >go reviewer }}"},
+	}
+
+	tests := []struct {
+		name         string
+		cardID       string
+		wantContent  string
+		wantIncluded []string
+		wantErr      string
+	}{
+		{
+			name:         "nested includes by title and id",
+			cardID:       "review",
+			wantContent:  "You are a senior Go reviewer. Focus on {{focus|naming}}.\n\nReview {{file}}. You are a senior Go reviewer.",
+			wantIncluded: []string{"style", "pre"},
+		},
+		{
+			name:         "case-insensitive title with spaces",
+			cardID:       "plain",
+			wantContent:  "{{name}} and You are a senior Go reviewer.",
+			wantIncluded: []string{"pre"},
+		},
+		{
+			name:        "self include",
+			cardID:      "self",
+			wantContent: "a {{> Self}}",
+			wantErr:     "include cycle: Self → Self",
+		},
+		{
+			name:         "indirect cycle",
+			cardID:       "ping",
+			wantContent:  "ping pong {{> Ping}}",
+			wantIncluded: []string{"pong"},
+			wantErr:      "include cycle: Ping → Pong → Ping",
+		},
+		{
+			name:        "missing card",
+			cardID:      "broken",
+			wantContent: "x {{> Missing}} y",
+			wantErr:     "no card 'Missing'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &cards[findCardIndex(cards, tt.cardID)]
+			got := expandIncludes(cards, card)
+			if got.Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", got.Content, tt.wantContent)
+			}
+			if !equalStrings(got.Included, tt.wantIncluded) {
+				t.Errorf("included = %v, want %v", got.Included, tt.wantIncluded)
+			}
+			if tt.wantErr == "" && len(got.Errors) > 0 {
+				t.Errorf("unexpected errors %v", got.Errors)
+			}
+			if tt.wantErr != "" && (len(got.Errors) == 0 || got.Errors[0] != tt.wantErr) {
+				t.Errorf("errors = %v, want %q", got.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandIncludesDepthLimit(t *testing.T) {
+	// A chain one longer than the limit: c0 includes c1 includes c2 ...
+	var cards []Card
+	for i := 0; i <= maxIncludeDepth+1; i++ {
+		content := "end"
+		if i <= maxIncludeDepth {
+			content = "{{> id:c" + string(rune('a'+i+1)) + "}}"
+		}
+		cards = append(cards, Card{ID: "c" + string(rune('a'+i)), Title: "C" + string(rune('a'+i)), Content: content})
+	}
+
+	got := expandIncludes(cards, &cards[0])
+	if len(got.Errors) != 1 || !strings.Contains(got.Errors[0], "nested deeper than") {
+		t.Errorf("errors = %v, want a depth error", got.Errors)
+	}
+	if strings.Contains(got.Content, "end") {
+		t.Errorf("content = %q, expanded past the depth limit", got.Content)
+	}
+}
+
+func TestIncludedVariables(t *testing.T) {
+	cards := []Card{
+		{ID: "pre", Title: "Preamble", Content: "Hi {{name}}, today is {{@date}}."},
+		{ID: "main", Title: "Main", Content: "{{> Preamble}} Do {{task:choice(a,b)}}. Thanks {{name}}."},
+	}
+	expanded := expandIncludes(cards, &cards[1]).Content
+
+	if got := ExtractVariables(expanded); !equalStrings(got, []string{"name", "task"}) {
+		t.Errorf("ExtractVariables() = %v, want [name task]", got)
+	}
+	// Include directives are never form fields, and broken ones survive filling
+	if got := ExtractVariables("{{> Missing}} {{x}}"); !equalStrings(got, []string{"x"}) {
+		t.Errorf("ExtractVariables() with directive = %v, want [x]", got)
+	}
+	if got := FillTemplate("{{> Missing}} {{x}}", map[string]string{"x": "1"}); got != "{{> Missing}} 1" {
+		t.Errorf("FillTemplate() = %q", got)
+	}
+}
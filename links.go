@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// links.go - Wiki-Style Card Links
+// Purpose: Parse [[Card Title]] / [[id:...]] links, resolve backlinks and follow links in detail view
+
+var (
+	// linkPattern matches [[Target]]; targets can't start or end with whitespace,
+	// so shell tests like [[ -f file ]] aren't mistaken for links
+	linkPattern = regexp.MustCompile(`\[\[([^\s\[\]](?:[^\[\]\n]*[^\s\[\]])?)\]\]`)
+
+	// renderedLinkPattern matches [[...]] in glamour output, where ANSI codes
+	// may sit between the words of a link
+	renderedLinkPattern = regexp.MustCompile(`\[\[((?:\x1b\[[0-9;]*m|[^\]\n\x1b])+?)\]\]`)
+
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// linkIDPrefix marks a link by card ID instead of title
+const linkIDPrefix = "id:"
+
+// parseCardLinks returns the unique link targets in content, in order of appearance
+func parseCardLinks(content string) []string {
+	var targets []string
+	seen := make(map[string]bool)
+	for _, match := range linkPattern.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			targets = append(targets, match[1])
+		}
+	}
+	return targets
+}
+
+// resolveLink finds the card a link target points to
+// Titles match case-insensitively; returns nil for broken links
+func resolveLink(cards []Card, target string) *Card {
+	if id, ok := strings.CutPrefix(target, linkIDPrefix); ok {
+		if i := findCardIndex(cards, strings.TrimSpace(id)); i >= 0 {
+			return &cards[i]
+		}
+		return nil
+	}
+
+	for i := range cards {
+		if strings.EqualFold(cards[i].Title, target) {
+			return &cards[i]
+		}
+	}
+	return nil
+}
+
+// linksTo reports whether content contains a link to the card
+func linksTo(cards []Card, content string, cardID string) bool {
+	for _, target := range parseCardLinks(content) {
+		if linked := resolveLink(cards, target); linked != nil && linked.ID == cardID {
+			return true
+		}
+	}
+	return false
+}
+
+// findBacklinks returns the cards that link to a card, in library order
+func findBacklinks(cards []Card, cardID string) []Card {
+	var backlinks []Card
+	for _, card := range cards {
+		if card.ID != cardID && linksTo(cards, card.Content, cardID) {
+			backlinks = append(backlinks, card)
+		}
+	}
+	return backlinks
+}
+
+// rewriteTitleLinks replaces [[oldTitle]] links (any case) with [[newTitle]]
+// ID links are left alone since they survive renames
+func rewriteTitleLinks(content, oldTitle, newTitle string) (string, int) {
+	count := 0
+	rewritten := linkPattern.ReplaceAllStringFunc(content, func(link string) string {
+		target := link[2 : len(link)-2]
+		if !strings.EqualFold(target, oldTitle) {
+			return link
+		}
+		count++
+		return "[[" + newTitle + "]]"
+	})
+	return rewritten, count
+}
+
+// highlightLinks styles [[links]] in rendered (possibly ANSI-styled) content
+// Broken links are struck through; the focused link target is shown inverted
+func highlightLinks(rendered string, cards []Card, focused string) string {
+	return renderedLinkPattern.ReplaceAllStringFunc(rendered, func(match string) string {
+		link := ansiPattern.ReplaceAllString(match, "")
+		if !linkPattern.MatchString(link) {
+			return match
+		}
+
+		target := link[2 : len(link)-2]
+		switch {
+		case target == focused:
+			return styleLinkFocused.Render(link)
+		case resolveLink(cards, target) == nil:
+			return styleLinkBroken.Render(link)
+		default:
+			return styleLink.Render(link)
+		}
+	})
+}
+
+// detailLink is a link reachable with Tab in detail view
+type detailLink struct {
+	Label    string // Link target as written, or backlink card title
+	CardID   string // Empty when the link is broken
+	Backlink bool   // True for "Linked from" entries
+}
+
+// getDetailLinks returns a card's outgoing links followed by its backlinks
+func (m *Model) getDetailLinks(card *Card) []detailLink {
+	if card == nil || m.Data == nil {
+		return nil
+	}
+
+	var links []detailLink
+	for _, target := range parseCardLinks(card.Content) {
+		link := detailLink{Label: target}
+		if linked := resolveLink(m.Data.Cards, target); linked != nil {
+			link.CardID = linked.ID
+		}
+		links = append(links, link)
+	}
+	for _, backlink := range findBacklinks(m.Data.Cards, card.ID) {
+		links = append(links, detailLink{Label: backlink.Title, CardID: backlink.ID, Backlink: true})
+	}
+	return links
+}
+
+// getFocusedDetailLink returns the link focused with Tab, or nil if none
+func (m *Model) getFocusedDetailLink(card *Card) *detailLink {
+	links := m.getDetailLinks(card)
+	if m.DetailLinkIndex < 0 || m.DetailLinkIndex >= len(links) {
+		return nil
+	}
+	return &links[m.DetailLinkIndex]
+}
+
+// cycleDetailLink moves link focus forward (delta=1) or backward (delta=-1)
+func (m *Model) cycleDetailLink(card *Card, delta int) {
+	count := len(m.getDetailLinks(card))
+	if count == 0 {
+		m.DetailLinkIndex = -1
+		return
+	}
+	if m.DetailLinkIndex < 0 && delta < 0 {
+		m.DetailLinkIndex = count - 1
+		return
+	}
+	m.DetailLinkIndex = (m.DetailLinkIndex + delta + count) % count
+}
+
+// followDetailLink opens the focused link's card, remembering where we came from
+func (m *Model) followDetailLink(card *Card) tea.Cmd {
+	link := m.getFocusedDetailLink(card)
+	if link == nil {
+		return nil
+	}
+	if link.CardID == "" {
+		m.ReloadMessage = fmt.Sprintf("⚠ No card matches [[%s]]", link.Label)
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+
+	from := card.ID
+	cmd, ok := m.openCardByID(link.CardID)
+	if ok {
+		m.DetailBackStack = append(m.DetailBackStack, from)
+	}
+	return cmd
+}
+
+// followBackLink returns to the card we followed a link from
+func (m *Model) followBackLink() tea.Cmd {
+	if len(m.DetailBackStack) == 0 {
+		return nil
+	}
+	previous := m.DetailBackStack[len(m.DetailBackStack)-1]
+	m.DetailBackStack = m.DetailBackStack[:len(m.DetailBackStack)-1]
+	cmd, _ := m.openCardByID(previous)
+	return cmd
+}
+
+// renameCard asks for a new title, then offers to rewrite [[links]] to the old title
+func (m *Model) renameCard(card *Card) {
+	if card == nil {
+		return
+	}
+	original := *card
+
+	m.askPrompt("Rename card:", original.Title, func(m *Model, value string) tea.Cmd {
+		newTitle := strings.TrimSpace(value)
+		if newTitle == "" || newTitle == original.Title {
+			return nil
+		}
+
+		renamed := original
+		renamed.Title = newTitle
+		renamed.UpdatedAt = time.Now().UnixMilli()
+		cmd := m.execute(updateCardCommand{verb: "rename", before: original, after: renamed},
+			fmt.Sprintf("✎ Renamed '%s' to '%s'", original.Title, newTitle))
+		if cmd == nil {
+			return nil
+		}
+
+		// Find incoming title links (ID links keep working)
+		var relinks []libraryCommand
+		total := 0
+		for _, other := range m.Data.Cards {
+			content, count := rewriteTitleLinks(other.Content, original.Title, newTitle)
+			if count == 0 {
+				continue
+			}
+			updated := other
+			updated.Content = content
+			updated.UpdatedAt = renamed.UpdatedAt
+			relinks = append(relinks, updateCardCommand{verb: "relink", before: other, after: updated})
+			total += count
+		}
+		if len(relinks) > 0 {
+			m.askConfirm(fmt.Sprintf("Rewrite %d link(s) in %d card(s) to [[%s]]?", total, len(relinks), newTitle),
+				func(m *Model) tea.Cmd {
+					cmd := m.execute(batchCommand{label: fmt.Sprintf("relink %d card(s)", len(relinks)), commands: relinks},
+						fmt.Sprintf("🔗 Rewrote %d link(s) to [[%s]]", total, newTitle))
+					// The dialog is modal, so the rename is the entry below - undo both at once
+					if cmd != nil {
+						m.mergeLastCommands(2, fmt.Sprintf("rename '%s'", original.Title))
+					}
+					return cmd
+				})
+		}
+		return cmd
+	})
+}
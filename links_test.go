@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+func TestParseCardLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"title link", "first run [[Docker Login]]", []string{"Docker Login"}},
+		{"id link", "see [[id:abc123]]", []string{"id:abc123"}},
+		{"duplicates once", "[[A]] then [[B]] then [[A]]", []string{"A", "B"}},
+		{"shell test is not a link", "if [[ -f file ]]; then", nil},
+		{"empty brackets", "[[]] and [[ ]]", nil},
+		{"single char", "[[x]]", []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCardLinks(tt.content); !equalStrings(got, tt.want) {
+				t.Errorf("parseCardLinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveLinkAndBacklinks(t *testing.T) {
+	cards := []Card{
+		{ID: "login", Title: "Docker Login"},
+		{ID: "deploy", Title: "Deploy", Content: "first run [[docker login]], then [[Missing]]"},
+		{ID: "rollback", Title: "Rollback", Content: "same as [[id:deploy]] but older tag; see [[id:login]]"},
+		{ID: "self", Title: "Self", Content: "[[Self]]"},
+	}
+
+	if card := resolveLink(cards, "DOCKER LOGIN"); card == nil || card.ID != "login" {
+		t.Errorf("resolveLink(title) = %+v, want login (case-insensitive)", card)
+	}
+	if card := resolveLink(cards, "id:deploy"); card == nil || card.ID != "deploy" {
+		t.Errorf("resolveLink(id) = %+v, want deploy", card)
+	}
+	if card := resolveLink(cards, "Missing"); card != nil {
+		t.Errorf("resolveLink(broken) = %+v, want nil", card)
+	}
+
+	if ids := cardIDs(findBacklinks(cards, "login")); !equalStrings(ids, []string{"deploy", "rollback"}) {
+		t.Errorf("findBacklinks(login) = %v, want [deploy rollback]", ids)
+	}
+	if ids := cardIDs(findBacklinks(cards, "self")); len(ids) != 0 {
+		t.Errorf("findBacklinks(self) = %v, want none (self-links excluded)", ids)
+	}
+}
+
+func TestRewriteTitleLinks(t *testing.T) {
+	content := "run [[Docker Login]] or [[docker login]], not [[Docker Logout]] or [[id:login]]"
+
+	got, count := rewriteTitleLinks(content, "Docker Login", "Registry Login")
+	want := "run [[Registry Login]] or [[Registry Login]], not [[Docker Logout]] or [[id:login]]"
+	if got != want || count != 2 {
+		t.Errorf("rewriteTitleLinks() = %q, %d; want %q, 2", got, count, want)
+	}
+}
+
+func TestHighlightLinksKeepsShellTests(t *testing.T) {
+	content := "[[ -f x ]] && echo"
+	if got := highlightLinks(content, nil, ""); got != content {
+		t.Errorf("highlightLinks() changed non-link brackets: %q", got)
+	}
+}
+
+func TestRenameWithLinksUndoesOnce(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{
+		{ID: "a", Title: "Docker Login", Content: "docker login"},
+		{ID: "b", Title: "Deploy", Content: "first run [[Docker Login]]"},
+	}})
+
+	m.renameCard(&m.Data.Cards[0])
+	m.Prompt.OnSubmit(&m, "Registry Login")
+	if m.Confirm == nil {
+		t.Fatal("rename didn't offer to rewrite links")
+	}
+	m.Confirm.OnConfirm(&m)
+	if m.Data.Cards[1].Content != "first run [[Registry Login]]" || len(m.UndoStack) != 1 {
+		t.Fatalf("after rename: link %q, %d undo entries; want rewritten and 1", m.Data.Cards[1].Content, len(m.UndoStack))
+	}
+
+	m.undo()
+	if m.Data.Cards[0].Title != "Docker Login" || m.Data.Cards[1].Content != "first run [[Docker Login]]" {
+		t.Errorf("after one undo: title %q, link %q; want both back", m.Data.Cards[0].Title, m.Data.Cards[1].Content)
+	}
+}
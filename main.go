@@ -12,6 +12,11 @@ import (
 // Rule: Never add business logic to this file. Keep it minimal.
 
 func main() {
+	// Subcommands (cellblocks-tui fill ...) run without the TUI - see cli.go
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	// Create program with options
 	opts := []tea.ProgramOption{
 		tea.WithAltScreen(),       // Use alternate screen buffer
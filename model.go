@@ -11,7 +11,39 @@ import (
 
 // initialModel creates the initial application state
 func initialModel() Model {
+	// Local settings are tiny - load synchronously so they apply from the first frame
+	config, configErr := LoadConfig(DefaultConfigPath)
+	usage, usageErr := LoadUsage(DefaultUsagePath)
+	varHistory, varHistoryErr := LoadVarHistory(DefaultVarHistoryPath)
+
+	m := newModel(config, usage, varHistory)
+
+	// A broken config shouldn't block the app - fall back to defaults and say so
+	if configErr != nil {
+		m.ReloadMessage = "⚠ " + configErr.Error() + " (using defaults)"
+		m.ReloadMessageTime = time.Now()
+	}
+	if usageErr != nil {
+		m.ReloadMessage = "⚠ " + usageErr.Error() + " (usage stats reset)"
+		m.ReloadMessageTime = time.Now()
+	}
+	if varHistoryErr != nil {
+		m.ReloadMessage = "⚠ " + varHistoryErr.Error() + " (variable history reset)"
+		m.ReloadMessageTime = time.Now()
+	}
+	if config.ActiveProfile != "" && config.profileIndex(config.ActiveProfile) < 0 {
+		m.ReloadMessage = "⚠ activeProfile \"" + config.ActiveProfile + "\" is not in profiles"
+		m.ReloadMessageTime = time.Now()
+		m.ActiveProfile = ""
+	}
+
+	return m
+}
+
+// newModel creates the initial state from already loaded settings (no file access)
+func newModel(config Config, usage *UsageData, varHistory *VarHistoryData) Model {
 	return Model{
+		Config:              config,
 		Data:                nil, // Will be loaded asynchronously
 		FilteredCards:       []Card{},
 		CategoryMap:         make(map[string]Category),
@@ -20,14 +52,22 @@ func initialModel() Model {
 		PreviewScrollOffset: 0,
 		ScrollOffset:        0,
 		SelectedCategories:  make(map[string]bool),
+		MarkedCards:         make(map[string]bool),
 		ViewMode:            ViewList,
 		ShowPreview:         false,          // Start with preview off for cleaner initial layout
 		ShowHelp:            false,
 		UseMarkdownRender:   true,           // Enable markdown by default (cards have markdown)
 		DetailScrollOffset:  0,
+		PinFavorites:        config.PinFavorites,
+		Usage:               usage,
+		VarHistory:          varHistory,
+		ListSort:            config.DefaultSort,
+		TableFields:         config.TableFields,
+		NewCardFields:       make(map[string]string),
 		SortColumn:          "title",        // Default sort by title
 		SortDirection:       "asc",          // Ascending by default
 		TemplateVars:        make(map[string]string),
+		ActiveProfile:       config.ActiveProfile,
 		DetectedVars:        []string{},
 		TemplateFormField:   0,
 		ShowTemplateForm:    false,
@@ -64,6 +104,12 @@ func (m *Model) updateFilteredCards() {
 		return
 	}
 
+	// Remember the selected card so selection survives re-filtering and re-sorting
+	selectedID := ""
+	if card := m.getSelectedCard(); card != nil {
+		selectedID = card.ID
+	}
+
 	cards := m.Data.Cards
 
 	// Apply category filter
@@ -71,8 +117,45 @@ func (m *Model) updateFilteredCards() {
 		cards = filterByCategories(cards, m.SelectedCategories)
 	}
 
+	// Apply favorites filter (virtual "★ Favorites" category)
+	if m.FavoritesOnly {
+		cards = filterFavorites(cards)
+	}
+
+	// Apply search (results come back ranked by relevance, frecency breaks ties)
+	if m.SearchQuery != "" {
+		cards = searchCards(cards, m.SearchQuery, m.CategoryMap, m.frecencyOf)
+	}
+
+	switch {
+	case m.ViewMode == ViewTable:
+		// Table view shows cards in column sort order (sortCards returns a copy)
+		cards = sortCards(cards, m.CategoryMap, m.SortColumn, m.SortDirection)
+	case m.SearchQuery != "":
+		// Keep search ranking
+	case m.ListSort == "frecency":
+		cards = sortByFrecency(cards, m.Usage, time.Now())
+	case m.ListSort == "manual":
+		cards = sortManual(cards, m.Data.Categories)
+	}
+
+	// Pinned favorites go first in list/grid regardless of order
+	if m.isPinningFavorites() {
+		cards = pinFavorites(cards)
+	}
+
 	m.FilteredCards = cards
 
+	// Keep the same card selected if it's still visible
+	if index := findCardIndex(m.FilteredCards, selectedID); index >= 0 && index != m.SelectedIndex {
+		m.SelectedIndex = index
+		if m.ViewMode == ViewGrid {
+			m.ensureGridSelectionVisible()
+		} else {
+			m.ensureListSelectionVisible()
+		}
+	}
+
 	// Adjust selected index if out of bounds
 	if m.SelectedIndex >= len(m.FilteredCards) {
 		m.SelectedIndex = max(0, len(m.FilteredCards)-1)
@@ -87,6 +170,99 @@ func (m *Model) getSelectedCard() *Card {
 	return &m.FilteredCards[m.SelectedIndex]
 }
 
+// openDetailView shows the selected card full-screen with template detection
+func (m *Model) openDetailView() tea.Cmd {
+	card := m.getSelectedCard()
+	if card == nil {
+		return nil
+	}
+
+	m.ViewMode = ViewDetail
+	m.DetailScrollOffset = 0
+	m.ShowPreview = false // Disable preview pane when entering detail view
+	// Clear preview cache to free memory
+	m.CachedPreviewContent = ""
+	m.CachedPreviewWidth = 0
+	m.PreviewRenderPending = false
+	// Drop any cached render of a previously opened card
+	m.CachedDetailContent = ""
+	m.CachedDetailWidth = 0
+	// Detect template variables (including those of {{> included}} cards)
+	content := m.expandCard(card).Content
+	m.DetectedDecls = ExtractTemplateVars(content)
+	m.DetectedVars = ExtractVariables(content)
+	// Initialize template vars (built-ins are stored here too)
+	if m.TemplateVars == nil {
+		m.TemplateVars = make(map[string]string)
+	}
+	// Resolve {{@built-ins}} (async)
+	builtinsCmd := m.refreshBuiltinVars(card)
+	// Auto-show template form if variables detected
+	m.ShowTemplateForm = len(m.DetectedVars) > 0
+	m.TemplateFormField = 0
+	// Start from the active profile's values, then the values used last time
+	m.prefillProfileVars()
+	m.prefillTemplateVars(card)
+	m.VarHistoryIndex = -1
+	m.TextInputs = nil
+	// Populate detail cache (async) - the form's state decides what's shown
+	cmd := tea.Batch(m.populateDetailCacheAsync(), builtinsCmd)
+	// No link focused until Tab
+	m.DetailLinkIndex = -1
+	return cmd
+}
+
+// filterState is the filtering of the card list (categories, favorites, search)
+type filterState struct {
+	categories    map[string]bool
+	favoritesOnly bool
+	searchQuery   string
+}
+
+// openCardByID selects a card and opens it in detail view
+// Filters and search that hide the card are set aside until the detail view is
+// left (see restoreFilters); returns false if the card doesn't exist
+func (m *Model) openCardByID(cardID string) (tea.Cmd, bool) {
+	if findCardIndex(m.FilteredCards, cardID) < 0 {
+		// Hopping through several hidden cards keeps the filters the user set
+		if m.SetAsideFilters == nil {
+			m.SetAsideFilters = &filterState{
+				categories:    m.SelectedCategories,
+				favoritesOnly: m.FavoritesOnly,
+				searchQuery:   m.SearchQuery,
+			}
+		}
+		m.SelectedCategories = make(map[string]bool)
+		m.FavoritesOnly = false
+		m.SearchQuery = ""
+		m.updateFilteredCards()
+	}
+
+	index := findCardIndex(m.FilteredCards, cardID)
+	if index < 0 {
+		m.restoreFilters()
+		return nil, false
+	}
+
+	m.SelectedIndex = index
+	m.PreviewedIndex = index
+	m.ensureListSelectionVisible()
+	return m.openDetailView(), true
+}
+
+// restoreFilters puts back filters set aside by openCardByID
+func (m *Model) restoreFilters() {
+	if m.SetAsideFilters == nil {
+		return
+	}
+	filters := m.SetAsideFilters
+	m.SetAsideFilters = nil
+	m.SelectedCategories = filters.categories
+	m.FavoritesOnly = filters.favoritesOnly
+	m.SearchQuery = filters.searchQuery
+	m.updateFilteredCards()
+}
+
 // getPreviewedCard returns the card shown in preview pane, or nil if none
 func (m *Model) getPreviewedCard() *Card {
 	if len(m.FilteredCards) == 0 || m.PreviewedIndex < 0 || m.PreviewedIndex >= len(m.FilteredCards) {
@@ -117,6 +293,11 @@ func (m *Model) getVisibleCardCount() int {
 		availableHeight = availableHeight / 2
 	}
 
+	// The pinned-favorites separator takes one line in list view
+	if m.ViewMode == ViewList && m.pinnedBoundary() >= 0 {
+		availableHeight--
+	}
+
 	return max(1, availableHeight)
 }
 
@@ -241,6 +422,7 @@ func (m *Model) toggleCategory(categoryID string) {
 // clearFilters resets all filters
 func (m *Model) clearFilters() {
 	m.SelectedCategories = make(map[string]bool)
+	m.FavoritesOnly = false
 	m.updateFilteredCards()
 }
 
@@ -255,16 +437,9 @@ func max(a, b int) int {
 // populatePreviewCacheAsync renders markdown for the preview pane asynchronously
 // Returns a tea.Cmd that will render in a goroutine and send a message when complete
 func (m *Model) populatePreviewCacheAsync() tea.Cmd {
-	if !m.UseMarkdownRender {
-		// Clear cache when not using markdown
-		m.CachedPreviewContent = ""
-		m.CachedPreviewWidth = 0
-		m.PreviewRenderPending = false
-		return nil
-	}
-
 	card := m.getPreviewedCard()
-	if card == nil {
+	if card == nil || !m.needsRender(card) {
+		// Clear cache when there's nothing to render (plain text)
 		m.CachedPreviewContent = ""
 		m.CachedPreviewWidth = 0
 		m.PreviewRenderPending = false
@@ -286,11 +461,13 @@ func (m *Model) populatePreviewCacheAsync() tea.Cmd {
 
 	// Capture values for goroutine
 	content := card.Content
+	kind, language := m.contentTypeOf(card)
+	theme := m.Config.CodeTheme
 	index := m.PreviewedIndex
 
 	// Return async command
 	return func() tea.Msg {
-		rendered := renderMarkdown(content, contentWidth)
+		rendered := renderCardContent(content, kind, language, theme, contentWidth)
 		return previewRenderCompleteMsg{
 			content: rendered,
 			width:   contentWidth,
@@ -302,16 +479,9 @@ func (m *Model) populatePreviewCacheAsync() tea.Cmd {
 // populateDetailCacheAsync renders markdown for the detail view asynchronously
 // Returns a tea.Cmd that will render in a goroutine and send a message when complete
 func (m *Model) populateDetailCacheAsync() tea.Cmd {
-	if !m.UseMarkdownRender {
-		// Clear cache when not using markdown
-		m.CachedDetailContent = ""
-		m.CachedDetailWidth = 0
-		m.DetailRenderPending = false
-		return nil
-	}
-
 	card := m.getSelectedCard()
-	if card == nil {
+	if card == nil || !m.needsRender(card) {
+		// Clear cache when there's nothing to render (plain text)
 		m.CachedDetailContent = ""
 		m.CachedDetailWidth = 0
 		m.DetailRenderPending = false
@@ -321,54 +491,91 @@ func (m *Model) populateDetailCacheAsync() tea.Cmd {
 	// Calculate available width (matches renderDetailView)
 	contentWidth := m.Width - 8
 
-	// Only render if width changed or cache is empty
-	if m.CachedDetailContent != "" && m.CachedDetailWidth == contentWidth {
+	// Shown content: the card, or its filled template while the form is open
+	content := m.detailSource(card)
+
+	// Only render if width or content changed or cache is empty
+	if m.CachedDetailContent != "" && m.CachedDetailWidth == contentWidth && m.DetailRenderSource == content {
 		m.DetailRenderPending = false
 		return nil // Cache is still valid
 	}
 
 	// Mark render as pending
 	m.DetailRenderPending = true
+	m.DetailRenderSource = content
 
 	// Capture values for goroutine
-	content := card.Content
+	kind, language := m.contentTypeOf(card)
+	theme := m.Config.CodeTheme
 
 	// Return async command
 	return func() tea.Msg {
-		rendered := renderMarkdown(content, contentWidth)
+		rendered := renderCardContent(content, kind, language, theme, contentWidth)
 		return detailRenderCompleteMsg{
 			content: rendered,
 			width:   contentWidth,
+			source:  content,
 		}
 	}
 }
 
-// saveNewCard creates a new card and saves it to disk
+// saveNewCard creates a new card and saves it to disk (undoable)
 func (m *Model) saveNewCard() tea.Cmd {
-	// Validate input
+	// Validate input (the form shows the required-fields hint)
 	if m.NewCardTitle == "" || m.NewCardContent == "" {
-		return nil // TODO: Show error message
+		return nil
+	}
+	fields, err := buildCardFields(nil, m.getCategoryFields(m.NewCardCategoryID), m.NewCardFields)
+	if err != nil {
+		m.CreateFormError = err.Error()
+		return nil
 	}
 
 	// Create the new card
+	newCard := Card{
+		ID:         generateCardID(),
+		Title:      m.NewCardTitle,
+		Content:    m.NewCardContent,
+		CategoryID: m.NewCardCategoryID,
+		CreatedAt:  time.Now().UnixMilli(),
+		UpdatedAt:  time.Now().UnixMilli(),
+		Fields:     fields,
+	}
+
+	// Add to data through the undo history
+	if !m.applyCommand(createCardCommand{card: newCard}) {
+		return nil
+	}
+
+	// Save to disk (marshal now so the goroutine doesn't race with later changes)
+	content, err := MarshalData(m.Data)
+	ticket := dataSaves.ticket()
 	return func() tea.Msg {
-		newCard := Card{
-			ID:         generateCardID(),
-			Title:      m.NewCardTitle,
-			Content:    m.NewCardContent,
-			CategoryID: m.NewCardCategoryID,
-			CreatedAt:  time.Now().UnixMilli(),
-			UpdatedAt:  time.Now().UnixMilli(),
+		if err == nil {
+			err = dataSaves.save(ticket, func() error { return WriteDataFile(DefaultDataPath, content) })
 		}
-
-		// Add to data
-		m.Data.Cards = append(m.Data.Cards, newCard)
-
-		// Save to disk
-		if err := SaveData(DefaultDataPath, m.Data); err != nil {
+		if err != nil {
 			return cardSaveErrorMsg{err: err}
 		}
 
 		return cardSavedMsg{card: &newCard}
 	}
 }
+
+// saveDataAsync writes the whole library to disk in the background
+// The message is shown as a notification once the save completes
+func (m *Model) saveDataAsync(message string) tea.Cmd {
+	// Marshal now so the goroutine doesn't race with later changes; the ticket keeps
+	// saves in order when several changes are saved at once
+	content, err := MarshalData(m.Data)
+	ticket := dataSaves.ticket()
+	return func() tea.Msg {
+		if err == nil {
+			err = dataSaves.save(ticket, func() error { return WriteDataFile(DefaultDataPath, content) })
+		}
+		if err != nil {
+			return cardSaveErrorMsg{err: err}
+		}
+		return dataSavedMsg{message: message}
+	}
+}
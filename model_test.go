@@ -0,0 +1,12 @@
+package main
+
+// testModel returns a model showing data with default settings and no usage or
+// variable history. It never reads the local config or sidecar files, so tests
+// behave the same on every machine.
+func testModel(data *CellBlocksData) Model {
+	m := newModel(defaultConfig(), nil, nil)
+	m.Data = data
+	m.buildCategoryMap()
+	m.updateFilteredCards()
+	return m
+}
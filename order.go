@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// order.go - Manual Card Ordering
+// Purpose: Curated per-category order ("manual" sort, alt+↑/↓ and drag in list view)
+//
+// Card.Order is 1-based within a category; 0 means the card hasn't been placed yet.
+// Unplaced cards follow placed ones in library order, so the sort stays stable
+// until someone starts curating a category.
+
+// sortManual orders cards by category (in library category order), then by Order
+func sortManual(cards []Card, categories []Category) []Card {
+	sorted := make([]Card, len(cards))
+	copy(sorted, cards)
+
+	categoryRank := make(map[string]int, len(categories))
+	for i, cat := range categories {
+		categoryRank[cat.ID] = i
+	}
+	rank := func(card Card) int {
+		if r, ok := categoryRank[card.CategoryID]; ok {
+			return r
+		}
+		// Unknown categories go last
+		return len(categories)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if (a.Order == 0) != (b.Order == 0) {
+			return a.Order != 0
+		}
+		return a.Order < b.Order
+	})
+
+	return sorted
+}
+
+// categoryManualOrder returns a category's cards in manual order
+func categoryManualOrder(cards []Card, categoryID string) []Card {
+	var inCategory []Card
+	for _, card := range cards {
+		if card.CategoryID == categoryID {
+			inCategory = append(inCategory, card)
+		}
+	}
+	return sortManual(inCategory, nil)
+}
+
+// reorderCommands moves a card to another card's position within their category
+// and renumbers the category 1..n. The moved card's command comes first so undo
+// reselects it. Returns nil if the cards aren't in the same category.
+func reorderCommands(cards []Card, cardID, targetID string) []libraryCommand {
+	from := findCardIndex(cards, cardID)
+	to := findCardIndex(cards, targetID)
+	if from < 0 || to < 0 || cardID == targetID || cards[from].CategoryID != cards[to].CategoryID {
+		return nil
+	}
+
+	ordered := categoryManualOrder(cards, cards[from].CategoryID)
+	fromPos := findCardIndex(ordered, cardID)
+	toPos := findCardIndex(ordered, targetID)
+
+	moved := ordered[fromPos]
+	ordered = append(ordered[:fromPos], ordered[fromPos+1:]...)
+	ordered = append(ordered[:toPos], append([]Card{moved}, ordered[toPos:]...)...)
+
+	var commands []libraryCommand
+	for i, card := range ordered {
+		if card.Order == i+1 {
+			continue
+		}
+		updated := card
+		updated.Order = i + 1
+		cmd := updateCardCommand{verb: "reorder", before: card, after: updated}
+		if card.ID == cardID {
+			commands = append([]libraryCommand{cmd}, commands...)
+		} else {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// moveCardInOrder moves the selected card up (delta=-1) or down (delta=1) in manual order
+// The first press switches to manual sort so the move is visible
+func (m *Model) moveCardInOrder(delta int) tea.Cmd {
+	if m.ViewMode != ViewList && m.ViewMode != ViewGrid {
+		return nil
+	}
+	if m.ListSort != "manual" {
+		m.ListSort = "manual"
+		m.updateFilteredCards()
+		m.ReloadMessage = "↕ Sorted by manual order (alt+↑/↓ to move cards)"
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+
+	card := m.getSelectedCard()
+	neighbor := m.SelectedIndex + delta
+	if card == nil || neighbor < 0 || neighbor >= len(m.FilteredCards) {
+		return nil
+	}
+
+	direction := "down"
+	if delta < 0 {
+		direction = "up"
+	}
+	return m.reorderCard(card.ID, m.FilteredCards[neighbor].ID, fmt.Sprintf("↕ Moved '%s' %s", card.Title, direction))
+}
+
+// dropCard places a dragged card at the position of the card it was dropped on
+func (m *Model) dropCard(cardID, targetID string) tea.Cmd {
+	index := findCardIndex(m.Data.Cards, cardID)
+	if index < 0 {
+		return nil
+	}
+	title := m.Data.Cards[index].Title
+
+	// Show the result in manual order
+	m.ListSort = "manual"
+	return m.reorderCard(cardID, targetID, fmt.Sprintf("↕ Moved '%s'", title))
+}
+
+// reorderCard applies a reorder as one undo step and saves
+func (m *Model) reorderCard(cardID, targetID, message string) tea.Cmd {
+	if m.Data == nil {
+		return nil
+	}
+
+	a, b := findCardIndex(m.Data.Cards, cardID), findCardIndex(m.Data.Cards, targetID)
+	if a >= 0 && b >= 0 && m.Data.Cards[a].CategoryID != m.Data.Cards[b].CategoryID {
+		m.ReloadMessage = "⚠ Manual order is per category - use M to move between categories"
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+
+	commands := reorderCommands(m.Data.Cards, cardID, targetID)
+	if len(commands) == 0 {
+		m.updateFilteredCards()
+		return nil
+	}
+
+	cmd := m.execute(batchCommand{label: fmt.Sprintf("reorder '%s'", m.Data.Cards[a].Title), commands: commands}, message)
+	m.selectCardByID(cardID)
+	return cmd
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestSortManual(t *testing.T) {
+	categories := []Category{{ID: "git"}, {ID: "docker"}}
+	cards := []Card{
+		{ID: "d1", CategoryID: "docker"},
+		{ID: "g1", CategoryID: "git"},
+		{ID: "d2", CategoryID: "docker", Order: 2},
+		{ID: "d3", CategoryID: "docker", Order: 1},
+		{ID: "x1", CategoryID: "gone"},
+		{ID: "g2", CategoryID: "git"},
+	}
+
+	got := cardIDs(sortManual(cards, categories))
+	want := []string{"g1", "g2", "d3", "d2", "d1", "x1"}
+	if !equalStrings(got, want) {
+		t.Errorf("sortManual() = %v, want %v", got, want)
+	}
+}
+
+func TestReorderCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		cardID string
+		target string
+		want   []string
+	}{
+		{"move down one", "a", "b", []string{"b", "a", "c", "d"}},
+		{"move up one", "c", "b", []string{"a", "c", "b", "d"}},
+		{"drag to top", "d", "a", []string{"d", "a", "b", "c"}},
+		{"drag to bottom", "a", "d", []string{"b", "c", "d", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &CellBlocksData{Cards: []Card{
+				{ID: "a", CategoryID: "git"},
+				{ID: "other", CategoryID: "docker"},
+				{ID: "b", CategoryID: "git"},
+				{ID: "c", CategoryID: "git", Order: 0},
+				{ID: "d", CategoryID: "git"},
+			}}
+
+			commands := reorderCommands(data.Cards, tt.cardID, tt.target)
+			if len(commands) == 0 {
+				t.Fatal("reorderCommands() returned no commands")
+			}
+			if commands[0].cardID() != tt.cardID {
+				t.Errorf("first command is for %q, want moved card %q", commands[0].cardID(), tt.cardID)
+			}
+
+			batch := batchCommand{label: "reorder", commands: commands}
+			batch.apply(data)
+			if got := cardIDs(categoryManualOrder(data.Cards, "git")); !equalStrings(got, tt.want) {
+				t.Errorf("order after move = %v, want %v", got, tt.want)
+			}
+
+			// Undo restores the unplaced state
+			batch.invert().apply(data)
+			for _, card := range data.Cards {
+				if card.Order != 0 {
+					t.Errorf("after undo card %q has order %d, want 0", card.ID, card.Order)
+				}
+			}
+		})
+	}
+}
+
+func TestReorderCommandsAcrossCategories(t *testing.T) {
+	cards := []Card{{ID: "a", CategoryID: "git"}, {ID: "b", CategoryID: "docker"}}
+	if commands := reorderCommands(cards, "a", "b"); commands != nil {
+		t.Errorf("reorderCommands() across categories = %v, want nil", commands)
+	}
+}
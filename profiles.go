@@ -0,0 +1,140 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// profiles.go - Variable Profiles
+// Purpose: Named sets of {{variable}} values (dev, staging, prod) that fill template forms
+//
+// Profiles live in config.json:
+//
+//	"profiles": [
+//	  {"name": "dev",  "vars": {"host": "dev.internal", "namespace": "dev"}},
+//	  {"name": "prod", "vars": {"host": "prod.internal", "namespace": "prod"}, "warn": true}
+//	],
+//	"activeProfile": "dev"
+//
+// The active profile is switched with Ctrl+O (or a click on its status bar badge) and is
+// shown in the status bar and template form - in red for "warn" profiles.
+
+// VarProfile is a named set of template variable values
+type VarProfile struct {
+	Name string            `json:"name"`
+	Vars map[string]string `json:"vars"` // Variable name -> value
+	Warn bool              `json:"warn"` // Highlight in red while active (e.g. production)
+}
+
+// profileIndex returns the index of the named profile, or -1
+func (c Config) profileIndex(name string) int {
+	for i, p := range c.Profiles {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// activeProfile returns the profile filling template forms (nil = none)
+func (m *Model) activeProfile() *VarProfile {
+	if i := m.Config.profileIndex(m.ActiveProfile); i >= 0 && m.ActiveProfile != "" {
+		return &m.Config.Profiles[i]
+	}
+	return nil
+}
+
+// prefillProfileVars sets the form fields the active profile has values for
+// Runs before prefillTemplateVars so the profile beats remembered values
+func (m *Model) prefillProfileVars() {
+	profile := m.activeProfile()
+	if profile == nil {
+		return
+	}
+	for _, decl := range m.DetectedDecls {
+		if value, ok := profile.Vars[decl.Name]; ok {
+			m.TemplateVars[decl.Name] = value
+		}
+	}
+}
+
+// switchProfileVars replaces the values of the old profile with those of the new one:
+// the new profile's values always win, and values left over from the old profile are
+// cleared so a prod host can't linger after switching to dev
+func (m *Model) switchProfileVars(old, next *VarProfile) {
+	if old != nil {
+		for name, value := range old.Vars {
+			if m.TemplateVars[name] == value {
+				m.TemplateVars[name] = ""
+			}
+		}
+	}
+	if next != nil {
+		for name, value := range next.Vars {
+			m.TemplateVars[name] = value
+		}
+	}
+}
+
+// cycleProfile switches to the next profile (after the last one: no profile)
+func (m *Model) cycleProfile() tea.Cmd {
+	m.ReloadMessageTime = time.Now()
+	if len(m.Config.Profiles) == 0 {
+		m.ReloadMessage = "No variable profiles - add \"profiles\" to config.json"
+		return nil
+	}
+
+	old := m.activeProfile()
+	next := 0
+	if old != nil {
+		next = m.Config.profileIndex(old.Name) + 1
+	}
+	if next < len(m.Config.Profiles) {
+		m.ActiveProfile = m.Config.Profiles[next].Name
+		m.ReloadMessage = "⎈ Profile: " + m.ActiveProfile
+	} else {
+		m.ActiveProfile = ""
+		m.ReloadMessage = "⎈ No profile"
+	}
+	m.switchProfileVars(old, m.activeProfile())
+
+	if m.ViewMode == ViewDetail {
+		return m.scheduleDetailRender()
+	}
+	return nil
+}
+
+// profileFilled reports whether a field still holds the active profile's value
+func (m *Model) profileFilled(name string) bool {
+	profile := m.activeProfile()
+	if profile == nil {
+		return false
+	}
+	value, ok := profile.Vars[name]
+	return ok && m.TemplateVars[name] == value
+}
+
+// renderProfileBadge renders the active profile for the status bar and template form
+// ("" when no profiles are configured)
+func renderProfileBadge(m Model) string {
+	if len(m.Config.Profiles) == 0 {
+		return ""
+	}
+	profile := m.activeProfile()
+	switch {
+	case profile == nil:
+		return styleSubtle.Render("⎈ no profile")
+	case profile.Warn:
+		return styleProfileWarn.Render("⎈ " + profile.Name)
+	}
+	return styleProfile.Render("⎈ " + profile.Name)
+}
+
+// isProfileBadgeClick reports whether a click landed on the status bar's profile badge
+func (m *Model) isProfileBadgeClick(msg tea.MouseMsg) bool {
+	badge := renderProfileBadge(*m)
+	// The status bar is the last line; its content starts after one space
+	return badge != "" && msg.Y == m.Height-1 && msg.X >= 1 && msg.X <= lipgloss.Width(badge)
+}
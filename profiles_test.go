@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// profileTestModel returns a model with dev/prod profiles and an open template card
+func profileTestModel(active string) Model {
+	m := testModel(&CellBlocksData{Cards: []Card{{ID: "a", Title: "Deploy", Content: "kubectl -n {{namespace}} --server {{host}} # {{region}}"}}})
+	m.Config.Profiles = []VarProfile{
+		{Name: "dev", Vars: map[string]string{"host": "dev.internal", "namespace": "dev"}},
+		{Name: "prod", Vars: map[string]string{"host": "prod.internal"}, Warn: true},
+	}
+	m.ActiveProfile = active
+	m.VarHistory = newVarHistoryData()
+	m.VarHistory.record("a", "host", "remembered.internal")
+	m.VarHistory.record("a", "region", "eu-west-1")
+	m.openDetailView()
+	return m
+}
+
+func TestProfilePrefill(t *testing.T) {
+	tests := []struct {
+		name   string
+		active string
+		want   map[string]string
+	}{
+		{name: "no profile uses history", active: "", want: map[string]string{"host": "remembered.internal", "namespace": "", "region": "eu-west-1"}},
+		{name: "profile beats history", active: "dev", want: map[string]string{"host": "dev.internal", "namespace": "dev", "region": "eu-west-1"}},
+		{name: "partial profile", active: "prod", want: map[string]string{"host": "prod.internal", "namespace": "", "region": "eu-west-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := profileTestModel(tt.active)
+			for name, want := range tt.want {
+				if got := m.TemplateVars[name]; got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCycleProfile(t *testing.T) {
+	m := profileTestModel("")
+	m.TemplateVars["region"] = "typed"
+
+	steps := []struct {
+		active string
+		want   map[string]string
+	}{
+		{active: "dev", want: map[string]string{"host": "dev.internal", "namespace": "dev", "region": "typed"}},
+		// prod has no namespace - dev's value mustn't linger
+		{active: "prod", want: map[string]string{"host": "prod.internal", "namespace": "", "region": "typed"}},
+		{active: "", want: map[string]string{"host": "", "namespace": "", "region": "typed"}},
+		{active: "dev", want: map[string]string{"host": "dev.internal", "namespace": "dev", "region": "typed"}},
+	}
+
+	for i, step := range steps {
+		if cmd := m.cycleProfile(); cmd == nil {
+			t.Errorf("step %d: no re-render scheduled in detail view", i)
+		}
+		if m.ActiveProfile != step.active {
+			t.Fatalf("step %d: active = %q, want %q", i, m.ActiveProfile, step.active)
+		}
+		for name, want := range step.want {
+			if got := m.TemplateVars[name]; got != want {
+				t.Errorf("step %d: %s = %q, want %q", i, name, got, want)
+			}
+		}
+	}
+
+	// Values edited by hand are kept unless the next profile sets them
+	m.TemplateVars["namespace"] = "edited"
+	m.cycleProfile()
+	if m.ActiveProfile != "prod" || m.TemplateVars["namespace"] != "edited" {
+		t.Errorf("active = %q namespace = %q, want prod and the edited namespace", m.ActiveProfile, m.TemplateVars["namespace"])
+	}
+}
+
+func TestCycleProfileKeysAndClick(t *testing.T) {
+	// Ctrl+O switches even while a template field has focus
+	m := profileTestModel("")
+	model, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = model.(Model)
+	if m.ActiveProfile != "dev" {
+		t.Errorf("after Ctrl+O active = %q, want dev", m.ActiveProfile)
+	}
+
+	// Clicking the status bar badge in list view
+	m.ViewMode = ViewList
+	model, _ = m.handleLeftClick(tea.MouseMsg{X: 2, Y: m.Height - 1})
+	m = model.(Model)
+	if m.ActiveProfile != "prod" {
+		t.Errorf("after a badge click active = %q, want prod", m.ActiveProfile)
+	}
+	model, _ = m.handleLeftClick(tea.MouseMsg{X: 40, Y: m.Height - 1})
+	m = model.(Model)
+	if m.ActiveProfile != "prod" {
+		t.Errorf("a click past the badge switched to %q", m.ActiveProfile)
+	}
+
+	// Without profiles nothing changes
+	m.Config.Profiles = nil
+	m.ActiveProfile = ""
+	m.cycleProfile()
+	if m.ActiveProfile != "" || renderProfileBadge(m) != "" {
+		t.Errorf("without profiles active = %q", m.ActiveProfile)
+	}
+}
@@ -0,0 +1,671 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// runner.go - Run Command Cards
+// Purpose: Execute a shell card (press r) and show its output
+//
+// The template is filled and the final command shown for confirmation. It then runs
+// through $SHELL -c, either captured (stdout/stderr collected into a scrollable panel,
+// stdin closed) or in the terminal via tea.ExecProcess for interactive commands.
+// Secrets are resolved by the command itself, like a copy, so the model only ever
+// holds the masked command.
+
+// maxRunOutput caps the captured output; the rest is dropped and flagged
+const maxRunOutput = 1 << 20
+
+// shellLanguages are the code languages that run through the shell
+var shellLanguages = map[string]bool{
+	"": true, "bash": true, "sh": true, "zsh": true, "fish": true, "ksh": true,
+	"shell": true, "console": true, "shellsession": true,
+}
+
+// Run stages
+const (
+	runConfirm = iota
+	runRunning
+	runDone
+)
+
+// runLine is one line of captured output
+type runLine struct {
+	Text   string
+	Stderr bool
+}
+
+// RunSession is a command being confirmed, running or showing its output
+type RunSession struct {
+	CardID     string
+	Title      string
+	CategoryID string
+	Command    string // The filled command as displayed ({{secret:...}} masked)
+	Stage      int
+	Warning    string // Shown on the confirmation, e.g. a warn profile being active
+
+	Interactive bool
+	Output      []runLine
+	Truncated   bool
+	ExitCode    int
+	Err         string // Why the command couldn't start or was stopped
+	Started     time.Time
+	Duration    time.Duration
+	Scroll      int
+
+	ReturnMode ViewMode
+
+	// resolve returns the command to execute (secrets looked up) - only called off the UI goroutine
+	resolve func() (string, error)
+	cancel  context.CancelFunc
+}
+
+// runFinishedMsg is sent when a command ends (or couldn't be started)
+type runFinishedMsg struct {
+	session   *RunSession
+	output    []runLine
+	truncated bool
+	exitCode  int
+	err       error
+	duration  time.Duration
+}
+
+// runExecMsg hands a resolved interactive command back to Update for tea.ExecProcess
+type runExecMsg struct {
+	session *RunSession
+	command string
+}
+
+// runnableBlock picks what runs from a card: -1 for the whole text, otherwise the index
+// of the first shell (or unlabelled) code block. Non-shell code cards can't run, and
+// neither can multi-line notes without a code block unless they look like shell
+func (m *Model) runnableBlock(card *Card, text string) (int, error) {
+	kind, language := m.contentTypeOf(card)
+	if kind == ContentCode {
+		if !shellLanguages[strings.ToLower(language)] {
+			return 0, fmt.Errorf("'%s' is %s code - only shell commands run", card.Title, language)
+		}
+		return -1, nil
+	}
+
+	blocks := parseCodeBlocks(text)
+	if len(blocks) == 0 {
+		// Notes aren't commands: the text has to look like shell or be a single line
+		// (the detail footer asks every frame, so the card's own content uses the cached detection)
+		trimmed := strings.TrimSpace(text)
+		language := cardLanguage(card)
+		if text != card.Content {
+			language = detectLanguage(trimmed)
+		}
+		if language != "" && shellLanguages[language] || !strings.Contains(trimmed, "\n") {
+			return -1, nil
+		}
+		return 0, fmt.Errorf("'%s' doesn't look like a shell command (use a ```bash block)", card.Title)
+	}
+	for i, block := range blocks {
+		if shellLanguages[strings.ToLower(block.Lang)] {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("'%s' has no shell code block", card.Title)
+}
+
+// pickRunnable returns the part of text that runs (see runnableBlock)
+func pickRunnable(text string, block int) string {
+	if block >= 0 {
+		if blocks := parseCodeBlocks(text); block < len(blocks) {
+			return blocks[block].Code
+		}
+	}
+	return text
+}
+
+// startRun fills the card and asks before running it
+// Templates need valid values first - the form is shown with the problem otherwise
+func (m *Model) startRun(card *Card) tea.Cmd {
+	if card == nil {
+		return nil
+	}
+
+	content := m.expandCard(card).Content
+	vars := m.TemplateVars
+	if !HasTemplateVariables(content) {
+		vars = map[string]string{}
+	} else if index, err := ValidateTemplate(content, m.DetectedDecls, m.TemplateVars); err != nil {
+		if index >= 0 {
+			m.TemplateFormField = index
+			m.ShowTemplateForm = true
+		}
+		m.ReloadMessage = "⚠ Can't run yet - " + err.Error()
+		m.ReloadMessageTime = time.Now()
+		return nil
+	} else if err := m.builtinsError(); err != nil {
+		m.ReloadMessage = "⚠ Can't run yet - " + err.Error()
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+
+	display := FillTemplate(content, maskSecrets(content, vars))
+	block, err := m.runnableBlock(card, display)
+	if err != nil {
+		m.ReloadMessage = "⚠ " + err.Error()
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+	command := strings.TrimSpace(pickRunnable(display, block))
+	if command == "" {
+		m.ReloadMessage = fmt.Sprintf("⚠ '%s' has no command to run", card.Title)
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+
+	// Snapshot so later typing doesn't race with the command
+	snapshot := make(map[string]string, len(vars))
+	for name, value := range vars {
+		snapshot[name] = value
+	}
+	cfg := m.Config.Secrets
+
+	m.Run = &RunSession{
+		CardID:     card.ID,
+		Title:      card.Title,
+		CategoryID: card.CategoryID,
+		Command:    command,
+		ReturnMode: m.ViewMode,
+		resolve: func() (string, error) {
+			filled, err := fillWithSecrets(content, snapshot, cfg)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(pickRunnable(filled, block)), nil
+		},
+	}
+	if profile := m.activeProfile(); profile != nil && profile.Warn {
+		m.Run.Warning = fmt.Sprintf("Profile '%s' is active", profile.Name)
+	}
+	m.ViewMode = ViewRun
+	return nil
+}
+
+// runFromList runs the selected card from the list; templates open in detail view first
+// so their variables can be filled (Ctrl+X or r runs from there)
+func (m *Model) runFromList() tea.Cmd {
+	card := m.getSelectedCard()
+	if card == nil {
+		return nil
+	}
+	if HasTemplateVariables(m.expandCard(card).Content) {
+		cmd := m.openDetailView()
+		m.ReloadMessage = "Fill in the template, then Ctrl+X to run"
+		m.ReloadMessageTime = time.Now()
+		return cmd
+	}
+	return m.startRun(card)
+}
+
+// runShell returns the user's shell ($SHELL, falling back to sh)
+func runShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "sh"
+}
+
+// exitCodeOf returns a finished command's exit code (-1 if it never ran or was killed)
+func exitCodeOf(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+// startError returns err unless it's just a non-zero exit (which the exit code shows)
+func startError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
+
+// execute runs the session's command: captured, or handed to the terminal
+func (s *RunSession) execute(interactive bool) tea.Cmd {
+	s.Stage = runRunning
+	s.Interactive = interactive
+	s.Output, s.Truncated, s.Err, s.Scroll = nil, false, "", 0
+	s.Started = time.Now()
+	resolve := s.resolve
+
+	if interactive {
+		return func() tea.Msg {
+			command, err := resolve()
+			if err != nil {
+				return runFinishedMsg{session: s, exitCode: -1, err: err}
+			}
+			return runExecMsg{session: s, command: command}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	return func() tea.Msg {
+		defer cancel()
+		command, err := resolve()
+		if err != nil {
+			return runFinishedMsg{session: s, exitCode: -1, err: err}
+		}
+
+		output := &runOutput{}
+		cmd := exec.CommandContext(ctx, runShell(), "-c", command)
+		cmd.Stdout = output.stream(false)
+		cmd.Stderr = output.stream(true)
+		cmd.WaitDelay = time.Second // Don't wait on pipes held open by leftover children
+		started := time.Now()
+		err = cmd.Run()
+		if ctx.Err() != nil {
+			err = errors.New("stopped")
+		}
+		lines, truncated := output.finish()
+		return runFinishedMsg{session: s, output: lines, truncated: truncated, exitCode: exitCodeOf(err), err: startError(err), duration: time.Since(started)}
+	}
+}
+
+// execInteractive runs a resolved command in the terminal (the TUI is suspended meanwhile)
+func execInteractive(msg runExecMsg) tea.Cmd {
+	s, started := msg.session, time.Now()
+	cmd := exec.Command(runShell(), "-c", msg.command)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return runFinishedMsg{session: s, exitCode: exitCodeOf(err), err: startError(err), duration: time.Since(started)}
+	})
+}
+
+// finishRun stores a command's result if its session is still open
+func (m *Model) finishRun(msg runFinishedMsg) {
+	s := m.Run
+	if s == nil || s != msg.session {
+		return
+	}
+	s.Stage = runDone
+	s.Output, s.Truncated = msg.output, msg.truncated
+	s.ExitCode, s.Duration = msg.exitCode, msg.duration
+	s.cancel = nil
+	if msg.err != nil {
+		s.Err = msg.err.Error()
+	}
+}
+
+// handleRunInput processes keys on the run screen
+func (m Model) handleRunInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.Run
+
+	switch s.Stage {
+	case runConfirm:
+		switch msg.String() {
+		case "y", "enter":
+			return m, tea.Batch(s.execute(false), m.recordRunUse())
+		case "i":
+			return m, tea.Batch(s.execute(true), m.recordRunUse())
+		case "n", "esc", "q":
+			return m, m.closeRun()
+		case "ctrl+c":
+			return m, tea.Quit
+		}
+
+	case runRunning:
+		// Esc/Ctrl+C stop a captured command (interactive ones own the terminal)
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			if s.cancel != nil {
+				s.cancel()
+			}
+		}
+
+	case runDone:
+		page := s.outputHeight(m.Height)
+		defer func() { s.Scroll = max(0, min(s.Scroll, len(s.Output)-page)) }()
+		switch msg.String() {
+		case "up", "k":
+			s.Scroll = max(0, s.Scroll-1)
+		case "down", "j":
+			s.Scroll++
+		case "pgup":
+			s.Scroll = max(0, s.Scroll-page)
+		case "pgdown", " ":
+			s.Scroll += page
+		case "home", "g":
+			s.Scroll = 0
+		case "end", "G":
+			s.Scroll = len(s.Output)
+		case "c":
+			return m, copyCardsToClipboard(s.outputText(), nil, false)
+		case "s":
+			m.promptSaveRunOutput()
+		case "r":
+			// Run again the same way
+			return m, tea.Batch(s.execute(s.Interactive), m.recordRunUse())
+		case "esc", "q", "enter":
+			return m, m.closeRun()
+		case "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// recordRunUse counts a run like a copy (frecency) and remembers the form values
+func (m *Model) recordRunUse() tea.Cmd {
+	card := m.getSelectedCard()
+	if card == nil || card.ID != m.Run.CardID {
+		return m.recordUsage([]string{m.Run.CardID}, false)
+	}
+	filled := len(m.DetectedVars) > 0
+	return tea.Batch(m.recordUsage([]string{card.ID}, filled), m.recordVarHistory(card))
+}
+
+// closeRun leaves the run screen for the view it was opened from
+func (m *Model) closeRun() tea.Cmd {
+	s := m.Run
+	if s.cancel != nil {
+		s.cancel()
+	}
+	m.Run = nil
+	m.ViewMode = s.ReturnMode
+	return nil
+}
+
+// commandLines returns the lines of the command shown above the output (long ones are cut)
+func (s *RunSession) commandLines() ([]string, int) {
+	lines := strings.Split(s.Command, "\n")
+	if s.Stage != runConfirm && len(lines) > 3 {
+		return lines[:3], len(lines) - 3
+	}
+	return lines, 0
+}
+
+// outputHeight is how many output lines fit on a screen of the given height
+func (s *RunSession) outputHeight(height int) int {
+	shown, more := s.commandLines()
+	if more > 0 {
+		height--
+	}
+	// Title, separators, blank line and footer
+	return max(1, height-len(shown)-5)
+}
+
+// outputText returns the captured output as plain text
+func (s *RunSession) outputText() string {
+	lines := make([]string, len(s.Output))
+	for i, line := range s.Output {
+		lines[i] = line.Text
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statusText summarizes how the command ended, e.g. "exit 0 · 1.2s"
+func (s *RunSession) statusText() string {
+	status := fmt.Sprintf("exit %d", s.ExitCode)
+	if s.Err != "" {
+		status = s.Err
+	}
+	if s.Duration >= time.Second {
+		status += " · " + s.Duration.Round(100*time.Millisecond).String()
+	} else if s.Duration > 0 {
+		status += " · " + s.Duration.Round(time.Millisecond).String()
+	}
+	return status
+}
+
+// promptSaveRunOutput asks for a title and saves the command and its output as a new card
+func (m *Model) promptSaveRunOutput() {
+	s := m.Run
+	m.askPrompt("Title for the output card:", "Output: "+s.Title, func(m *Model, title string) tea.Cmd {
+		title = strings.TrimSpace(title)
+		if title == "" {
+			return nil
+		}
+		now := time.Now()
+		card := Card{
+			ID:         generateCardID(),
+			Title:      title,
+			Content:    runOutputCardContent(s, now),
+			CategoryID: s.CategoryID,
+			CreatedAt:  now.UnixMilli(),
+			UpdatedAt:  now.UnixMilli(),
+		}
+		return m.execute(createCardCommand{card: card}, fmt.Sprintf("💾 Saved output as '%s'", title))
+	})
+}
+
+// runOutputCardContent formats a finished run as markdown: the command, its output and status
+// The template markers of the output are escaped so the new card isn't mistaken for a template
+func runOutputCardContent(s *RunSession, at time.Time) string {
+	fence := "```"
+	for strings.Contains(s.Command+s.outputText(), fence) {
+		fence += "`"
+	}
+	var b strings.Builder
+	b.WriteString(fence + "console\n")
+	for _, line := range strings.Split(s.Command, "\n") {
+		b.WriteString("$ " + line + "\n")
+	}
+	if output := s.outputText(); output != "" {
+		b.WriteString(output + "\n")
+	}
+	b.WriteString(fence + "\n\n")
+	fmt.Fprintf(&b, "%s, %s", s.statusText(), at.Format("2006-01-02 15:04"))
+	if s.Truncated {
+		b.WriteString(" (output truncated)")
+	}
+	return strings.ReplaceAll(b.String(), "{{", `\{{`)
+}
+
+// runOutput collects stdout and stderr into lines, in the order they're written
+type runOutput struct {
+	mu        sync.Mutex
+	lines     []runLine
+	partial   [2]strings.Builder // Unfinished line of stdout / stderr
+	size      int
+	truncated bool
+}
+
+// runStream is the io.Writer for one stream of a runOutput
+type runStream struct {
+	out    *runOutput
+	stderr bool
+}
+
+func (o *runOutput) stream(stderr bool) *runStream {
+	return &runStream{out: o, stderr: stderr}
+}
+
+func (w *runStream) Write(p []byte) (int, error) {
+	o := w.out
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	text := string(p)
+	if room := maxRunOutput - o.size; len(text) > room {
+		text, o.truncated = text[:max(0, room)], true
+	}
+	o.size += len(text)
+
+	index := 0
+	if w.stderr {
+		index = 1
+	}
+	for {
+		line, rest, found := strings.Cut(text, "\n")
+		o.partial[index].WriteString(line)
+		if !found {
+			break
+		}
+		o.lines = append(o.lines, runLine{Text: cleanOutputLine(o.partial[index].String()), Stderr: w.stderr})
+		o.partial[index].Reset()
+		text = rest
+	}
+	// Report everything as written so a chatty command isn't killed by a short write
+	return len(p), nil
+}
+
+// finish flushes unterminated lines and returns the output
+func (o *runOutput) finish() ([]runLine, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.partial {
+		if o.partial[i].Len() > 0 {
+			o.lines = append(o.lines, runLine{Text: cleanOutputLine(o.partial[i].String()), Stderr: i == 1})
+			o.partial[i].Reset()
+		}
+	}
+	return o.lines, o.truncated
+}
+
+// terminalEscape matches ANSI escape sequences (colors, cursor movement...)
+var terminalEscape = regexp.MustCompile(`\x1b(\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+// cleanOutputLine makes a line of command output safe to draw: escape sequences are
+// removed, a carriage return keeps what was written last (progress bars) and tabs expand
+func cleanOutputLine(line string) string {
+	line = terminalEscape.ReplaceAllString(line, "")
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	line = strings.ReplaceAll(line, "\t", "    ")
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, line)
+}
+
+// renderRunScreen shows the command to confirm, or its output
+func renderRunScreen(m Model) string {
+	s := m.Run
+	width := max(20, m.Width-4)
+	separator := strings.Repeat("─", width)
+
+	title := stylePreviewTitle.Render("▶ Run '" + s.Title + "'")
+	var status string
+	switch s.Stage {
+	case runRunning:
+		status = styleHelpKey.Render("  running…")
+	case runDone:
+		if s.ExitCode == 0 && s.Err == "" {
+			status = styleFillValue.Render("  ✓ " + s.statusText())
+		} else {
+			status = styleError.Render("  ✗ " + s.statusText())
+		}
+	}
+	lines := []string{title + status, separator}
+
+	// The command, prompt-style
+	commandLines, more := s.commandLines()
+	for i, line := range commandLines {
+		prefix := "$ "
+		if i > 0 {
+			prefix = "  "
+		}
+		lines = append(lines, styleFillValue.Render(truncate(prefix+line, width)))
+	}
+	if more > 0 {
+		lines = append(lines, styleSubtle.Render(fmt.Sprintf("  … %d more line(s)", more)))
+	}
+
+	var footer []string
+	switch s.Stage {
+	case runConfirm:
+		lines = append(lines, "")
+		if s.Warning != "" {
+			lines = append(lines, styleError.Render("⚠ "+s.Warning))
+		}
+		lines = append(lines, styleSubtle.Render("Runs with "+runShell()+" -c in "+currentDir()))
+		footer = append(footer, strings.Join([]string{
+			styleHelpKey.Render("Enter/y") + styleHelpDesc.Render(" run"),
+			styleHelpKey.Render("i") + styleHelpDesc.Render(" run in terminal (interactive)"),
+			styleHelpKey.Render("Esc") + styleHelpDesc.Render(" cancel"),
+		}, "  "))
+
+	case runRunning:
+		if s.Interactive {
+			lines = append(lines, "", styleSubtle.Render("Running in the terminal…"))
+		} else {
+			lines = append(lines, "", styleSubtle.Render("Waiting for the command to finish…"))
+		}
+		footer = append(footer, styleHelpKey.Render("Esc")+styleHelpDesc.Render(" stop"))
+
+	case runDone:
+		lines = append(lines, separator)
+		outputHeight := s.outputHeight(m.Height)
+		var output []string
+		switch {
+		case s.Interactive:
+			output = []string{styleSubtle.Render("Output went to the terminal")}
+		case len(s.Output) == 0:
+			output = []string{styleSubtle.Render("(no output)")}
+		default:
+			for _, line := range s.Output[s.Scroll:min(len(s.Output), s.Scroll+outputHeight)] {
+				text := truncate(line.Text, width)
+				if line.Stderr {
+					text = lipgloss.NewStyle().Foreground(colorOrange).Render(text)
+				}
+				output = append(output, text)
+			}
+			if s.Truncated && s.Scroll+outputHeight >= len(s.Output) {
+				output = append(output[:len(output)-1], styleSubtle.Render(fmt.Sprintf("… output cut at %d KB", maxRunOutput/1024)))
+			}
+		}
+		lines = append(lines, output...)
+		for len(lines) < m.Height-2 {
+			lines = append(lines, "")
+		}
+
+		hints := []string{
+			styleHelpKey.Render("↑↓") + styleHelpDesc.Render(" scroll"),
+			styleHelpKey.Render("c") + styleHelpDesc.Render(" copy output"),
+			styleHelpKey.Render("s") + styleHelpDesc.Render(" save as card"),
+			styleHelpKey.Render("r") + styleHelpDesc.Render(" run again"),
+			styleHelpKey.Render("Esc") + styleHelpDesc.Render(" back"),
+		}
+		if len(s.Output) > outputHeight {
+			hints[0] += styleSubtle.Render(fmt.Sprintf(" (%d-%d of %d)", s.Scroll+1, min(len(s.Output), s.Scroll+outputHeight), len(s.Output)))
+		}
+		footer = append(footer, strings.Join(hints, "  "))
+	}
+
+	// Notifications (copied, saved...) replace the output hints for 5 seconds
+	if s.Stage == runDone && m.ReloadMessage != "" && time.Since(m.ReloadMessageTime) < 5*time.Second {
+		footer = []string{styleHelpKey.Render(m.ReloadMessage)}
+	}
+	lines = append(lines, "")
+	lines = append(lines, footer...)
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(lines, "\n"))
+}
+
+// currentDir returns the working directory commands run in
+func currentDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(dir, home) {
+		dir = "~" + strings.TrimPrefix(dir, home)
+	}
+	return dir
+}
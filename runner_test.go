@@ -0,0 +1,209 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runMsgs runs a command (and the commands of a batch) and returns their messages
+func runMsgs(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, runMsgs(c)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+// runTestModel returns a model with one card open in detail view
+// Usage and variable history are off so nothing is written to disk
+func runTestModel(card Card) Model {
+	m := testModel(&CellBlocksData{Cards: []Card{card}})
+	m.openDetailView()
+	return m
+}
+
+// finishRunning sends the messages of a run command back into the model
+func finishRunning(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	for _, msg := range runMsgs(cmd) {
+		if finished, ok := msg.(runFinishedMsg); ok {
+			model, _ := m.Update(finished)
+			m = model.(Model)
+		}
+	}
+	if m.Run == nil || m.Run.Stage != runDone {
+		t.Fatalf("command didn't finish")
+	}
+	return m
+}
+
+func TestRunnableBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		card    Card
+		want    int
+		wantErr string
+	}{
+		{name: "bash card", card: Card{ContentType: ContentCode, Language: "bash", Content: "ls\npwd"}, want: -1},
+		{name: "python card", card: Card{Title: "Py", ContentType: ContentCode, Language: "python", Content: "print(1)"}, wantErr: "'Py' is python code"},
+		{name: "first shell block", card: Card{Content: "Setup:\n```python\nprint(1)\n```\n```sh\nmake\n```"}, want: 1},
+		{name: "unlabelled block", card: Card{Content: "```\nmake\n```"}, want: 0},
+		{name: "no shell block", card: Card{Title: "Py", Content: "```python\nprint(1)\n```"}, wantErr: "no shell code block"},
+		{name: "one-line note", card: Card{Content: "htop -d 5"}, want: -1},
+		{name: "shell lines", card: Card{Content: "git fetch\ngit rebase origin/main"}, want: -1},
+		{name: "prose", card: Card{Title: "Notes", Content: "Remember to\nwater the plants"}, wantErr: "doesn't look like a shell command"},
+	}
+
+	m := testModel(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.runnableBlock(&tt.card, tt.card.Content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %d, %v; want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	output := &runOutput{}
+	stdout, stderr := output.stream(false), output.stream(true)
+	stdout.Write([]byte("hel"))
+	stderr.Write([]byte("warning\n"))
+	stdout.Write([]byte("lo\n\x1b[32mgreen\x1b[0m\tok\n10%\r50%\r100%\n"))
+	stdout.Write([]byte("no newline"))
+
+	lines, truncated := output.finish()
+	want := []runLine{{"warning", true}, {"hello", false}, {"green    ok", false}, {"100%", false}, {"no newline", false}}
+	if truncated || len(lines) != len(want) {
+		t.Fatalf("got %v (truncated %v), want %v", lines, truncated, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %v, want %v", i, lines[i], want[i])
+		}
+	}
+
+	big := &runOutput{}
+	n, err := big.stream(false).Write([]byte(strings.Repeat("x\n", maxRunOutput)))
+	if _, truncated := big.finish(); !truncated || n != 2*maxRunOutput || err != nil {
+		t.Errorf("big output: truncated %v, wrote %d, err %v", truncated, n, err)
+	}
+}
+
+func TestRunCard(t *testing.T) {
+	t.Setenv("SHELL", "sh")
+	m := runTestModel(Card{ID: "a", Title: "Greet", CategoryID: "ops", ContentType: ContentCode, Language: "bash",
+		Content: "echo hello {{name}}; echo oops >&2; exit 2"})
+
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		model, cmd := m.handleKeyPress(msg)
+		m = model.(Model)
+		return cmd
+	}
+
+	// A missing value keeps the form open
+	press(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if m.ViewMode != ViewDetail || !strings.Contains(m.ReloadMessage, "Can't run yet") {
+		t.Fatalf("ran without a value (view %d, message %q)", m.ViewMode, m.ReloadMessage)
+	}
+
+	m.TemplateVars["name"] = "{{you}}"
+	press(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if m.ViewMode != ViewRun || m.Run.Command != "echo hello {{you}}; echo oops >&2; exit 2" {
+		t.Fatalf("confirmation not shown (view %d)", m.ViewMode)
+	}
+
+	m = finishRunning(t, m, press(tea.KeyMsg{Type: tea.KeyEnter}))
+	want := []runLine{{"hello {{you}}", false}, {"oops", true}}
+	if m.Run.ExitCode != 2 || m.Run.Err != "" || len(m.Run.Output) != 2 {
+		t.Fatalf("exit %d, err %q, output %v", m.Run.ExitCode, m.Run.Err, m.Run.Output)
+	}
+	for _, line := range want {
+		found := false
+		for _, got := range m.Run.Output {
+			found = found || got == line
+		}
+		if !found {
+			t.Errorf("output %v is missing %v", m.Run.Output, line)
+		}
+	}
+
+	// Save the output as a new card (one undo step), in the same category
+	press(testKey("s"))
+	if m.Prompt == nil {
+		t.Fatal("s didn't ask for a title")
+	}
+	m.Prompt.OnSubmit(&m, "Greeting output")
+	m.Prompt = nil
+	saved := m.Data.Cards[len(m.Data.Cards)-1]
+	if saved.Title != "Greeting output" || saved.CategoryID != "ops" ||
+		!strings.Contains(saved.Content, "$ echo hello \\{{you}}") || !strings.Contains(saved.Content, "exit 2") {
+		t.Errorf("saved card %q in %q:\n%s", saved.Title, saved.CategoryID, saved.Content)
+	}
+	if HasTemplateVariables(saved.Content) {
+		t.Errorf("saved output became a template: %q", ExtractVariables(saved.Content))
+	}
+	m.undo()
+	if len(m.Data.Cards) != 1 {
+		t.Errorf("undo left %d cards", len(m.Data.Cards))
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.ViewMode != ViewDetail || m.Run != nil || m.TemplateVars["name"] != "{{you}}" {
+		t.Errorf("esc went to view %d, form values %v", m.ViewMode, m.TemplateVars)
+	}
+}
+
+func TestRunSecretsAndStop(t *testing.T) {
+	t.Setenv("SHELL", "sh")
+	t.Setenv("API_TOKEN", "s3cret")
+	m := runTestModel(Card{ID: "a", Title: "Token", Content: "echo {{secret:api_token}}"})
+
+	// The model only sees the mask; the command gets the value
+	m.startRun(m.getSelectedCard())
+	if m.Run == nil || m.Run.Command != "echo "+secretMask {
+		t.Fatalf("command = %+v", m.Run)
+	}
+	m = finishRunning(t, m, m.Run.execute(false))
+	if m.Run.outputText() != "s3cret" || strings.Contains(m.Run.Command, "s3cret") {
+		t.Errorf("output %q, command %q", m.Run.outputText(), m.Run.Command)
+	}
+
+	// A secret that can't be found never runs
+	t.Setenv("API_TOKEN", "")
+	m.Run.Stage = runConfirm
+	m = finishRunning(t, m, m.Run.execute(false))
+	if !strings.Contains(m.Run.Err, `secret "api_token"`) || m.Run.ExitCode != -1 {
+		t.Errorf("err %q exit %d", m.Run.Err, m.Run.ExitCode)
+	}
+
+	// Esc stops a long command
+	m.Run.Command = "sleep 10"
+	m.Run.resolve = func() (string, error) { return "sleep 10", nil }
+	cmd := m.Run.execute(false)
+	started := time.Now()
+	running := m
+	time.AfterFunc(100*time.Millisecond, func() { running.handleRunInput(tea.KeyMsg{Type: tea.KeyEsc}) })
+	m = finishRunning(t, m, cmd)
+	if m.Run.Err != "stopped" || time.Since(started) > 5*time.Second {
+		t.Errorf("err %q after %s", m.Run.Err, time.Since(started))
+	}
+}
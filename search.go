@@ -1,46 +1,97 @@
 package main
 
 import (
+	"sort"
 	"strings"
 )
 
 // search.go - Search and Filtering Engine
 // Purpose: Full-text search and category filtering
 
-// searchCards filters cards by search query (title and content)
-func searchCards(cards []Card, query string) []Card {
+// searchCards filters cards by search query (title, tags, fields and content)
+// categories supplies the field schemas that key:value terms are matched by.
+// Results are ranked by match quality; tieBreak (e.g. frecency) orders equal
+// matches, highest first. Pass nil to keep library order within a rank.
+func searchCards(cards []Card, query string, categories map[string]Category, tieBreak func(Card) float64) []Card {
 	if query == "" {
 		return cards
 	}
 
-	query = strings.ToLower(strings.TrimSpace(query))
-	if query == "" {
+	// "key:value" terms filter on custom fields; the rest is free text
+	text, filters := parseSearchQuery(query)
+	query = strings.ToLower(strings.TrimSpace(text))
+	if query == "" && len(filters) == 0 {
 		return cards
 	}
 
-	var results []Card
+	type rankedCard struct {
+		card  Card
+		rank  int
+		score float64
+	}
+
+	var ranked []rankedCard
 	for _, card := range cards {
-		if matchesSearch(card, query) {
-			results = append(results, card)
+		if !matchesFieldFilters(card, categories[card.CategoryID].Fields, filters) {
+			continue
 		}
+		rank := 1
+		if query != "" {
+			rank = searchRank(card, query)
+		}
+		if rank == 0 {
+			continue
+		}
+		entry := rankedCard{card: card, rank: rank}
+		if tieBreak != nil {
+			entry.score = tieBreak(card)
+		}
+		ranked = append(ranked, entry)
 	}
 
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].rank != ranked[j].rank {
+			return ranked[i].rank > ranked[j].rank
+		}
+		return ranked[i].score > ranked[j].score
+	})
+
+	results := make([]Card, len(ranked))
+	for i, entry := range ranked {
+		results[i] = entry.card
+	}
 	return results
 }
 
-// matchesSearch checks if a card matches the search query
-func matchesSearch(card Card, query string) bool {
-	// Search in title
-	if strings.Contains(strings.ToLower(card.Title), query) {
-		return true
+// searchRank scores how well a card matches a lowercase query (0 = no match)
+func searchRank(card Card, query string) int {
+	title := strings.ToLower(card.Title)
+
+	switch {
+	case title == query:
+		return 5
+	case strings.HasPrefix(title, query):
+		return 4
+	case strings.Contains(title, query):
+		return 3
+	}
+
+	for _, tag := range card.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return 2
+		}
+	}
+	for _, value := range card.Fields {
+		if strings.Contains(strings.ToLower(value), query) {
+			return 2
+		}
 	}
 
-	// Search in content
 	if strings.Contains(strings.ToLower(card.Content), query) {
-		return true
+		return 1
 	}
 
-	return false
+	return 0
 }
 
 // filterByCategories filters cards by selected categories
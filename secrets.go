@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// secrets.go - Secret Template Variables
+// Purpose: Resolve {{secret:name}} placeholders from a secret store when copying
+//
+// Secrets are looked up only inside the copy command, so their values never reach the
+// model, the variable history or cellblocks-data.json. The form and the filled preview
+// show them masked. Providers are tried in the order of "secrets.providers" in config.json:
+//
+//	env     $NAME (envPrefix + the name upper-cased, non-alphanumerics as _)
+//	pass    pass show <passPrefix><name> (first line)
+//	gopass  gopass show -o <passPrefix><name>
+//	file    name=value lines of an encrypted file, decrypted with decryptCommand (gpg, age...)
+
+// secretPrefix marks a secret placeholder: {{secret:db_password}}
+const secretPrefix = "secret:"
+
+// secretMask stands in for a secret's value wherever it's displayed
+const secretMask = "••••••••"
+
+// secretCommandTimeout bounds pass/gopass/gpg, which may wait for a pinentry dialog
+const secretCommandTimeout = 30 * time.Second
+
+// SecretsConfig chooses where {{secret:name}} values come from
+type SecretsConfig struct {
+	// Providers are tried in order: "env", "pass", "gopass", "file"
+	Providers []string `json:"providers"`
+
+	// EnvPrefix is put before the upper-cased name (e.g. "CB_" reads $CB_DB_PASSWORD)
+	EnvPrefix string `json:"envPrefix"`
+
+	// PassPrefix is put before the name for pass/gopass (e.g. "cellblocks/")
+	PassPrefix string `json:"passPrefix"`
+
+	// File holds name=value lines, encrypted; DecryptCommand prints it decrypted
+	// (the file path is appended as the last argument)
+	File           string   `json:"file"`
+	DecryptCommand []string `json:"decryptCommand"`
+}
+
+// defaultSecretsConfig reads secrets from the environment only
+func defaultSecretsConfig() SecretsConfig {
+	return SecretsConfig{
+		Providers:      []string{"env"},
+		File:           "~/.config/cellblocks-tui/secrets.env.gpg",
+		DecryptCommand: []string{"gpg", "--quiet", "--batch", "--decrypt"},
+	}
+}
+
+// isSecretVar reports whether the inside of {{...}} is a secret placeholder
+func isSecretVar(raw string) bool {
+	return strings.HasPrefix(strings.TrimSpace(raw), secretPrefix)
+}
+
+// parseSecretTag splits "secret:db_password::shellquote" into its name and filters
+func parseSecretTag(raw string) (string, []string) {
+	spec, filters := splitFilters(strings.TrimSpace(raw))
+	return strings.TrimSpace(strings.TrimPrefix(spec, secretPrefix)), filters
+}
+
+// hasSecretVars reports whether the card (with its includes) uses any secrets
+func (m *Model) hasSecretVars(card *Card) bool {
+	return len(secretNames(m.expandCard(card).Content)) > 0
+}
+
+// secretNames returns the names of the secrets in content, in order of appearance
+func secretNames(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, tag := range templateTags(content) {
+		if !isSecretVar(tag.Text) {
+			continue
+		}
+		if name, _ := parseSecretTag(tag.Text); name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// maskSecrets returns vars plus a masked value for each secret in content (for display)
+// vars itself is returned when content has no secrets
+func maskSecrets(content string, vars map[string]string) map[string]string {
+	var masked map[string]string
+	for _, tag := range templateTags(content) {
+		if !isSecretVar(tag.Text) {
+			continue
+		}
+		if masked == nil {
+			masked = make(map[string]string, len(vars)+1)
+			for name, value := range vars {
+				masked[name] = value
+			}
+		}
+		masked[tag.Text] = secretMask
+	}
+	if masked == nil {
+		return vars
+	}
+	return masked
+}
+
+// secretResolver looks secrets up with the configured providers
+// The encrypted file is decrypted at most once per resolver
+type secretResolver struct {
+	cfg      SecretsConfig
+	file     map[string]string
+	fileErr  error
+	fileRead bool
+}
+
+// resolve returns the value of one secret from the first provider that has it
+func (r *secretResolver) resolve(name string) (string, error) {
+	if len(r.cfg.Providers) == 0 {
+		return "", fmt.Errorf("secret %q: no providers configured (secrets.providers)", name)
+	}
+	var reasons []string
+	for _, provider := range r.cfg.Providers {
+		value, err := r.lookup(provider, name)
+		if err == nil && value != "" {
+			return value, nil
+		}
+		if err == nil {
+			err = errors.New("empty")
+		}
+		reasons = append(reasons, provider+": "+err.Error())
+	}
+	return "", fmt.Errorf("secret %q not found (%s)", name, strings.Join(reasons, "; "))
+}
+
+// lookup asks one provider for a secret
+func (r *secretResolver) lookup(provider, name string) (string, error) {
+	switch provider {
+	case "env":
+		key := r.cfg.EnvPrefix + secretEnvName(name)
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			return "", fmt.Errorf("$%s is not set", key)
+		}
+		return value, nil
+
+	case "pass":
+		output, err := runSecretCommand("pass", "show", r.cfg.PassPrefix+name)
+		first, _, _ := strings.Cut(output, "\n")
+		return first, err
+
+	case "gopass":
+		return runSecretCommand("gopass", "show", "-o", r.cfg.PassPrefix+name)
+
+	case "file":
+		if !r.fileRead {
+			r.fileRead = true
+			r.file, r.fileErr = r.readSecretsFile()
+		}
+		if r.fileErr != nil {
+			return "", r.fileErr
+		}
+		value, ok := r.file[name]
+		if !ok {
+			return "", fmt.Errorf("not in %s", r.cfg.File)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown provider (use env, pass, gopass or file)")
+}
+
+// readSecretsFile decrypts the secrets file into name -> value
+func (r *secretResolver) readSecretsFile() (map[string]string, error) {
+	if len(r.cfg.DecryptCommand) == 0 {
+		return nil, fmt.Errorf("no decryptCommand configured")
+	}
+	path := expandPath(r.cfg.File)
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("can't read %s", r.cfg.File)
+	}
+	args := append(append([]string(nil), r.cfg.DecryptCommand[1:]...), path)
+	output, err := runSecretCommand(r.cfg.DecryptCommand[0], args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseSecretsFile(output), nil
+}
+
+// parseSecretsFile reads name=value lines (blank lines and # comments are skipped)
+func parseSecretsFile(content string) map[string]string {
+	secrets := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, value, ok := strings.Cut(line, "="); ok {
+			secrets[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+	}
+	return secrets
+}
+
+// secretEnvName turns "db/password" into "DB_PASSWORD"
+func secretEnvName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+// runSecretCommand runs a secret store command and returns its output without the
+// trailing newline. Errors never include the output, which may hold a secret
+func runSecretCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// fillWithSecrets resolves the secrets in content and fills it with vars
+// vars is not modified - the secret values only exist in the returned text
+func fillWithSecrets(content string, vars map[string]string, cfg SecretsConfig) (string, error) {
+	names := secretNames(content)
+	if len(names) == 0 {
+		return FillTemplate(content, vars), nil
+	}
+
+	resolver := &secretResolver{cfg: cfg}
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := resolver.resolve(name)
+		if err != nil {
+			return "", err
+		}
+		values[name] = value
+	}
+
+	filled := make(map[string]string, len(vars)+len(names))
+	for name, value := range vars {
+		filled[name] = value
+	}
+	for _, tag := range templateTags(content) {
+		if isSecretVar(tag.Text) {
+			name, filters := parseSecretTag(tag.Text)
+			filled[tag.Text] = applyFilters(values[name], filters)
+		}
+	}
+	return FillTemplate(content, filled), nil
+}
+
+// secretErrorMsg is sent when a secret can't be resolved (the copy is skipped)
+type secretErrorMsg struct {
+	err error
+}
+
+// copyWithSecrets fills content (resolving its secrets) and copies it in the background
+// pick returns the part of the result to copy (the whole text, its first code block...)
+func copyWithSecrets(content string, vars map[string]string, cfg SecretsConfig, pick func(filled string) string, cardIDs []string) tea.Cmd {
+	// Snapshot so later typing doesn't race with the command
+	snapshot := make(map[string]string, len(vars))
+	for name, value := range vars {
+		snapshot[name] = value
+	}
+
+	return func() tea.Msg {
+		filled, err := fillWithSecrets(content, snapshot, cfg)
+		if err != nil {
+			return secretErrorMsg{err: err}
+		}
+		if err := copyToClipboardSync(pick(filled)); err != nil {
+			return copyErrorMsg{err: err}
+		}
+		return cardCopiedMsg{cardTitle: "Card copied to clipboard", cardIDs: cardIDs, templateFilled: true}
+	}
+}
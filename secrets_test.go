@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFillWithSecrets(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "it's")
+	t.Setenv("CB_API_KEY", "k3y")
+
+	secretsFile := filepath.Join(t.TempDir(), "secrets.env")
+	if err := os.WriteFile(secretsFile, []byte("# test\napi/token = t0ken\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		vars    map[string]string
+		cfg     SecretsConfig
+		want    string
+		wantErr string
+	}{
+		{name: "env", content: "psql -W {{secret:db_password}} -h {{host}}", vars: map[string]string{"host": "db"}, cfg: SecretsConfig{Providers: []string{"env"}}, want: "psql -W it's -h db"},
+		{name: "filters apply", content: "PGPASSWORD={{secret:db_password::shellquote}}", cfg: SecretsConfig{Providers: []string{"env"}}, want: `PGPASSWORD='it'"'"'s'`},
+		{name: "env prefix and name mangling", content: "{{secret:api-key}}", cfg: SecretsConfig{Providers: []string{"env"}, EnvPrefix: "CB_"}, want: "k3y"},
+		{name: "falls through to the next provider", content: "{{secret:api/token}}", cfg: SecretsConfig{Providers: []string{"env", "file"}, File: secretsFile, DecryptCommand: []string{"cat"}}, want: "t0ken"},
+		{name: "no secrets", content: "echo {{x}}", vars: map[string]string{"x": "1"}, want: "echo 1"},
+		{name: "not found", content: "{{secret:nope}}", cfg: SecretsConfig{Providers: []string{"env", "file"}, File: secretsFile, DecryptCommand: []string{"cat"}}, wantErr: `secret "nope" not found (env: $NOPE is not set; file: not in`},
+		{name: "unknown provider", content: "{{secret:x}}", cfg: SecretsConfig{Providers: []string{"vault"}}, wantErr: "vault: unknown provider"},
+		{name: "no providers", content: "{{secret:x}}", wantErr: "no providers configured"},
+		{name: "missing file", content: "{{secret:x}}", cfg: SecretsConfig{Providers: []string{"file"}, File: "/nonexistent/secrets", DecryptCommand: []string{"cat"}}, wantErr: "can't read /nonexistent/secrets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := tt.vars
+			if vars == nil {
+				vars = map[string]string{}
+			}
+			got, err := fillWithSecrets(tt.content, vars, tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			for key := range vars {
+				if isSecretVar(key) {
+					t.Errorf("secret %q was stored in vars", key)
+				}
+			}
+		})
+	}
+}
+
+func TestSecretsStayOutOfTheForm(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+
+	m := testModel(&CellBlocksData{Cards: []Card{{ID: "a", Title: "psql", Content: "PGPASSWORD={{secret:db_password}} psql -h {{host}}"}}})
+	m.VarHistory = newVarHistoryData()
+	m.openDetailView()
+
+	// Only {{host}} is a form field
+	if len(m.DetectedDecls) != 1 || m.DetectedDecls[0].Name != "host" {
+		t.Fatalf("form fields = %v, want just host", m.DetectedVars)
+	}
+
+	// The preview shows the secret masked
+	m.TemplateVars["host"] = "db"
+	card := m.getSelectedCard()
+	if source := m.detailSource(card); strings.Contains(source, "hunter2") || !strings.Contains(source, secretMask) {
+		t.Errorf("preview = %q, want the secret masked", source)
+	}
+
+	// Copying remembers host but never the secret
+	if cmd := m.copyFilledTemplate(card); cmd == nil {
+		t.Fatal("copy returned no command")
+	}
+	for _, values := range m.VarHistory.Global {
+		for _, value := range values {
+			if value == "hunter2" {
+				t.Errorf("secret remembered in the variable history: %v", m.VarHistory.Global)
+			}
+		}
+	}
+	for key, value := range m.TemplateVars {
+		if value == "hunter2" || isSecretVar(key) {
+			t.Errorf("secret stored in the form values: %q = %q", key, value)
+		}
+	}
+}
+
+func TestParseSecretTag(t *testing.T) {
+	tests := []struct {
+		raw         string
+		wantName    string
+		wantFilters string
+	}{
+		{raw: "secret:db_password", wantName: "db_password"},
+		{raw: " secret: api/token ::shellquote::upper", wantName: "api/token", wantFilters: "shellquote,upper"},
+		{raw: "secret:", wantName: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			name, filters := parseSecretTag(tt.raw)
+			if name != tt.wantName || strings.Join(filters, ",") != tt.wantFilters {
+				t.Errorf("got %q %q, want %q %q", name, filters, tt.wantName, tt.wantFilters)
+			}
+		})
+	}
+}
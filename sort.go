@@ -7,7 +7,51 @@ import (
 )
 
 // sort.go - Card Sorting Functions
-// Purpose: Sort cards by different columns for table view
+// Purpose: Sort cards by table columns and pick the list/grid ordering
+
+// tableSortColumns maps number keys 1-4 to the built-in table sort columns
+// (custom field columns follow on 5-9, see tableColumns)
+var tableSortColumns = []string{"title", "category", "created", "updated"}
+
+// setSortColumn sorts the table by a column, toggling direction if it's already active
+func (m *Model) setSortColumn(column string) {
+	if m.SortColumn == column {
+		// Toggle direction
+		if m.SortDirection == "asc" {
+			m.SortDirection = "desc"
+		} else {
+			m.SortDirection = "asc"
+		}
+	} else {
+		m.SortColumn = column
+		m.SortDirection = "asc"
+	}
+	m.updateFilteredCards()
+}
+
+// listSortModes are the orderings available for list/grid views ("" = library order)
+var listSortModes = []string{"", "frecency", "manual"}
+
+// cycleListSort switches to the next list/grid ordering
+func (m *Model) cycleListSort() {
+	for i, mode := range listSortModes {
+		if mode == m.ListSort {
+			m.ListSort = listSortModes[(i+1)%len(listSortModes)]
+			m.updateFilteredCards()
+			return
+		}
+	}
+	m.ListSort = ""
+	m.updateFilteredCards()
+}
+
+// listSortLabel returns a short label for the current list/grid ordering
+func listSortLabel(mode string) string {
+	if mode == "" {
+		return "library order"
+	}
+	return mode
+}
 
 // sortCards sorts a slice of cards based on the specified column and direction
 func sortCards(cards []Card, categoryMap map[string]Category, column, direction string) []Card {
@@ -15,6 +59,18 @@ func sortCards(cards []Card, categoryMap map[string]Category, column, direction
 	sorted := make([]Card, len(cards))
 	copy(sorted, cards)
 
+	// Custom field columns sort by their text value
+	if key, ok := strings.CutPrefix(column, fieldColumnPrefix); ok {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			result := strings.ToLower(sorted[i].Fields[key]) < strings.ToLower(sorted[j].Fields[key])
+			if direction == "desc" {
+				return !result
+			}
+			return result
+		})
+		return sorted
+	}
+
 	// Define the comparison function based on column
 	switch column {
 	case "title":
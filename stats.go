@@ -0,0 +1,201 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// stats.go - Library Statistics
+// Purpose: Compute the numbers behind the stats dashboard (S) and draw bars/sparklines
+
+const (
+	// statsWeeks is how many weeks of card creation the sparkline covers
+	statsWeeks = 12
+	// statsTopN is how many cards the most/least used lists show
+	statsTopN = 5
+
+	// missingCategoryKey groups cards whose category no longer exists
+	missingCategoryKey = "\x00missing"
+)
+
+// refreshStats computes the dashboard body (on open, resize and reload) so scrolling
+// and drawing reuse it instead of walking the whole library every keypress and frame
+func (m *Model) refreshStats() {
+	if m.Data == nil {
+		m.StatsLines = nil
+		return
+	}
+	m.StatsLines = renderStatsLines(*m)
+}
+
+// categoryCount is the number of cards in one category
+type categoryCount struct {
+	Name  string
+	Color string
+	Count int
+}
+
+// cardUsageStat pairs a card with its usage record
+type cardUsageStat struct {
+	Card  Card
+	Usage CardUsage
+}
+
+// templateVarCount is how many cards use a template variable
+type templateVarCount struct {
+	Name  string
+	Cards int
+}
+
+// LibraryStats is a snapshot of library health for the stats screen
+type LibraryStats struct {
+	TotalCards       int
+	TotalCopies      int
+	Categories       []categoryCount    // Largest first
+	WeeklyCreated    []int              // Oldest week first, current week last
+	MostUsed         []cardUsageStat    // Highest copy count first
+	LeastUsed        []cardUsageStat    // Lowest copy count first (copied at least once)
+	NeverCopied      []Card             // Library order
+	AvgContentLength int                // Characters
+	TemplateCards    int                // Cards with at least one {{variable}}
+	TemplateVars     []templateVarCount // Most used first
+}
+
+// computeLibraryStats gathers statistics for all cards in the library
+func computeLibraryStats(data *CellBlocksData, usage *UsageData, now time.Time) LibraryStats {
+	stats := LibraryStats{WeeklyCreated: make([]int, statsWeeks)}
+	if data == nil {
+		return stats
+	}
+	if usage == nil {
+		usage = newUsageData()
+	}
+	stats.TotalCards = len(data.Cards)
+
+	categoryIndex := make(map[string]int)
+	for _, cat := range data.Categories {
+		categoryIndex[cat.ID] = len(stats.Categories)
+		stats.Categories = append(stats.Categories, categoryCount{Name: cat.Name, Color: cat.Color})
+	}
+
+	varCards := make(map[string]int)
+	var used []cardUsageStat
+	totalLength := 0
+
+	for _, card := range data.Cards {
+		// Cards per category (cards pointing at a missing category are grouped together)
+		i, ok := categoryIndex[card.CategoryID]
+		if !ok {
+			if i, ok = categoryIndex[missingCategoryKey]; !ok {
+				i = len(stats.Categories)
+				categoryIndex[missingCategoryKey] = i
+				stats.Categories = append(stats.Categories, categoryCount{Name: "(no category)"})
+			}
+		}
+		stats.Categories[i].Count++
+
+		// Cards created per week
+		if card.CreatedAt > 0 {
+			weeksAgo := int(now.Sub(time.UnixMilli(card.CreatedAt)) / (7 * 24 * time.Hour))
+			if weeksAgo >= 0 && weeksAgo < statsWeeks {
+				stats.WeeklyCreated[statsWeeks-1-weeksAgo]++
+			}
+		}
+
+		// Usage
+		if entry := usage.Cards[card.ID]; entry.CopyCount > 0 {
+			used = append(used, cardUsageStat{Card: card, Usage: entry})
+			stats.TotalCopies += entry.CopyCount
+		} else {
+			stats.NeverCopied = append(stats.NeverCopied, card)
+		}
+
+		totalLength += len([]rune(card.Content))
+
+		// Template variables
+		vars := ExtractVariables(card.Content)
+		if len(vars) > 0 {
+			stats.TemplateCards++
+		}
+		for _, name := range vars {
+			varCards[name]++
+		}
+	}
+
+	if stats.TotalCards > 0 {
+		stats.AvgContentLength = totalLength / stats.TotalCards
+	}
+
+	// Drop empty categories and show the largest first
+	nonEmpty := stats.Categories[:0]
+	for _, cat := range stats.Categories {
+		if cat.Count > 0 {
+			nonEmpty = append(nonEmpty, cat)
+		}
+	}
+	stats.Categories = nonEmpty
+	sort.SliceStable(stats.Categories, func(i, j int) bool {
+		return stats.Categories[i].Count > stats.Categories[j].Count
+	})
+
+	// Most used first; ties go to the most recently used
+	sort.SliceStable(used, func(i, j int) bool {
+		if used[i].Usage.CopyCount != used[j].Usage.CopyCount {
+			return used[i].Usage.CopyCount > used[j].Usage.CopyCount
+		}
+		return used[i].Usage.LastUsedAt > used[j].Usage.LastUsedAt
+	})
+	stats.MostUsed = used[:min(statsTopN, len(used))]
+	// Least used never repeats a most used card (small libraries would list them twice)
+	for i := len(used) - 1; i >= len(stats.MostUsed) && len(stats.LeastUsed) < statsTopN; i-- {
+		stats.LeastUsed = append(stats.LeastUsed, used[i])
+	}
+
+	for name, count := range varCards {
+		stats.TemplateVars = append(stats.TemplateVars, templateVarCount{Name: name, Cards: count})
+	}
+	sort.Slice(stats.TemplateVars, func(i, j int) bool {
+		if stats.TemplateVars[i].Cards != stats.TemplateVars[j].Cards {
+			return stats.TemplateVars[i].Cards > stats.TemplateVars[j].Cards
+		}
+		return stats.TemplateVars[i].Name < stats.TemplateVars[j].Name
+	})
+
+	return stats
+}
+
+// sparkline renders values as a row of block characters scaled to the maximum
+func sparkline(values []int) string {
+	levels := []rune("▁▂▃▄▅▆▇█")
+
+	maxValue := 0
+	for _, v := range values {
+		maxValue = max(maxValue, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		if maxValue == 0 {
+			b.WriteRune(levels[0])
+			continue
+		}
+		b.WriteRune(levels[v*(len(levels)-1)/maxValue])
+	}
+	return b.String()
+}
+
+// renderBar draws a horizontal bar of value/maxValue scaled to width cells
+// Non-zero values always get at least one cell so small categories stay visible
+func renderBar(value, maxValue, width int, color string) string {
+	if maxValue <= 0 || width <= 0 {
+		return ""
+	}
+	cells := value * width / maxValue
+	if value > 0 && cells == 0 {
+		cells = 1
+	}
+	return lipgloss.NewStyle().Foreground(getCategoryColor(color)).Render(strings.Repeat("█", cells))
+}
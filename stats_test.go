@@ -0,0 +1,183 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestComputeLibraryStats(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	weeksAgo := func(weeks int) int64 {
+		return now.Add(-time.Duration(weeks) * 7 * 24 * time.Hour).UnixMilli()
+	}
+
+	data := &CellBlocksData{
+		Categories: []Category{
+			{ID: "docker", Name: "Docker", Color: "#0db7ed"},
+			{ID: "git", Name: "Git", Color: "#f05032"},
+			{ID: "empty", Name: "Empty"},
+		},
+		Cards: []Card{
+			{ID: "a", CategoryID: "git", Content: "git log", CreatedAt: weeksAgo(0)},
+			{ID: "b", CategoryID: "docker", Content: "docker run {{image}}", CreatedAt: weeksAgo(0)},
+			{ID: "c", CategoryID: "docker", Content: "docker exec {{container}} {{image}}", CreatedAt: weeksAgo(3)},
+			{ID: "d", CategoryID: "docker", Content: "abc", CreatedAt: weeksAgo(50)},
+			{ID: "e", CategoryID: "gone", Content: "orphan"},
+		},
+	}
+
+	usage := newUsageData()
+	for i := 0; i < 3; i++ {
+		usage.recordCopy("b", false, now)
+	}
+	usage.recordCopy("a", false, now)
+
+	stats := computeLibraryStats(data, usage, now)
+
+	if stats.TotalCards != 5 || stats.TotalCopies != 4 {
+		t.Errorf("totals = %d cards, %d copies; want 5 and 4", stats.TotalCards, stats.TotalCopies)
+	}
+
+	wantCategories := []categoryCount{
+		{Name: "Docker", Color: "#0db7ed", Count: 3},
+		{Name: "Git", Color: "#f05032", Count: 1},
+		{Name: "(no category)", Count: 1},
+	}
+	if len(stats.Categories) != len(wantCategories) {
+		t.Fatalf("categories = %+v, want %+v", stats.Categories, wantCategories)
+	}
+	for i, want := range wantCategories {
+		if stats.Categories[i] != want {
+			t.Errorf("categories[%d] = %+v, want %+v", i, stats.Categories[i], want)
+		}
+	}
+
+	if stats.WeeklyCreated[statsWeeks-1] != 2 || stats.WeeklyCreated[statsWeeks-4] != 1 {
+		t.Errorf("weekly created = %v, want 2 this week and 1 three weeks ago", stats.WeeklyCreated)
+	}
+
+	if len(stats.MostUsed) != 2 || stats.MostUsed[0].Card.ID != "b" {
+		t.Errorf("most used = %+v, want b first", stats.MostUsed)
+	}
+	// Both used cards are already in most used
+	if len(stats.LeastUsed) != 0 {
+		t.Errorf("least used = %+v, want none (no overlap with most used)", stats.LeastUsed)
+	}
+	if ids := cardIDs(stats.NeverCopied); !equalStrings(ids, []string{"c", "d", "e"}) {
+		t.Errorf("never copied = %v, want [c d e]", ids)
+	}
+
+	if stats.TemplateCards != 2 {
+		t.Errorf("template cards = %d, want 2", stats.TemplateCards)
+	}
+	if len(stats.TemplateVars) != 2 || stats.TemplateVars[0] != (templateVarCount{Name: "image", Cards: 2}) {
+		t.Errorf("template vars = %+v, want image (2) first", stats.TemplateVars)
+	}
+
+	// (7 + 20 + 35 + 3 + 6) / 5
+	if stats.AvgContentLength != 14 {
+		t.Errorf("avg content length = %d, want 14", stats.AvgContentLength)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   string
+	}{
+		{[]int{0, 0, 0}, "▁▁▁"},
+		{[]int{0, 7, 14}, "▁▄█"},
+		{[]int{1}, "█"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestLeastUsedSkipsMostUsed(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	data := &CellBlocksData{}
+	usage := newUsageData()
+	// Card n is copied n times
+	for n := 1; n <= 7; n++ {
+		id := string(rune('0' + n))
+		data.Cards = append(data.Cards, Card{ID: id})
+		for i := 0; i < n; i++ {
+			usage.recordCopy(id, false, now)
+		}
+	}
+
+	stats := computeLibraryStats(data, usage, now)
+	var most, least []string
+	for _, entry := range stats.MostUsed {
+		most = append(most, entry.Card.ID)
+	}
+	for _, entry := range stats.LeastUsed {
+		least = append(least, entry.Card.ID)
+	}
+	if !equalStrings(most, []string{"7", "6", "5", "4", "3"}) || !equalStrings(least, []string{"1", "2"}) {
+		t.Errorf("most used %v, least used %v", most, least)
+	}
+}
+
+func TestStatsScreenComputesOnce(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{{ID: "a", Title: "One"}}})
+	m.Width, m.Height = 60, 10
+	m.Usage = newUsageData()
+
+	model, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	m = model.(Model)
+	if m.ViewMode != ViewStats || len(m.StatsLines) == 0 {
+		t.Fatalf("S didn't compute the dashboard (view %d)", m.ViewMode)
+	}
+
+	// Scrolling and drawing use the stored lines - a library change shows on the next open
+	lines := len(m.StatsLines)
+	m.Data.Cards = nil
+	model, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnd})
+	m = model.(Model)
+	if len(m.StatsLines) != lines || m.StatsScrollOffset != max(0, lines-statsVisibleLines(m)) {
+		t.Errorf("end: offset %d of %d lines", m.StatsScrollOffset, len(m.StatsLines))
+	}
+
+	model, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if m.ViewMode == ViewStats || m.StatsLines != nil {
+		t.Errorf("esc kept the dashboard (view %d)", m.ViewMode)
+	}
+}
+
+func TestPageKeys(t *testing.T) {
+	// Bubbletea reports PgUp/PgDn as "pgup"/"pgdown"
+	if got := (tea.KeyMsg{Type: tea.KeyPgDown}).String(); got != "pgdown" {
+		t.Fatalf("PgDn = %q", got)
+	}
+
+	var cards []Card
+	for i := 0; i < 40; i++ {
+		cards = append(cards, Card{ID: string(rune('A' + i)), Title: "Card"})
+	}
+	m := testModel(&CellBlocksData{Cards: cards})
+	m.Usage = newUsageData()
+
+	model, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyPgDown})
+	m = model.(Model)
+	if m.SelectedIndex == 0 {
+		t.Error("PgDn didn't move the list selection")
+	}
+
+	m.Height = 10 // Dashboard taller than the screen
+	model, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+	m = model.(Model)
+	model, _ = m.handleKeyPress(tea.KeyMsg{Type: tea.KeyPgDown})
+	m = model.(Model)
+	if m.StatsScrollOffset == 0 {
+		t.Error("PgDn didn't scroll the stats dashboard")
+	}
+}
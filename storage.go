@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -21,6 +23,9 @@ const (
 	DefaultDataPath = "~/projects/CellBlocks/data/cellblocks-data.json"
 )
 
+// dataSaves orders background saves of the data file
+var dataSaves saveQueue
+
 // expandPath expands ~ to home directory
 func expandPath(path string) string {
 	if strings.HasPrefix(path, "~/") {
@@ -66,19 +71,85 @@ func loadDataAsync(path string) tea.Cmd {
 
 // SaveData writes the CellBlocksData to disk (for future editing features)
 func SaveData(path string, data *CellBlocksData) error {
-	fullPath := expandPath(path)
+	content, err := MarshalData(data)
+	if err != nil {
+		return err
+	}
+	return WriteDataFile(path, content)
+}
 
-	// Marshal to JSON with indentation
+// MarshalData encodes the library as indented JSON
+// Call it on the UI goroutine and write the bytes in the background, so the
+// save never reads cards that later keypresses are changing
+func MarshalData(data *CellBlocksData) ([]byte, error) {
 	content, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
+	return content, nil
+}
 
-	// Write to file
-	if err := os.WriteFile(fullPath, content, 0644); err != nil {
+// WriteDataFile writes already-marshaled library JSON to disk
+func WriteDataFile(path string, content []byte) error {
+	if err := writeFileAtomic(expandPath(path), content); err != nil {
 		return fmt.Errorf("failed to write data file: %w", err)
 	}
+	return nil
+}
+
+// writeFileAtomic writes content to a temp file next to path and renames it into
+// place, so a crash mid-write leaves the old file instead of a truncated one
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// saveQueue orders the background saves of one file
+// Take a ticket when the snapshot is made (on the update goroutine); saves then
+// run one at a time, and one older than the snapshot already written is skipped,
+// so a slow save can never overwrite a newer one
+type saveQueue struct {
+	issued  atomic.Uint64
+	mu      sync.Mutex
+	written uint64 // Ticket of the newest snapshot on disk
+}
+
+// ticket reserves the next position in the save order
+func (q *saveQueue) ticket() uint64 {
+	return q.issued.Add(1)
+}
 
+// save runs write unless a newer snapshot was already written
+func (q *saveQueue) save(ticket uint64, write func() error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if ticket <= q.written {
+		return nil
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	q.written = ticket
 	return nil
 }
 
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(content)); err != nil {
+			t.Fatalf("writeFileAtomic(%q) error: %v", content, err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil || string(got) != "second" {
+		t.Errorf("file = %q, %v; want second", got, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("dir has %d entries, want no temp files left", len(entries))
+	}
+}
+
+func TestSaveQueueSkipsStaleSaves(t *testing.T) {
+	var q saveQueue
+	var written []string
+	write := func(name string) func() error {
+		return func() error {
+			written = append(written, name)
+			return nil
+		}
+	}
+
+	older, newer := q.ticket(), q.ticket()
+
+	// The newer snapshot finishes first - the older one must not overwrite it
+	if err := q.save(newer, write("newer")); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.save(older, write("older")); err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1 || written[0] != "newer" {
+		t.Errorf("written = %v, want only the newer snapshot", written)
+	}
+
+	if err := q.save(q.ticket(), write("latest")); err != nil || written[len(written)-1] != "latest" {
+		t.Errorf("written = %v, %v; want the latest save to go through", written, err)
+	}
+}
@@ -116,6 +116,65 @@ var (
 	styleHelpDesc = lipgloss.NewStyle().
 			Foreground(colorGray)
 
+	// Favorites star and pinned separator
+	styleFavorite = lipgloss.NewStyle().
+			Foreground(colorYellow).
+			Bold(true)
+
+	// Multi-selection mark
+	styleMarked = lipgloss.NewStyle().
+			Foreground(colorAccent).
+			Bold(true)
+
+	// Wiki-style [[links]] in detail view
+	styleLink = lipgloss.NewStyle().
+			Foreground(colorSecondary).
+			Underline(true)
+
+	styleLinkFocused = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#000000")).
+				Background(colorSecondary).
+				Bold(true)
+
+	styleLinkBroken = lipgloss.NewStyle().
+			Foreground(colorOrange).
+			Strikethrough(true)
+
+	// Cursor inside a text input (at the end it's a █)
+	styleInputCursor = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#1a1a1a")).
+				Background(colorPrimary).
+				Bold(true)
+
+	// Live filled-template preview: values typed so far, variables still missing
+	styleFillValue = lipgloss.NewStyle().
+			Foreground(colorPrimary).
+			Bold(true)
+
+	styleFillMissing = lipgloss.NewStyle().
+				Foreground(colorOrange).
+				Underline(true)
+
+	// Active variable profile badge (warn profiles, e.g. prod, in red)
+	styleProfile = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(colorSecondary).
+			Bold(true).
+			Padding(0, 1)
+
+	styleProfileWarn = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#ffffff")).
+				Background(lipgloss.Color("#cc0000")).
+				Bold(true).
+				Padding(0, 1)
+
+	// Modal dialogs (confirmations)
+	styleDialogBox = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(colorOrange).
+			Padding(1, 2).
+			Background(lipgloss.Color("#0a0a0a"))
+
 	// Grid view card - compact 27 chars wide (was 36)
 	// NO padding - maximizes space for content
 	styleGridCard = lipgloss.NewStyle().
@@ -214,9 +273,20 @@ func wrapText(text string, maxWidth int, maxLines int) []string {
 }
 
 // makeGridCardStyle creates a card style with category-colored border
-func makeGridCardStyle(categoryColor string, selected bool) lipgloss.Style {
+// Marked cards (multi-selection) get a double border in the accent color
+func makeGridCardStyle(categoryColor string, selected, marked bool) lipgloss.Style {
 	color := getCategoryColor(categoryColor)
 
+	if marked {
+		style := styleGridCard
+		if selected {
+			style = styleGridCardSelected
+		}
+		return style.
+			Border(lipgloss.DoubleBorder()).
+			BorderForeground(colorAccent)
+	}
+
 	if selected {
 		return styleGridCardSelected.
 			BorderForeground(color)
@@ -1,34 +1,287 @@
 package main
 
 import (
+	"fmt"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // template.go - Template Variable Parsing
 // Purpose: Detect and fill {{variable}} patterns in card content
+//
+// Variables can declare a type and a default:
+//
+//	{{name}}  {{name|default}}  {{port:int|3000}}  {{env:choice(dev,staging,prod)}}
+//	{{path:file}}  {{confirm:bool}}  {{tag:match(^v\d+\.\d+$)}}
+//
+// and transform the filled value with filters (see filters.go): {{query::urlencode|cats}}
+//
+// Unknown types are read as part of the name, so "{{Note: call first}}" keeps working.
+// {{#if}} / {{#each}} sections are handled in templateblocks.go; templatetokens.go
+// decides what counts as a tag ("\{{" is a literal "{{").
 
-// ExtractVariables finds all {{variable}} patterns in content
-// Returns unique variable names (without braces or defaults)
-func ExtractVariables(content string) []string {
-	// Match {{variable}} or {{variable|default}}
-	re := regexp.MustCompile(`\{\{([^}|]+)(?:\|[^}]*)?\}\}`)
-	matches := re.FindAllStringSubmatch(content, -1)
+// Variable types
+const (
+	VarText   = "text"
+	VarInt    = "int"
+	VarBool   = "bool"
+	VarFile   = "file"
+	VarChoice = "choice"
+	VarMatch  = "match"
+	VarList   = "list" // Comma/newline-separated items for {{#each}}
+)
 
-	// Collect unique variable names
-	seen := make(map[string]bool)
-	var vars []string
+// TemplateVar is a parsed {{variable}} declaration
+type TemplateVar struct {
+	Name    string
+	Type    string   // One of the Var* types
+	Default string   // Value used when the field is left empty
+	Options []string // Allowed values for choice
+	Pattern *regexp.Regexp
+	Filters []string // ::filters applied to the value when filling
+	Err     string   // Problem with the declaration itself (e.g. bad regex)
+}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			varName := strings.TrimSpace(match[1])
-			if !seen[varName] {
-				seen[varName] = true
-				vars = append(vars, varName)
+// ParseTemplateVar parses the inside of {{...}} into a declaration
+func ParseTemplateVar(raw string) TemplateVar {
+	raw, filters := splitFilters(strings.TrimSpace(raw))
+	v := parseTemplateDecl(raw)
+	v.Filters = filters
+	if err := checkFilters(filters); err != nil && v.Err == "" {
+		v.Err = err.Error()
+	}
+	return v
+}
+
+// parseTemplateDecl parses "name", "name:type(args)" and "|default" (filters already removed)
+func parseTemplateDecl(raw string) TemplateVar {
+	v := TemplateVar{Name: raw, Type: VarText}
+
+	end := strings.IndexAny(raw, ":|")
+	if end < 0 {
+		return v
+	}
+	v.Name = strings.TrimSpace(raw[:end])
+	rest := raw[end:]
+
+	if rest[0] == ':' {
+		typed, remainder, ok := parseVarType(&v, strings.TrimLeft(rest[1:], " "))
+		if !ok {
+			// Not a known type - the colon is part of the name
+			name, defaultVal := ParseDefaultValue(raw)
+			return TemplateVar{Name: name, Type: VarText, Default: defaultVal}
+		}
+		v.Type = typed
+		rest = strings.TrimSpace(remainder)
+	}
+
+	if strings.HasPrefix(rest, "|") {
+		v.Default = strings.TrimSpace(rest[1:])
+	}
+	return v
+}
+
+// parseVarType reads "type" or "type(args)" at the start of s into v
+// Returns the type, the unparsed remainder and false if the type is unknown
+func parseVarType(v *TemplateVar, s string) (string, string, bool) {
+	i := 0
+	for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z') {
+		i++
+	}
+	typed := strings.ToLower(s[:i])
+	rest := s[i:]
+
+	args := ""
+	if strings.HasPrefix(rest, "(") {
+		close := matchingParen(rest)
+		if close < 0 {
+			v.Err = "missing )"
+			args, rest = rest[1:], ""
+		} else {
+			args, rest = rest[1:close], rest[close+1:]
+		}
+	}
+
+	switch typed {
+	case VarText, VarInt, VarBool, VarFile, VarList:
+	case VarChoice:
+		for _, option := range strings.Split(args, ",") {
+			if option = strings.TrimSpace(option); option != "" {
+				v.Options = append(v.Options, option)
 			}
 		}
+		if len(v.Options) == 0 && v.Err == "" {
+			v.Err = "choice needs options"
+		}
+	case VarMatch:
+		pattern, err := regexp.Compile(args)
+		if err != nil {
+			v.Err = "bad pattern: " + err.Error()
+		} else {
+			v.Pattern = pattern
+		}
+	default:
+		return "", "", false
+	}
+
+	// Anything between the type and the default is a typo we can point at
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "|") && v.Err == "" {
+		v.Err = fmt.Sprintf("unexpected %q after type", rest)
+		rest = ""
 	}
+	return typed, rest, true
+}
 
+// matchingParen returns the index of the ")" closing the "(" at s[0], or -1
+// Backslash-escaped parens (as in regexes) don't count
+func matchingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Required reports whether the variable needs a value before the template can be copied
+// (an empty list just repeats nothing)
+func (v TemplateVar) Required() bool {
+	return v.Type != VarBool && v.Type != VarList && v.Default == ""
+}
+
+// Resolve returns the value that fills the variable: the input, else the default
+// (bools are "false" when unset)
+func (v TemplateVar) Resolve(value string) string {
+	if value == "" {
+		value = v.Default
+	}
+	if value == "" && v.Type == VarBool {
+		return "false"
+	}
+	return value
+}
+
+// Validate checks an input value (empty means "use the default")
+func (v TemplateVar) Validate(value string) error {
+	if v.Err != "" {
+		return fmt.Errorf("%s", v.Err)
+	}
+
+	value = v.Resolve(value)
+	if value == "" {
+		if !v.Required() {
+			return nil
+		}
+		return fmt.Errorf("required")
+	}
+
+	switch v.Type {
+	case VarInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+	case VarBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("must be true or false")
+		}
+	case VarChoice:
+		for _, option := range v.Options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of: %s", strings.Join(v.Options, ", "))
+	case VarFile:
+		if _, err := os.Stat(expandPath(value)); err != nil {
+			return fmt.Errorf("file not found")
+		}
+	case VarMatch:
+		if !v.Pattern.MatchString(value) {
+			return fmt.Errorf("must match %s", v.Pattern)
+		}
+	}
+	return nil
+}
+
+// isUserVar reports whether the inside of {{...}} is a variable to ask for
+// Built-ins ({{@date}}...) are resolved, secrets looked up when copying, includes
+// ({{> Card}}) expanded and block tags evaluated
+func isUserVar(raw string) bool {
+	return !isBuiltinVar(raw) && !isSecretVar(raw) && !isIncludeDirective(raw) && !isBlockTag(raw)
+}
+
+// ExtractTemplateVars returns the declarations of all user variables in content
+// Unique by name; the first declaration of a name wins. Names only used by
+// {{#if name}} are bools and names only used by {{#each name}} are lists.
+func ExtractTemplateVars(content string) []TemplateVar {
+	return parseTemplate(content).vars()
+}
+
+// vars returns the declarations of the template's user variables (see ExtractTemplateVars)
+func (p *parsedTemplate) vars() []TemplateVar {
+	var tags []templateToken
+	for _, token := range p.tokens {
+		if token.Tag {
+			tags = append(tags, token)
+		}
+	}
+
+	// Names declared as plain variables keep their declaration wherever it sits
+	declared := make(map[string]bool)
+	for _, tag := range tags {
+		if isUserVar(tag.Text) {
+			declared[ParseTemplateVar(tag.Text).Name] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var vars []TemplateVar
+	for _, tag := range tags {
+		var v TemplateVar
+		if kind, name, ok := parseBlockTag(tag.Text); ok {
+			if name == "" || declared[name] {
+				continue
+			}
+			v = TemplateVar{Name: name, Type: VarBool}
+			if kind == "#each" {
+				v.Type = VarList
+			}
+		} else if isUserVar(tag.Text) {
+			v = ParseTemplateVar(tag.Text)
+		} else {
+			continue
+		}
+
+		if v.Name != "" && !seen[v.Name] {
+			seen[v.Name] = true
+			vars = append(vars, v)
+		}
+	}
+
+	return vars
+}
+
+// ExtractVariables finds all {{variable}} patterns in content
+// Returns unique variable names (without braces, types or defaults)
+func ExtractVariables(content string) []string {
+	var vars []string
+	for _, v := range ExtractTemplateVars(content) {
+		vars = append(vars, v.Name)
+	}
 	return vars
 }
 
@@ -45,36 +298,215 @@ func ParseDefaultValue(variable string) (name, defaultVal string) {
 	return name, defaultVal
 }
 
+// ValidateTemplate returns the first invalid variable (by position) and its error
+// Variables in {{#if}} branches that aren't taken are skipped; a block syntax error
+// is returned with index -1. Returns -1, nil when every variable is valid
+func ValidateTemplate(content string, vars []TemplateVar, values map[string]string) (int, error) {
+	if err := parseTemplate(content).blockErr; err != nil {
+		return -1, err
+	}
+	active := activeTemplateVars(content, values)
+	for i, v := range vars {
+		if !active[v.Name] && (v.Type != VarBool && v.Type != VarList) {
+			continue
+		}
+		if err := v.Validate(values[v.Name]); err != nil {
+			return i, fmt.Errorf("%s: %w", v.Name, err)
+		}
+	}
+	return -1, nil
+}
+
 // FillTemplate replaces all {{variable}} patterns with values from vars map
 // If a variable has no value in the map, it uses the default value from {{var|default}}
 // If no default and no value, keeps the original {{variable}}
+// Built-ins use a value already in the map (keyed by their raw text) or are resolved now
+// Includes must already be expanded (expandCard); leftover {{> ...}} are kept
+// {{#if}} / {{#each}} blocks are evaluated (left as written if they don't parse)
+// and escaped \{{ become {{
 func FillTemplate(content string, vars map[string]string) string {
-	re := regexp.MustCompile(`\{\{([^}]+)\}\}`)
+	return fillTemplate(content, vars, nil)
+}
 
-	result := re.ReplaceAllStringFunc(content, func(match string) string {
-		// Extract variable name (without braces)
-		varWithDefault := strings.TrimSpace(match[2 : len(match)-2]) // Remove {{ and }}
-		varName, defaultVal := ParseDefaultValue(varWithDefault)
+// fillTemplate is FillTemplate with an optional mark func that wraps each filled
+// value (filled=true) and each variable left as written (filled=false)
+func fillTemplate(content string, vars map[string]string, mark func(text string, filled bool) string) string {
+	var b strings.Builder
+	parseTemplate(content).walk(vars, func(node templateNode, item *string) {
+		if node.Kind == "text" {
+			b.WriteString(node.Token.Text)
+			return
+		}
+		value, filled := fillTag(node.Token.Text, vars, item), true
+		if value == "" {
+			// Keep original if no value and no default
+			value, filled = node.Token.Raw, false
+		}
+		// Leftover include and block tags aren't variables
+		if mark != nil && (filled || isUserVar(node.Token.Text) || isBuiltinVar(node.Token.Text) || isSecretVar(node.Token.Text)) {
+			value = mark(value, filled)
+		}
+		b.WriteString(value)
+	})
+	return b.String()
+}
 
-		// Check if user provided a value
-		if val, ok := vars[varName]; ok && val != "" {
-			return val
+// fillTag returns the value of one tag ("" to keep the tag as written)
+func fillTag(raw string, vars map[string]string, item *string) string {
+	switch {
+	case isEachItem(raw):
+		if item != nil {
+			_, filters := splitFilters(raw)
+			return applyFilters(*item, filters)
 		}
+		return ""
 
-		// Fall back to default value
-		if defaultVal != "" {
-			return defaultVal
+	case isIncludeDirective(raw) || isBlockTag(raw):
+		// Left over from a broken include (see expandCard) or block
+		return ""
+
+	case isBuiltinVar(raw):
+		value, ok := vars[raw]
+		if !ok {
+			value, _ = resolveBuiltinVar(raw, time.Now())
 		}
+		return value
 
-		// Keep original if no value and no default
-		return match
-	})
+	case isSecretVar(raw):
+		// Only set (filtered) by fillWithSecrets, or masked for display
+		return vars[raw]
+	}
 
-	return result
+	// User value, then default (unset bools fill as "false"), then ::filters
+	v := ParseTemplateVar(raw)
+	if value := v.Resolve(vars[v.Name]); value != "" {
+		return applyFilters(value, v.Filters)
+	}
+	return ""
 }
 
 // HasTemplateVariables checks if content contains any {{variable}} patterns
+// (block tags like {{#if x}} count too; escaped \{{ don't)
 func HasTemplateVariables(content string) bool {
-	re := regexp.MustCompile(`\{\{[^}]+\}\}`)
-	return re.MatchString(content)
+	return len(templateTags(content)) > 0
+}
+
+// Template form
+
+// focusedTemplateVar returns the declaration of the focused form field, or nil
+func (m *Model) focusedTemplateVar() *TemplateVar {
+	if !m.ShowTemplateForm || m.TemplateFormField < 0 || m.TemplateFormField >= len(m.DetectedDecls) {
+		return nil
+	}
+	return &m.DetectedDecls[m.TemplateFormField]
+}
+
+// editTemplateField applies a key to the focused text-like field of the template form
+// Returns false for choice and bool fields and keys that aren't editing keys
+func (m *Model) editTemplateField(msg tea.KeyMsg) bool {
+	v := m.focusedTemplateVar()
+	if v == nil || v.Type == VarChoice || v.Type == VarBool {
+		return false
+	}
+	in := m.input("var:" + v.Name)
+	if v.Type == VarList {
+		in.PasteJoin = ", " // A pasted column of items becomes a list
+	}
+	value := m.TemplateVars[v.Name]
+	if !in.Update(&value, msg) {
+		return false
+	}
+	m.TemplateVars[v.Name] = value
+	return true
+}
+
+// stepTemplateVar changes a choice (next/previous option) or bool (toggle) field
+// Text-like fields are typed into and left alone
+func (m *Model) stepTemplateVar(v *TemplateVar, delta int) {
+	switch v.Type {
+	case VarBool:
+		if v.Resolve(m.TemplateVars[v.Name]) == "true" {
+			m.TemplateVars[v.Name] = "false"
+		} else {
+			m.TemplateVars[v.Name] = "true"
+		}
+
+	case VarChoice:
+		if len(v.Options) == 0 {
+			return
+		}
+		current := -1
+		for i, option := range v.Options {
+			if option == v.Resolve(m.TemplateVars[v.Name]) {
+				current = i
+				break
+			}
+		}
+		next := 0
+		if current >= 0 {
+			next = (current + delta + len(v.Options)) % len(v.Options)
+		} else if delta < 0 {
+			next = len(v.Options) - 1
+		}
+		m.TemplateVars[v.Name] = v.Options[next]
+	}
+}
+
+// templateFieldErrors returns the validation error of each form field (nil when valid
+// or unused by the {{#if}} branches taken) and any block syntax error
+func (m *Model) templateFieldErrors(card *Card) ([]error, error) {
+	content := m.expandCard(card).Content
+	active := activeTemplateVars(content, m.TemplateVars)
+
+	errs := make([]error, len(m.DetectedDecls))
+	for i, decl := range m.DetectedDecls {
+		if active[decl.Name] || decl.Type == VarBool || decl.Type == VarList {
+			errs[i] = decl.Validate(m.TemplateVars[decl.Name])
+		}
+	}
+	if err := parseTemplate(content).blockErr; err != nil {
+		return errs, err
+	}
+	return errs, nil
+}
+
+// copyFilledTemplate copies the filled template once every variable is valid
+// Otherwise it focuses the first invalid field and says what's wrong
+func (m *Model) copyFilledTemplate(card *Card) tea.Cmd {
+	firstBlock := m.copiesFirstCodeBlock(card)
+	return m.copyFilled(card, func(filled string) string {
+		if firstBlock {
+			return firstCodeBlockOr(filled)
+		}
+		return filled
+	})
+}
+
+// copyFilled is copyFilledTemplate for part of the card: pick gets the filled
+// template (secrets resolved) and returns the text to copy
+func (m *Model) copyFilled(card *Card, pick func(filled string) string) tea.Cmd {
+	content := m.expandCard(card).Content
+	if index, err := ValidateTemplate(content, m.DetectedDecls, m.TemplateVars); err != nil {
+		if index >= 0 {
+			m.TemplateFormField = index
+		}
+		m.ReloadMessage = "⚠ Can't copy yet - " + err.Error()
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+	if err := m.builtinsError(); err != nil {
+		m.ReloadMessage = "⚠ Can't copy yet - " + err.Error()
+		m.ReloadMessageTime = time.Now()
+		return nil
+	}
+	m.VarHistoryIndex = -1
+	var copyCmd tea.Cmd
+	if m.hasSecretVars(card) {
+		// Secrets are looked up by the command itself and never stored
+		copyCmd = copyWithSecrets(content, m.TemplateVars, m.Config.Secrets, pick, []string{card.ID})
+	} else {
+		copyCmd = copyCardsToClipboard(pick(FillTemplate(content, m.TemplateVars)), []string{card.ID}, true)
+	}
+	// Fresh built-ins for the next copy (a new {{@uuid}}, the current time...)
+	return tea.Batch(copyCmd, m.recordVarHistory(card), m.refreshBuiltinVars(card))
 }
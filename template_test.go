@@ -2,6 +2,7 @@ package main
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -31,6 +32,16 @@ func TestExtractVariables(t *testing.T) {
 			content:  "{{name}} says hello to {{name}}",
 			expected: []string{"name"},
 		},
+		{
+			name:     "typed variables",
+			content:  "deploy --env {{env:choice(dev,prod)}} --port {{port:int|3000}} {{tag:match(^v\\d{1,3}$)}}",
+			expected: []string{"env", "port", "tag"},
+		},
+		{
+			name:     "unknown type is part of the name",
+			content:  "{{Note: call first}}",
+			expected: []string{"Note: call first"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,6 +117,36 @@ func TestFillTemplate(t *testing.T) {
 			vars:     map[string]string{},
 			expected: "plain text",
 		},
+		{
+			name:     "typed variables",
+			content:  "--env={{env:choice(dev,prod)}} --port={{port:int|3000}} --force={{force:bool}}",
+			vars:     map[string]string{"env": "prod"},
+			expected: "--env=prod --port=3000 --force=false",
+		},
+		{
+			name:     "missing typed value kept",
+			content:  "cat {{path:file}}",
+			vars:     map[string]string{},
+			expected: "cat {{path:file}}",
+		},
+		{
+			name:     "escaped braces stay literal",
+			content:  `image: \{{ .Values.image }} tag: {{tag}}`,
+			vars:     map[string]string{"tag": "v1"},
+			expected: "image: {{ .Values.image }} tag: v1",
+		},
+		{
+			name:     "unclosed tag stays literal",
+			content:  "{{name}} {{oops\n{{name}}",
+			vars:     map[string]string{"name": "x"},
+			expected: "x {{oops\nx",
+		},
+		{
+			name:     "braces inside a value are not re-expanded",
+			content:  "{{a}} {{b}}",
+			vars:     map[string]string{"a": "{{b}}", "b": "B"},
+			expected: "{{b}} B",
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +168,9 @@ func TestHasTemplateVariables(t *testing.T) {
 		{"plain text", false},
 		{"{{var1}} and {{var2}}", true},
 		{"{single brace}", false},
+		{`\{{escaped}}`, false},
+		{"{{unclosed", false},
+		{"{{ }}", false},
 	}
 
 	for _, tt := range tests {
@@ -138,3 +182,292 @@ func TestHasTemplateVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTemplateVar(t *testing.T) {
+	tests := []struct {
+		raw         string
+		wantName    string
+		wantType    string
+		wantDefault string
+		wantOptions []string
+		wantErr     bool
+	}{
+		{"name", "name", VarText, "", nil, false},
+		{"port|3000", "port", VarText, "3000", nil, false},
+		{"port:int|3000", "port", VarInt, "3000", nil, false},
+		{" env : choice( dev, staging ,prod ) | dev ", "env", VarChoice, "dev", []string{"dev", "staging", "prod"}, false},
+		{"confirm:bool", "confirm", VarBool, "", nil, false},
+		{"path:FILE", "path", VarFile, "", nil, false},
+		{"tag:match(^(v|r)\\d+$)|v1", "tag", VarMatch, "v1", nil, false},
+		{"tag:match(^v(\\d+$)", "tag", VarMatch, "", nil, true},
+		{"tag:match([)", "tag", VarMatch, "", nil, true},
+		{"env:choice()", "env", VarChoice, "", nil, true},
+		{"port:int extra", "port", VarInt, "", nil, true},
+		{"Note: call first|x", "Note: call first", VarText, "x", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			v := ParseTemplateVar(tt.raw)
+			if v.Name != tt.wantName || v.Type != tt.wantType || v.Default != tt.wantDefault {
+				t.Errorf("ParseTemplateVar(%q) = (%q, %q, %q), want (%q, %q, %q)",
+					tt.raw, v.Name, v.Type, v.Default, tt.wantName, tt.wantType, tt.wantDefault)
+			}
+			if tt.wantOptions != nil && !reflect.DeepEqual(v.Options, tt.wantOptions) {
+				t.Errorf("ParseTemplateVar(%q) options = %v, want %v", tt.raw, v.Options, tt.wantOptions)
+			}
+			if (v.Err != "") != tt.wantErr {
+				t.Errorf("ParseTemplateVar(%q) err = %q, wantErr %v", tt.raw, v.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateVarValidate(t *testing.T) {
+	existing := t.TempDir()
+
+	tests := []struct {
+		decl    string
+		value   string
+		wantErr bool
+	}{
+		{"name", "", true},
+		{"name", "x", false},
+		{"name|default", "", false},
+		{"port:int", "8080", false},
+		{"port:int", "80a", true},
+		{"port:int|abc", "", true},
+		{"env:choice(dev,prod)", "prod", false},
+		{"env:choice(dev,prod)", "qa", true},
+		{"env:choice(dev,prod)|dev", "", false},
+		{"confirm:bool", "", false},
+		{"confirm:bool", "true", false},
+		{"confirm:bool", "maybe", true},
+		{"path:file", existing, false},
+		{"path:file", existing + "/missing", true},
+		{"tag:match(^v\\d+$)", "v12", false},
+		{"tag:match(^v\\d+$)", "12", true},
+		{"tag:match([)", "x", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.decl+"="+tt.value, func(t *testing.T) {
+			err := ParseTemplateVar(tt.decl).Validate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	content := "{{env:choice(dev,prod)|dev}} {{port:int}} {{name}}"
+	vars := ExtractTemplateVars(content)
+
+	index, err := ValidateTemplate(content, vars, map[string]string{"port": "x", "name": "a"})
+	if index != 1 || err == nil {
+		t.Errorf("ValidateTemplate() = (%d, %v), want first invalid at 1", index, err)
+	}
+
+	if index, err := ValidateTemplate(content, vars, map[string]string{"port": "80", "name": "a"}); index != -1 || err != nil {
+		t.Errorf("ValidateTemplate() = (%d, %v), want valid", index, err)
+	}
+}
+
+func TestStepTemplateVar(t *testing.T) {
+	m := testModel(nil)
+	env := ParseTemplateVar("env:choice(dev,staging,prod)|staging")
+	force := ParseTemplateVar("force:bool")
+
+	want := []string{"prod", "dev", "staging"}
+	for _, option := range want {
+		m.stepTemplateVar(&env, 1)
+		if got := m.TemplateVars["env"]; got != option {
+			t.Fatalf("stepTemplateVar(+1) = %q, want %q", got, option)
+		}
+	}
+	m.stepTemplateVar(&env, -1)
+	if got := m.TemplateVars["env"]; got != "dev" {
+		t.Errorf("stepTemplateVar(-1) = %q, want dev", got)
+	}
+
+	m.stepTemplateVar(&force, 1)
+	if got := m.TemplateVars["force"]; got != "true" {
+		t.Errorf("bool toggle = %q, want true", got)
+	}
+	m.stepTemplateVar(&force, 1)
+	if got := m.TemplateVars["force"]; got != "false" {
+		t.Errorf("bool toggle = %q, want false", got)
+	}
+}
+
+func TestTokenizeTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantTags []string
+		wantErrs []string
+	}{
+		{"plain", "no tags here", nil, nil},
+		{"tags", "a {{x}} b {{ y|z }}", []string{"x", "y|z"}, nil},
+		{"regex quantifier", "{{tag:match(^v\\d{1,3}$)}}", []string{"tag:match(^v\\d{1,3}$)"}, nil},
+		{"single brace inside", "{{a}b}}", []string{"a}b"}, nil},
+		{"escaped", `\{{x}} {{y}}`, []string{"y"}, nil},
+		{"unclosed at end", "ok {{x", nil, []string{"1:4: unclosed {{"}},
+		{"unclosed before newline", "a\n é {{x\n}}", nil, []string{"2:4: unclosed {{ (tags end on the same line with }})"}},
+		{"empty tag", "{{ }} {{x}}", []string{"x"}, []string{"1:1: empty {{ }}"}},
+		{"nested open", "{{a {{b}}", []string{"b"}, []string{"1:1: unclosed {{ (only one level of { } is allowed inside a tag)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, errs := tokenizeTemplate(tt.content)
+
+			var tags []string
+			var raw strings.Builder
+			for _, token := range tokens {
+				if token.Tag {
+					tags = append(tags, token.Text)
+				}
+				raw.WriteString(token.Raw)
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags = %q, want %q", tags, tt.wantTags)
+			}
+			if raw.String() != tt.content {
+				t.Errorf("tokens rebuild %q, want %q", raw.String(), tt.content)
+			}
+
+			var gotErrs []string
+			for _, err := range errs {
+				gotErrs = append(gotErrs, err.Error())
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("errors = %q, want %q", gotErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestTemplateEscaping(t *testing.T) {
+	content := `helm: \{{ .Release.Name }}, go: \{{- range . }}`
+	if vars := ExtractVariables(content); len(vars) != 0 {
+		t.Errorf("ExtractVariables() = %v, want none", vars)
+	}
+	want := "helm: {{ .Release.Name }}, go: {{- range . }}"
+	if got := unescapeTemplate(content); got != want {
+		t.Errorf("unescapeTemplate() = %q, want %q", got, want)
+	}
+	if got := unescapeTemplate(escapeTemplate(want)); got != want {
+		t.Errorf("escape round trip = %q, want %q", got, want)
+	}
+}
+
+func TestLintTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"clean", "{{name}} {{#if x}}{{port:int}}{{/if}} {{@date}} {{> Other}} {{#each l}}{{this}}{{/each}}", nil},
+		{"escaped", `\{{ .Values.x }}`, nil},
+		{"unclosed", "line one\n  {{name", []string{"2:3: unclosed {{"}},
+		{"go template", "x\n{{ .Values.image }}", []string{"2:1: {{ .Values.image }} looks like a Go template action - write \\{{ to keep it literal"}},
+		{"bad declaration", "{{env:choice()}}", []string{"1:1: env: choice needs options"}},
+		{"conflicting types", "{{port:int}} {{port:bool}}", []string{"1:14: 'port' is already declared as int; this bool type is ignored"}},
+		{"unknown built-in", "{{@nope}} {{@env}}", []string{"1:1: unknown built-in @nope", "1:11: @env needs a variable name, e.g. {{@env:HOME}}"}},
+		{"this outside each", "{{this}}", []string{"1:1: {{this}} outside {{#each}}"}},
+		{"unknown block", "{{#unless x}}", []string{"1:1: unknown block tag {{#unless x}} (use #if, #each, else, /if, /each)"}},
+		{"block error", "{{#if x}}", []string{"1:1: {{#if x}} is never closed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, problem := range lintTemplate(tt.content) {
+				got = append(got, problem.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintTemplate(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+// Fuzz tests
+
+var templateFuzzSeeds = []string{
+	"docker run -p {{port}}:{{port}} {{image|nginx}}",
+	"{{env:choice(dev,prod)|dev}} {{tag:match(^v\\d{1,3}$)}}",
+	`\{{ .Values.image }} {{x`,
+	"{{#if a}}A{{else}}B{{/if}}{{#each l}}-{{this}}{{/each}}",
+	"{{#if a}}{{/each}}{{",
+	"{{{x}}}",
+	"}}{{ }}{{\n}}",
+	"{{@date:2006}} {{> Other}}",
+}
+
+func FuzzTokenizeTemplate(f *testing.F) {
+	for _, seed := range templateFuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, content string) {
+		tokens, errs := tokenizeTemplate(content)
+
+		// Tokens cover the content exactly, in order
+		var raw strings.Builder
+		for _, token := range tokens {
+			if token.Pos != raw.Len() {
+				t.Fatalf("token %q at %d, want %d", token.Raw, token.Pos, raw.Len())
+			}
+			raw.WriteString(token.Raw)
+			if token.Tag && (token.Text == "" || strings.Contains(token.Raw, "\n")) {
+				t.Fatalf("bad tag %q", token.Raw)
+			}
+		}
+		if raw.String() != content {
+			t.Fatalf("tokens rebuild %q, want %q", raw.String(), content)
+		}
+		for _, err := range errs {
+			if err.Line < 1 || err.Col < 1 {
+				t.Fatalf("error without position: %v", err)
+			}
+		}
+
+		// Escaping makes any text literal
+		if got := unescapeTemplate(escapeTemplate(content)); got != content {
+			t.Fatalf("escape round trip = %q, want %q", got, content)
+		}
+		if HasTemplateVariables(escapeTemplate(content)) {
+			t.Fatalf("escaped %q still has tags", content)
+		}
+	})
+}
+
+func FuzzFillTemplate(f *testing.F) {
+	for _, seed := range templateFuzzSeeds {
+		f.Add(seed, "value")
+	}
+	f.Fuzz(func(t *testing.T, content, value string) {
+		content = strings.ReplaceAll(content, "{{@", "{{") // Built-ins would shell out
+		vars := map[string]string{}
+		for _, v := range ExtractTemplateVars(content) {
+			if strings.Contains(v.Name, "\n") {
+				t.Fatalf("variable name %q spans lines", v.Name)
+			}
+			vars[v.Name] = value
+		}
+
+		// Must not panic, with or without values
+		FillTemplate(content, vars)
+		FillTemplate(content, nil)
+		lintTemplate(content)
+
+		// Content without tags fills to itself (escapes resolved)
+		if !HasTemplateVariables(content) {
+			if got, want := FillTemplate(content, vars), unescapeTemplate(content); got != want {
+				t.Fatalf("FillTemplate(%q) = %q, want %q", content, got, want)
+			}
+		}
+	})
+}
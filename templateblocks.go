@@ -0,0 +1,220 @@
+package main
+
+import (
+	"strings"
+)
+
+// templateblocks.go - Conditional and Repeated Template Sections
+// Purpose: Parse {{#if var}}...{{else}}...{{/if}} and {{#each list}}...{{/each}} into a tree
+//
+// A variable used only as an {{#if}} condition becomes a checkbox; one used only in
+// {{#each}} becomes a list field (comma- or newline-separated), and {{this}} inside
+// the block is the item. If the blocks don't parse, block tags are left as written.
+
+// parseBlockTag splits a block tag ("#if name", "else", "/each") into kind and name
+func parseBlockTag(text string) (kind string, name string, ok bool) {
+	kind, name, _ = strings.Cut(strings.TrimSpace(text), " ")
+	name = strings.TrimSpace(name)
+	switch kind {
+	case "#if", "#each":
+		return kind, name, true
+	case "else", "/if", "/each":
+		return kind, "", name == ""
+	}
+	return "", "", false
+}
+
+// isBlockTag reports whether the inside of {{...}} is a block tag or {{this}}
+func isBlockTag(raw string) bool {
+	_, _, ok := parseBlockTag(raw)
+	return ok || isEachItem(raw)
+}
+
+// isEachItem reports whether the inside of {{...}} is the {{#each}} item ({{this}}, {{this::upper}})
+func isEachItem(raw string) bool {
+	name, _ := splitFilters(strings.TrimSpace(raw))
+	return name == "this"
+}
+
+// templateNode is a piece of a parsed template: text, a tag, an if block or an each block
+type templateNode struct {
+	Kind  string        // "text", "tag", "#if" or "#each"
+	Token templateToken // Text and tag nodes: the token; blocks: the opening tag
+	Name  string        // Blocks: the variable name
+	Body  []templateNode
+	Else  []templateNode
+}
+
+// parsedTemplate is tokenized and parsed card content
+type parsedTemplate struct {
+	tokens   []templateToken
+	nodes    []templateNode  // Block tree (flat when the blocks don't parse)
+	errors   []TemplateError // Tokenizer errors
+	blockErr *TemplateError  // First block structure error, if any
+}
+
+// parseTemplate tokenizes content and parses its blocks
+func parseTemplate(content string) *parsedTemplate {
+	p := &parsedTemplate{}
+	p.tokens, p.errors = tokenizeTemplate(content)
+
+	nodes, err := parseTemplateBlocks(content, p.tokens)
+	if err != nil {
+		p.blockErr = err
+		// Blocks are left as written
+		nodes = nil
+		for _, token := range p.tokens {
+			kind := "text"
+			if token.Tag {
+				kind = "tag"
+			}
+			nodes = append(nodes, templateNode{Kind: kind, Token: token})
+		}
+	}
+	p.nodes = nodes
+	return p
+}
+
+// parseTemplateBlocks builds the block tree from tokens
+func parseTemplateBlocks(content string, tokens []templateToken) ([]templateNode, *TemplateError) {
+	type frame struct {
+		node   templateNode
+		inElse bool
+	}
+	var stack []*frame
+	var root []templateNode
+
+	// appendNode adds to the innermost open block (or the top level)
+	appendNode := func(node templateNode) {
+		if len(stack) == 0 {
+			root = append(root, node)
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.inElse {
+			top.node.Else = append(top.node.Else, node)
+		} else {
+			top.node.Body = append(top.node.Body, node)
+		}
+	}
+	errorAt := func(token templateToken, format string, args ...any) *TemplateError {
+		err := templateErrorAt(content, token.Pos, format, args...)
+		return &err
+	}
+
+	for _, token := range tokens {
+		kind, name, ok := parseBlockTag(token.Text)
+		if !token.Tag || !ok {
+			nodeKind := "text"
+			if token.Tag {
+				nodeKind = "tag"
+			}
+			appendNode(templateNode{Kind: nodeKind, Token: token})
+			continue
+		}
+
+		switch kind {
+		case "#if", "#each":
+			if name == "" {
+				return nil, errorAt(token, "{{%s}} needs a variable name", kind)
+			}
+			stack = append(stack, &frame{node: templateNode{Kind: kind, Token: token, Name: name}})
+
+		case "else":
+			if len(stack) == 0 || stack[len(stack)-1].inElse {
+				return nil, errorAt(token, "{{else}} without {{#if}} or {{#each}}")
+			}
+			stack[len(stack)-1].inElse = true
+
+		case "/if", "/each":
+			open := "#" + kind[1:]
+			if len(stack) == 0 || stack[len(stack)-1].node.Kind != open {
+				return nil, errorAt(token, "{{%s}} without {{%s}}", kind, open)
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			appendNode(top.node)
+		}
+	}
+
+	if len(stack) > 0 {
+		top := stack[len(stack)-1].node
+		return nil, errorAt(top.Token, "{{%s %s}} is never closed", top.Kind, top.Name)
+	}
+	return root, nil
+}
+
+// isTruthy reports whether an {{#if}} condition value counts as true
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0", "no", "off":
+		return false
+	}
+	return true
+}
+
+// splitList splits a list value on commas and newlines, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// walk visits the nodes of the branches taken with the given values
+// visit gets each text or tag node and the current {{#each}} item (nil outside one)
+func (p *parsedTemplate) walk(vars map[string]string, visit func(node templateNode, item *string)) {
+	// Conditions and lists resolve like any variable (a {{name|default}} elsewhere counts)
+	decls := make(map[string]TemplateVar)
+	for _, decl := range p.vars() {
+		decls[decl.Name] = decl
+	}
+	value := func(name string) string {
+		if decl, ok := decls[name]; ok {
+			return decl.Resolve(vars[name])
+		}
+		return vars[name]
+	}
+
+	var walkNodes func(nodes []templateNode, item *string)
+	walkNodes = func(nodes []templateNode, item *string) {
+		for _, node := range nodes {
+			switch node.Kind {
+			case "#if":
+				if isTruthy(value(node.Name)) {
+					walkNodes(node.Body, item)
+				} else {
+					walkNodes(node.Else, item)
+				}
+
+			case "#each":
+				items := splitList(value(node.Name))
+				if len(items) == 0 {
+					walkNodes(node.Else, item)
+				}
+				for i := range items {
+					walkNodes(node.Body, &items[i])
+				}
+
+			default:
+				visit(node, item)
+			}
+		}
+	}
+	walkNodes(p.nodes, nil)
+}
+
+// activeTemplateVars returns the variables that end up in the filled template
+// (those inside an {{#if}} branch that isn't taken don't need a value)
+func activeTemplateVars(content string, vars map[string]string) map[string]bool {
+	active := make(map[string]bool)
+	parseTemplate(content).walk(vars, func(node templateNode, item *string) {
+		if node.Kind == "tag" && isUserVar(node.Token.Text) {
+			active[ParseTemplateVar(node.Token.Text).Name] = true
+		}
+	})
+	return active
+}
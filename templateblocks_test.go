@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFillTemplateBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		vars    map[string]string
+		want    string
+	}{
+		{"if true", "a{{#if tests}} +tests{{/if}}", map[string]string{"tests": "true"}, "a +tests"},
+		{"if false", "a{{#if tests}} +tests{{/if}}", map[string]string{"tests": "false"}, "a"},
+		{"if unset", "a{{#if tests}} +tests{{/if}}", map[string]string{}, "a"},
+		{"else branch", "{{#if prod}}live{{else}}dry run{{/if}}", map[string]string{}, "dry run"},
+		{"text condition", "{{#if ticket}}Ticket: {{ticket}}{{/if}}", map[string]string{"ticket": "ABC-1"}, "Ticket: ABC-1"},
+		{"condition default", "{{#if mode}}x{{/if}} {{mode|on}}", map[string]string{}, "x on"},
+		{"falsy words", "{{#if v}}x{{/if}}", map[string]string{"v": "No"}, ""},
+		{"each comma list", "docker run{{#each ports}} -p {{this}}:{{this}}{{/each}}", map[string]string{"ports": "80, 443"}, "docker run -p 80:80 -p 443:443"},
+		{"each newline list", "{{#each files}}- {{ this }}\n{{/each}}", map[string]string{"files": "a.go\n\nb.go"}, "- a.go\n- b.go\n"},
+		{"each empty else", "{{#each items}}{{this}}{{else}}none{{/each}}", map[string]string{}, "none"},
+		{"nested", "{{#each hosts}}{{#if verbose}}-v {{/if}}{{this}};{{/each}}", map[string]string{"hosts": "a,b", "verbose": "1"}, "-v a;-v b;"},
+		{"variables inside each", "{{#each names}}{{greeting|hi}} {{this}}. {{/each}}", map[string]string{"names": "x,y"}, "hi x. hi y. "},
+		{"unclosed block left alone", "{{#if a}}x {{name}}", map[string]string{"a": "true", "name": "n"}, "{{#if a}}x n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FillTemplate(tt.content, tt.vars); got != tt.want {
+				t.Errorf("FillTemplate(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateBlockErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"{{#if a}}x", "1:1: {{#if a}} is never closed"},
+		{"x{{/if}}", "1:2: {{/if}} without {{#if}}"},
+		{"{{#if a}}x{{/each}}", "1:11: {{/each}} without {{#each}}"},
+		{"ok\n  {{else}}", "2:3: {{else}} without {{#if}} or {{#each}}"},
+		{"{{#if a}}x{{else}}y{{else}}z{{/if}}", "1:20: {{else}} without {{#if}} or {{#each}}"},
+		{"{{#each}}x{{/each}}", "1:1: {{#each}} needs a variable name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			err := parseTemplate(tt.content).blockErr
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("parseTemplate(%q) block error = %v, want %q", tt.content, err, tt.want)
+			}
+			// Block tags are left as written
+			if got := FillTemplate(tt.content, nil); got != tt.content {
+				t.Errorf("FillTemplate(%q) = %q, want content unchanged", tt.content, got)
+			}
+		})
+	}
+}
+
+func TestBlockVariables(t *testing.T) {
+	content := "{{#if with_tests}}Tests for {{pkg}}{{/if}}{{#each ports}}-p {{this}}{{/each}}{{#if pkg}}!{{/if}}"
+	vars := ExtractTemplateVars(content)
+
+	want := []struct{ name, typ string }{
+		{"with_tests", VarBool},
+		{"pkg", VarText}, // Plain use wins over the {{#if pkg}} condition
+		{"ports", VarList},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("ExtractTemplateVars() = %+v, want %d vars", vars, len(want))
+	}
+	for i, w := range want {
+		if vars[i].Name != w.name || vars[i].Type != w.typ {
+			t.Errorf("var %d = %s (%s), want %s (%s)", i, vars[i].Name, vars[i].Type, w.name, w.typ)
+		}
+	}
+
+	// pkg only needs a value when the tests section is included
+	if index, err := ValidateTemplate(content, vars, map[string]string{}); index != -1 || err != nil {
+		t.Errorf("ValidateTemplate() without tests = (%d, %v), want valid", index, err)
+	}
+	if index, err := ValidateTemplate(content, vars, map[string]string{"with_tests": "true"}); index != 1 || err == nil {
+		t.Errorf("ValidateTemplate() with tests = (%d, %v), want pkg required", index, err)
+	}
+	if _, err := ValidateTemplate("{{#if a}}", nil, nil); err == nil {
+		t.Error("ValidateTemplate() with an unclosed block should fail")
+	}
+
+	if !HasTemplateVariables("{{#each x}}{{/each}}") {
+		t.Error("HasTemplateVariables() should see block tags")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// templatelint.go - Template Linting
+// Purpose: Find template mistakes (unclosed tags, bad declarations, stray block tags...)
+// and report them with line:column for the detail view's lint panel
+
+// maxLintShown is how many problems the detail view lists
+const maxLintShown = 3
+
+// goTemplateWords are Go template / Helm actions that aren't CellBlocks tags
+var goTemplateWords = map[string]bool{
+	"range": true, "end": true, "with": true, "define": true, "template": true,
+	"include": true, "block": true, "toYaml": true, "nindent": true, "indent": true,
+}
+
+// lintTemplate returns the problems in a template, in order of position
+func lintTemplate(content string) []TemplateError {
+	if !strings.Contains(content, "{{") {
+		return nil
+	}
+	p := parseTemplate(content)
+	problems := append([]TemplateError(nil), p.errors...)
+	if p.blockErr != nil {
+		problems = append(problems, *p.blockErr)
+	}
+	add := func(tag templateToken, format string, args ...any) {
+		problems = append(problems, templateErrorAt(content, tag.Pos, format, args...))
+	}
+
+	// {{this}} only means something inside {{#each}}
+	var checkThis func(nodes []templateNode, inEach bool)
+	checkThis = func(nodes []templateNode, inEach bool) {
+		for _, node := range nodes {
+			switch node.Kind {
+			case "tag":
+				if isEachItem(node.Token.Text) && !inEach {
+					add(node.Token, "{{this}} outside {{#each}}")
+				}
+			case "#if":
+				checkThis(node.Body, inEach)
+				checkThis(node.Else, inEach)
+			case "#each":
+				checkThis(node.Body, true)
+				checkThis(node.Else, inEach)
+			}
+		}
+	}
+	if p.blockErr == nil {
+		checkThis(p.nodes, false)
+	}
+
+	declared := make(map[string]TemplateVar)
+	for _, tag := range p.tokens {
+		if !tag.Tag {
+			continue
+		}
+		text := tag.Text
+		firstWord, _, _ := strings.Cut(text, " ")
+
+		switch {
+		case isIncludeDirective(text):
+			if strings.TrimSpace(strings.TrimPrefix(text, includePrefix)) == "" {
+				add(tag, "{{>}} needs a card title or id:...")
+			}
+
+		case isBuiltinVar(text):
+			name, arg := splitBuiltin(text)
+			_, filters := splitFilters(text)
+			if err := checkFilters(filters); err != nil {
+				add(tag, "%s", err)
+			}
+			if !builtinNames[name] {
+				add(tag, "unknown built-in @%s", name)
+			} else if name == "env" && arg == "" {
+				add(tag, "@env needs a variable name, e.g. {{@env:HOME}}")
+			}
+
+		case isSecretVar(text):
+			name, filters := parseSecretTag(text)
+			if err := checkFilters(filters); err != nil {
+				add(tag, "%s", err)
+			}
+			if name == "" {
+				add(tag, "{{secret:}} needs a name, e.g. {{secret:db_password}}")
+			}
+
+		case isBlockTag(text):
+			// Checked by the block parser and checkThis
+
+		case strings.HasPrefix(text, "#") || strings.HasPrefix(text, "/"):
+			add(tag, "unknown block tag {{%s}} (use #if, #each, else, /if, /each)", text)
+
+		case strings.HasPrefix(text, ".") || strings.HasPrefix(text, "-") || strings.HasPrefix(text, "$") ||
+			goTemplateWords[firstWord]:
+			add(tag, "%s looks like a Go template action - write \\{{ to keep it literal", tag.Raw)
+
+		default:
+			v := ParseTemplateVar(text)
+			if v.Err != "" {
+				add(tag, "%s: %s", v.Name, v.Err)
+			}
+			if first, ok := declared[v.Name]; !ok {
+				declared[v.Name] = v
+			} else if v.Type != VarText && v.Type != first.Type {
+				add(tag, "'%s' is already declared as %s; this %s type is ignored", v.Name, first.Type, v.Type)
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Col < problems[j].Col
+	})
+	return problems
+}
+
+// renderLintPanel lists a card's template problems ("" when there are none)
+func renderLintPanel(problems []TemplateError, width int) string {
+	if len(problems) == 0 {
+		return ""
+	}
+
+	noun := "problem"
+	if len(problems) > 1 {
+		noun = "problems"
+	}
+	lines := []string{styleError.Render(fmt.Sprintf("⚠ %d template %s", len(problems), noun))}
+	for i, problem := range problems {
+		if i == maxLintShown {
+			lines = append(lines, styleSubtle.Render(fmt.Sprintf("  …and %d more", len(problems)-maxLintShown)))
+			break
+		}
+		lines = append(lines, "  "+truncate(problem.Error(), max(20, width-2)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lintPanelHeight is the number of lines renderLintPanel takes
+func lintPanelHeight(problems []TemplateError) int {
+	if len(problems) == 0 {
+		return 0
+	}
+	height := 1 + min(len(problems), maxLintShown)
+	if len(problems) > maxLintShown {
+		height++
+	}
+	return height
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// templatetokens.go - Template Tokenizer
+// Purpose: Split card content into literal text and {{...}} tags, the one place
+// that decides what a tag is
+//
+// Rules:
+//   - A tag is "{{" ... "}}" on a single line; one level of {} is allowed inside
+//     (regex quantifiers like {2,3} in match(...))
+//   - "\{{" is a literal "{{" (for Go templates, Helm charts, Jinja...)
+//   - A "{{" that never closes on its line, or an empty "{{ }}", stays literal text
+//     and is reported as an error with its line:column
+
+// templateToken is literal text or a {{...}} tag
+type templateToken struct {
+	Tag  bool   // {{...}} tag (else literal text)
+	Text string // Text: the literal text (escapes resolved); tag: trimmed inside of {{ }}
+	Raw  string // Source text of the token
+	Pos  int    // Byte offset of the token in the content
+}
+
+// TemplateError is a problem at a position in a template
+type TemplateError struct {
+	Line, Col int // 1-based; Col counts characters, not bytes
+	Msg       string
+}
+
+func (e TemplateError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// templateErrorAt builds a TemplateError for a byte offset in content
+func templateErrorAt(content string, pos int, format string, args ...any) TemplateError {
+	before := content[:pos]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return TemplateError{Line: line, Col: utf8.RuneCountInString(before[lineStart:]) + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// tokenizeTemplate splits content into text and tag tokens
+// Concatenating the Raw of every token gives back content exactly
+func tokenizeTemplate(content string) ([]templateToken, []TemplateError) {
+	var tokens []templateToken
+	var errs []TemplateError
+
+	var text strings.Builder // Pending literal text (escapes resolved)
+	textStart := 0
+	flushText := func(end int) {
+		if end > textStart {
+			tokens = append(tokens, templateToken{Text: text.String(), Raw: content[textStart:end], Pos: textStart})
+		}
+		text.Reset()
+		textStart = end
+	}
+
+	for i := 0; i < len(content); {
+		switch {
+		case strings.HasPrefix(content[i:], `\{{`):
+			// Escaped - a literal "{{"
+			text.WriteString("{{")
+			i += 3
+
+		case strings.HasPrefix(content[i:], "{{"):
+			end, problem := scanTag(content, i)
+			if problem != "" {
+				errs = append(errs, templateErrorAt(content, i, "%s", problem))
+				text.WriteString("{{")
+				i += 2
+				continue
+			}
+			flushText(i)
+			raw := content[i:end]
+			tokens = append(tokens, templateToken{Tag: true, Text: strings.TrimSpace(raw[2 : len(raw)-2]), Raw: raw, Pos: i})
+			textStart = end
+			i = end
+
+		default:
+			text.WriteByte(content[i])
+			i++
+		}
+	}
+	flushText(len(content))
+
+	return tokens, errs
+}
+
+// scanTag finds the end (just past "}}") of the tag opening at content[start]
+// Returns a description of the problem when it isn't a well-formed tag
+func scanTag(content string, start int) (int, string) {
+	depth := 0
+	for i := start + 2; i < len(content); i++ {
+		switch content[i] {
+		case '\n':
+			return 0, "unclosed {{ (tags end on the same line with }})"
+		case '{':
+			if depth == 1 {
+				return 0, "unclosed {{ (only one level of { } is allowed inside a tag)"
+			}
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			} else if i+1 < len(content) && content[i+1] == '}' {
+				if strings.TrimSpace(content[start+2:i]) == "" {
+					return 0, "empty {{ }}"
+				}
+				return i + 2, ""
+			}
+		}
+	}
+	return 0, "unclosed {{"
+}
+
+// templateTags returns just the tags of content
+func templateTags(content string) []templateToken {
+	if !strings.Contains(content, "{{") {
+		return nil
+	}
+	tokens, _ := tokenizeTemplate(content)
+	var tags []templateToken
+	for _, token := range tokens {
+		if token.Tag {
+			tags = append(tags, token)
+		}
+	}
+	return tags
+}
+
+// unescapeTemplate turns "\{{" back into "{{" and leaves tags as written
+// Used when a card is copied without filling its template
+func unescapeTemplate(content string) string {
+	if !strings.Contains(content, `\{{`) {
+		return content
+	}
+	tokens, _ := tokenizeTemplate(content)
+	var b strings.Builder
+	for _, token := range tokens {
+		if token.Tag {
+			b.WriteString(token.Raw)
+		} else {
+			b.WriteString(token.Text)
+		}
+	}
+	return b.String()
+}
+
+// escapeTemplate makes text safe to put into a template (every "{{" becomes "\{{")
+func escapeTemplate(text string) string {
+	return strings.ReplaceAll(text, "{{", `\{{`)
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// templatize.go - Templatize Assistant
+// Purpose: Turn a pasted literal command into a template (press z)
+//
+// The card is scanned for likely parameters - URLs, UUIDs, IPs, ports, paths and quoted
+// strings - and each distinct value is offered as a {{name|original}} placeholder.
+// Suggestions are accepted or rejected one by one and the card is saved as one
+// undoable edit.
+
+// Suggestion states
+const (
+	suggestPending = iota
+	suggestAccepted
+	suggestRejected
+)
+
+// templatizeSuggestion is one literal value that could become a variable
+type templatizeSuggestion struct {
+	Original string   // The literal value
+	Kind     string   // "url", "uuid", "ip", "port", "path" or "string"
+	Name     string   // Proposed variable name (r renames)
+	Spans    [][2]int // Byte ranges of every occurrence in the content
+	State    int      // suggestPending, suggestAccepted or suggestRejected
+}
+
+// TemplatizeSession is the review of one card's suggestions
+type TemplatizeSession struct {
+	CardID      string
+	Title       string
+	Content     string // Content the suggestions were made for
+	Suggestions []templatizeSuggestion
+	Cursor      int
+	Renaming    bool   // The current suggestion's name is being edited
+	NameInput   string // Name typed while renaming
+	NameError   string // Why the typed name can't be used
+	ReturnMode  ViewMode
+}
+
+// templatizePattern finds one kind of parameter; group is the submatch that is the value
+type templatizePattern struct {
+	kind  string
+	re    *regexp.Regexp
+	group int
+}
+
+// templatizePatterns are tried in order; an earlier match wins over an overlapping later one
+// (a URL's host:port stays part of the URL, a quoted path is a path)
+var templatizePatterns = []templatizePattern{
+	{kind: "url", re: regexp.MustCompile(`https?://[^\s"'<>()\[\]{}|]+`)},
+	{kind: "uuid", re: regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)},
+	{kind: "ip", re: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)},
+	{kind: "port", re: regexp.MustCompile(`(?:--port[= ]|-p )(\d{2,5})\b`), group: 1},
+	{kind: "port", re: regexp.MustCompile(`-p \d{2,5}:(\d{2,5})\b`), group: 1},
+	{kind: "port", re: regexp.MustCompile(`\b(?:localhost|\d{1,3}(?:\.\d{1,3}){3}|[A-Za-z][\w-]*(?:\.[\w-]+)+):(\d{2,5})\b`), group: 1},
+	{kind: "path", re: regexp.MustCompile(`(?:^|[\s"'=(:])((?:~|\.{1,2})?/[\w.@%+~-]+(?:/[\w.@%+~-]*)*)`), group: 1},
+	{kind: "string", re: regexp.MustCompile(`"([^"\n]{1,80})"`), group: 1},
+	{kind: "string", re: regexp.MustCompile(`'([^'\n]{1,80})'`), group: 1},
+}
+
+// templatizeNames are the variable names proposed per kind (without a --flag or VAR= to go by)
+var templatizeNames = map[string]string{
+	"url": "url", "uuid": "id", "ip": "host", "port": "port", "path": "path", "string": "value",
+}
+
+// paramNameContext finds a --flag or NAME= right before a value
+var paramNameContext = regexp.MustCompile(`(?:--([A-Za-z][\w-]*)[= ]|\b([A-Za-z_]\w*)=)["']?$`)
+
+// templatizeVarName is what a suggestion may be renamed to
+var templatizeVarName = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+
+// suggestTemplateParams scans content for values that look like parameters
+// Each distinct value is one suggestion (with all its occurrences), in order of appearance
+func suggestTemplateParams(content string) []templatizeSuggestion {
+	// Existing {{tags}} are already variables
+	var taken [][2]int
+	for _, tag := range templateTags(content) {
+		taken = append(taken, [2]int{tag.Pos, tag.Pos + len(tag.Raw)})
+	}
+	overlaps := func(start, end int) bool {
+		for _, span := range taken {
+			if start < span[1] && span[0] < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	type match struct {
+		kind       string
+		start, end int
+	}
+	var matches []match
+	for _, pattern := range templatizePatterns {
+		for _, loc := range pattern.re.FindAllStringSubmatchIndex(content, -1) {
+			start, end := loc[2*pattern.group], loc[2*pattern.group+1]
+			if pattern.kind == "url" || pattern.kind == "path" {
+				// Sentence punctuation isn't part of it
+				end = start + len(strings.TrimRight(content[start:end], ".,;:!?"))
+			}
+			if !plausibleParam(content, pattern.kind, start, end) || overlaps(start, end) {
+				continue
+			}
+			taken = append(taken, [2]int{start, end})
+			matches = append(matches, match{kind: pattern.kind, start: start, end: end})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	// Names already used by the card's variables aren't proposed again
+	used := make(map[string]bool)
+	for _, name := range ExtractVariables(content) {
+		used[name] = true
+	}
+
+	var suggestions []templatizeSuggestion
+	byValue := make(map[string]int)
+	for _, found := range matches {
+		value := content[found.start:found.end]
+		if i, ok := byValue[value]; ok {
+			suggestions[i].Spans = append(suggestions[i].Spans, [2]int{found.start, found.end})
+			continue
+		}
+		name := uniqueVarName(suggestParamName(content, found.start, found.kind), used)
+		used[name] = true
+		byValue[value] = len(suggestions)
+		suggestions = append(suggestions, templatizeSuggestion{
+			Original: value,
+			Kind:     found.kind,
+			Name:     name,
+			Spans:    [][2]int{{found.start, found.end}},
+		})
+	}
+	return suggestions
+}
+
+// plausibleParam filters out matches that aren't parameters or can't be a default
+func plausibleParam(content, kind string, start, end int) bool {
+	value := content[start:end]
+	if value == "" || strings.Contains(value, "{") || strings.Contains(value, "}") || strings.Contains(value, "::") {
+		return false
+	}
+
+	switch kind {
+	case "ip":
+		return net.ParseIP(value) != nil
+	case "port":
+		port, err := strconv.Atoi(value)
+		return err == nil && port > 0 && port <= 65535
+	case "path":
+		return len(strings.Trim(value, "/.~")) > 0
+	case "string":
+		// Defaults are trimmed, and apostrophes in prose ("don't ... isn't") aren't quotes
+		if strings.TrimSpace(value) != value {
+			return false
+		}
+		before, after := start-2, end+1
+		if before >= 0 && isWordRune(rune(content[before])) || after < len(content) && isWordRune(rune(content[after])) {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestParamName names a value after the --flag or VAR= in front of it, else its kind
+func suggestParamName(content string, start int, kind string) string {
+	lineStart := strings.LastIndex(content[:start], "\n") + 1
+	if m := paramNameContext.FindStringSubmatch(content[lineStart:start]); m != nil {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		name = strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+		if len(name) > 1 {
+			return name
+		}
+	}
+	return templatizeNames[kind]
+}
+
+// uniqueVarName returns name, or name_2, name_3... if it's already used
+func uniqueVarName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s_%d", name, n); !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// applyTemplatize replaces every occurrence of each accepted suggestion with {{name|original}}
+func applyTemplatize(content string, suggestions []templatizeSuggestion) string {
+	type replacement struct {
+		span [2]int
+		text string
+	}
+	var replacements []replacement
+	for _, s := range suggestions {
+		if s.State != suggestAccepted {
+			continue
+		}
+		for _, span := range s.Spans {
+			replacements = append(replacements, replacement{span: span, text: "{{" + s.Name + "|" + s.Original + "}}"})
+		}
+	}
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].span[0] < replacements[j].span[0] })
+
+	var b strings.Builder
+	last := 0
+	for _, r := range replacements {
+		b.WriteString(content[last:r.span[0]])
+		b.WriteString(r.text)
+		last = r.span[1]
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// startTemplatize opens the review of a card's suggestions
+func (m *Model) startTemplatize(card *Card) {
+	if card == nil {
+		return
+	}
+	suggestions := suggestTemplateParams(card.Content)
+	if len(suggestions) == 0 {
+		m.ReloadMessage = fmt.Sprintf("No likely parameters found in '%s'", card.Title)
+		m.ReloadMessageTime = time.Now()
+		return
+	}
+	m.Templatize = &TemplatizeSession{
+		CardID:      card.ID,
+		Title:       card.Title,
+		Content:     card.Content,
+		Suggestions: suggestions,
+		ReturnMode:  m.ViewMode,
+	}
+	m.ViewMode = ViewTemplatize
+}
+
+// decide sets the current suggestion's state and moves on to the next undecided one
+func (s *TemplatizeSession) decide(state int) {
+	s.Suggestions[s.Cursor].State = state
+	for i := s.Cursor + 1; i < len(s.Suggestions); i++ {
+		if s.Suggestions[i].State == suggestPending {
+			s.Cursor = i
+			return
+		}
+	}
+	s.Cursor = min(s.Cursor+1, len(s.Suggestions)-1)
+}
+
+// renameError says why name can't be used for the current suggestion ("" if it can)
+func (s *TemplatizeSession) renameError(name string) string {
+	if !templatizeVarName.MatchString(name) || isEachItem(name) {
+		return "Use letters, digits, _ . - (not starting with a digit)"
+	}
+	for i, other := range s.Suggestions {
+		if i != s.Cursor && other.Name == name && other.State != suggestRejected {
+			return fmt.Sprintf("'%s' is already used for %s", name, other.Original)
+		}
+	}
+	return ""
+}
+
+// handleTemplatizeInput processes keys while reviewing suggestions
+func (m Model) handleTemplatizeInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.Templatize
+
+	if s.Renaming {
+		switch msg.String() {
+		case "enter":
+			name := strings.TrimSpace(s.NameInput)
+			if problem := s.renameError(name); problem != "" {
+				s.NameError = problem
+				return m, nil
+			}
+			s.Suggestions[s.Cursor].Name = name
+			s.Renaming = false
+		case "esc":
+			s.Renaming = false
+		default:
+			s.NameError = ""
+			m.input("templatize:name").Update(&s.NameInput, msg)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k", "shift+tab":
+		s.Cursor = max(0, s.Cursor-1)
+	case "down", "j", "tab":
+		s.Cursor = min(len(s.Suggestions)-1, s.Cursor+1)
+	case "y", " ":
+		s.decide(suggestAccepted)
+	case "n", "x":
+		s.decide(suggestRejected)
+	case "a":
+		for i := range s.Suggestions {
+			if s.Suggestions[i].State == suggestPending {
+				s.Suggestions[i].State = suggestAccepted
+			}
+		}
+	case "r", "e":
+		s.Renaming = true
+		s.NameInput = s.Suggestions[s.Cursor].Name
+		s.NameError = ""
+		m.TextInputs = nil
+	case "enter", "ctrl+s":
+		return m, m.saveTemplatize()
+	case "esc", "q":
+		return m, m.closeTemplatize()
+	}
+	return m, nil
+}
+
+// closeTemplatize leaves the review for the view it was opened from
+func (m *Model) closeTemplatize() tea.Cmd {
+	s := m.Templatize
+	m.Templatize = nil
+	m.TextInputs = nil
+	if s.ReturnMode == ViewDetail {
+		cmd, _ := m.openCardByID(s.CardID)
+		return cmd
+	}
+	m.ViewMode = s.ReturnMode
+	return nil
+}
+
+// saveTemplatize writes the accepted placeholders into the card (undoable)
+func (m *Model) saveTemplatize() tea.Cmd {
+	s := m.Templatize
+	accepted := 0
+	for _, suggestion := range s.Suggestions {
+		if suggestion.State == suggestAccepted {
+			accepted++
+		}
+	}
+	if accepted == 0 {
+		m.ReloadMessage = "No suggestions accepted - card unchanged"
+		m.ReloadMessageTime = time.Now()
+		return m.closeTemplatize()
+	}
+
+	index := findCardIndex(m.Data.Cards, s.CardID)
+	if index < 0 || m.Data.Cards[index].Content != s.Content {
+		m.ReloadMessage = fmt.Sprintf("⚠ '%s' changed while templatizing - nothing saved", s.Title)
+		m.ReloadMessageTime = time.Now()
+		return m.closeTemplatize()
+	}
+
+	before := m.Data.Cards[index]
+	after := before
+	after.Content = applyTemplatize(s.Content, s.Suggestions)
+	after.UpdatedAt = time.Now().UnixMilli()
+	cmd := m.execute(updateCardCommand{verb: "templatize", before: before, after: after},
+		fmt.Sprintf("🧩 Made %d variable(s) in '%s'", accepted, after.Title))
+	return tea.Batch(cmd, m.closeTemplatize())
+}
+
+// renderTemplatizeScreen shows the card with its suggestions marked and the list of suggestions
+func renderTemplatizeScreen(m Model) string {
+	s := m.Templatize
+	width := max(20, m.Width-4)
+
+	accepted := 0
+	for _, suggestion := range s.Suggestions {
+		if suggestion.State == suggestAccepted {
+			accepted++
+		}
+	}
+	header := stylePreviewTitle.Render("🧩 Templatize '"+s.Title+"'") +
+		styleSubtle.Render(fmt.Sprintf("  %d of %d accepted", accepted, len(s.Suggestions)))
+	separator := strings.Repeat("─", width)
+
+	// Suggestion list: a window around the cursor
+	listHeight := min(len(s.Suggestions), max(3, m.Height/3))
+	start := max(0, min(s.Cursor-listHeight/2, len(s.Suggestions)-listHeight))
+	var list []string
+	nameWidth := 0
+	for _, suggestion := range s.Suggestions {
+		nameWidth = max(nameWidth, runewidth.StringWidth(suggestion.Name))
+	}
+	for i := start; i < start+listHeight; i++ {
+		suggestion := s.Suggestions[i]
+		mark := "[ ]"
+		switch suggestion.State {
+		case suggestAccepted:
+			mark = "[✓]"
+		case suggestRejected:
+			mark = "[✗]"
+		}
+		detail := suggestion.Kind
+		if len(suggestion.Spans) > 1 {
+			detail += fmt.Sprintf(", %d×", len(suggestion.Spans))
+		}
+		line := fmt.Sprintf("%s %s  %s  (%s)", mark, padOrTruncate(suggestion.Name, nameWidth), suggestion.Original, detail)
+		line = truncate(line, width-2)
+		switch {
+		case i == s.Cursor:
+			list = append(list, styleCardItemSelected.Render("> "+line))
+		case suggestion.State == suggestRejected:
+			list = append(list, styleSubtle.Render("  "+line))
+		default:
+			list = append(list, "  "+line)
+		}
+	}
+
+	// Rename prompt or key hints
+	var footer []string
+	if s.Renaming {
+		footer = append(footer, styleHelpKey.Render("Name: ")+m.TextInputs["templatize:name"].View(s.NameInput, "", true))
+		if s.NameError != "" {
+			footer = append(footer, styleError.Render("⚠ "+s.NameError))
+		}
+		footer = append(footer, styleHelpKey.Render("Enter")+styleHelpDesc.Render(" rename  ")+
+			styleHelpKey.Render("Esc")+styleHelpDesc.Render(" keep"))
+	} else {
+		footer = append(footer, strings.Join([]string{
+			styleHelpKey.Render("y") + styleHelpDesc.Render(" accept"),
+			styleHelpKey.Render("n") + styleHelpDesc.Render(" reject"),
+			styleHelpKey.Render("r") + styleHelpDesc.Render(" rename"),
+			styleHelpKey.Render("a") + styleHelpDesc.Render(" accept all"),
+			styleHelpKey.Render("↑↓") + styleHelpDesc.Render(" move"),
+			styleHelpKey.Render("Enter") + styleHelpDesc.Render(" save"),
+			styleHelpKey.Render("Esc") + styleHelpDesc.Render(" cancel"),
+		}, "  "))
+	}
+
+	// Content fills what's left, scrolled to the current suggestion
+	contentLines, cursorLine := templatizeContentLines(s, width)
+	contentHeight := max(1, m.Height-len(list)-len(footer)-5)
+	top := max(0, min(cursorLine-contentHeight/2, len(contentLines)-contentHeight))
+	contentLines = contentLines[top:min(len(contentLines), top+contentHeight)]
+
+	lines := []string{header, separator}
+	lines = append(lines, contentLines...)
+	for len(lines) < contentHeight+2 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, separator)
+	lines = append(lines, list...)
+	lines = append(lines, "")
+	lines = append(lines, footer...)
+
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(lines, "\n"))
+}
+
+// templatizeContentLines renders the content with each occurrence styled by its
+// suggestion's state (accepted ones show their placeholder); lines are cut at width.
+// Also returns the line of the current suggestion's first occurrence
+func templatizeContentLines(s *TemplatizeSession, width int) ([]string, int) {
+	type mark struct {
+		span  [2]int
+		index int
+	}
+	var marks []mark
+	for i, suggestion := range s.Suggestions {
+		for _, span := range suggestion.Spans {
+			marks = append(marks, mark{span: span, index: i})
+		}
+	}
+	sort.Slice(marks, func(i, j int) bool { return marks[i].span[0] < marks[j].span[0] })
+
+	var lines []string
+	var line strings.Builder
+	lineWidth, cursorLine, last := 0, -1, 0
+	// add appends text to the current line(s), cutting lines at width
+	add := func(text string, style *lipgloss.Style) {
+		for i, part := range strings.Split(text, "\n") {
+			if i > 0 {
+				lines = append(lines, line.String())
+				line.Reset()
+				lineWidth = 0
+			}
+			if room := width - lineWidth; room > 0 && part != "" {
+				cut := runewidth.Truncate(part, room, "…")
+				lineWidth += runewidth.StringWidth(cut)
+				if style != nil {
+					cut = style.Render(cut)
+				}
+				line.WriteString(cut)
+			}
+		}
+	}
+
+	for _, mk := range marks {
+		add(s.Content[last:mk.span[0]], nil)
+		suggestion := s.Suggestions[mk.index]
+		text, style := suggestion.Original, styleFillMissing
+		switch suggestion.State {
+		case suggestAccepted:
+			text, style = "{{"+suggestion.Name+"|"+suggestion.Original+"}}", styleFillValue
+		case suggestRejected:
+			style = styleSubtle
+		}
+		if mk.index == s.Cursor {
+			style = styleLinkFocused
+			if cursorLine < 0 {
+				cursorLine = len(lines)
+			}
+		}
+		add(text, &style)
+		last = mk.span[1]
+	}
+	add(s.Content[last:], nil)
+	lines = append(lines, line.String())
+
+	return lines, max(0, cursorLine)
+}
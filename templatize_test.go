@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describeSuggestions lists suggestions as "name=original (kind)" for comparison
+func describeSuggestions(suggestions []templatizeSuggestion) string {
+	var parts []string
+	for _, s := range suggestions {
+		part := fmt.Sprintf("%s=%s (%s)", s.Name, s.Original, s.Kind)
+		if len(s.Spans) > 1 {
+			part += fmt.Sprintf(" x%d", len(s.Spans))
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestSuggestTemplateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "ip and port", content: "ssh -p 2222 admin@192.168.1.10", want: "port=2222 (port), host=192.168.1.10 (ip)"},
+		{name: "host:port", content: "psql -h db.internal:5432", want: "port=5432 (port)"},
+		{name: "docker ports", content: "docker run -p 8080:80 nginx", want: "port=8080 (port), port_2=80 (port)"},
+		{name: "url wins over its host and port", content: "curl https://api.example.com:8443/v1/users.", want: "url=https://api.example.com:8443/v1/users (url)"},
+		{name: "uuid", content: "kubectl delete pod 3f2b8c1e-9a4d-4e7b-8c2a-1d5e6f7a8b9c", want: "id=3f2b8c1e-9a4d-4e7b-8c2a-1d5e6f7a8b9c (uuid)"},
+		{name: "paths", content: "tar czf ~/backup.tgz ./src /etc/nginx/nginx.conf", want: "path=~/backup.tgz (path), path_2=./src (path), path_3=/etc/nginx/nginx.conf (path)"},
+		{name: "names from flags and env vars", content: `helm install --namespace "staging" --values=./values.yaml DB_HOST=10.0.0.1 app`, want: "namespace=staging (string), values=./values.yaml (path), db_host=10.0.0.1 (ip)"},
+		{name: "repeated values are one suggestion", content: "ping 10.0.0.1 && ssh 10.0.0.1", want: "host=10.0.0.1 (ip) x2"},
+		{name: "quoted strings", content: `git commit -m "fix login" && echo 'done'`, want: "value=fix login (string), value_2=done (string)"},
+		{name: "apostrophes aren't quotes", content: "Don't run this, it's slow", want: ""},
+		{name: "existing variables are skipped", content: "ssh {{host|10.0.0.1}} 10.0.0.2", want: "host_2=10.0.0.2 (ip)"},
+		{name: "not an ip", content: "version 1.2.3.999 and 12:30", want: ""},
+		{name: "strings that can't be defaults", content: `echo "{{x}}" " padded "`, want: ""},
+		{name: "plain prose", content: "and/or 1/2 </b>", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeSuggestions(suggestTemplateParams(tt.content)); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTemplatize(t *testing.T) {
+	content := "ping 10.0.0.1 -c 3 && ssh -p 2222 10.0.0.1"
+	suggestions := suggestTemplateParams(content)
+	for i := range suggestions {
+		if suggestions[i].Kind == "ip" {
+			suggestions[i].State = suggestAccepted
+			suggestions[i].Name = "server"
+		} else {
+			suggestions[i].State = suggestRejected
+		}
+	}
+
+	got := applyTemplatize(content, suggestions)
+	want := "ping {{server|10.0.0.1}} -c 3 && ssh -p 2222 {{server|10.0.0.1}}"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if vars := ExtractVariables(got); len(vars) != 1 || vars[0] != "server" {
+		t.Errorf("variables = %v, want [server]", vars)
+	}
+	if filled := FillTemplate(got, map[string]string{}); filled != content {
+		t.Errorf("filled with defaults = %q, want the original %q", filled, content)
+	}
+}
+
+func TestTemplatizeReview(t *testing.T) {
+	m := testModel(&CellBlocksData{Cards: []Card{{ID: "a", Title: "SSH", Content: "ssh -p 2222 admin@10.0.0.5 -i ~/.ssh/key"}}})
+
+	press := func(keys ...string) {
+		t.Helper()
+		for _, key := range keys {
+			model, _ := m.handleKeyPress(testKey(key))
+			m = model.(Model)
+		}
+	}
+
+	press("z")
+	if m.ViewMode != ViewTemplatize || m.Templatize == nil {
+		t.Fatalf("z didn't open the review (view %d)", m.ViewMode)
+	}
+
+	// Accept the port, reject the host, rename the key path - a taken name is refused first
+	press("y", "n", "r", "ctrl+u", "p", "o", "r", "t", "enter")
+	if m.Templatize.NameError == "" || !m.Templatize.Renaming {
+		t.Fatalf("renaming to a used name wasn't refused")
+	}
+	press("ctrl+u", "k", "e", "y", "enter")
+	if m.Templatize.Renaming || m.Templatize.Suggestions[2].Name != "key" {
+		t.Fatalf("rename failed: %+v", m.Templatize.Suggestions[2])
+	}
+	press("y", "enter")
+
+	want := "ssh -p {{port|2222}} admin@10.0.0.5 -i {{key|~/.ssh/key}}"
+	if got := m.Data.Cards[0].Content; got != want {
+		t.Errorf("saved %q, want %q", got, want)
+	}
+	if m.ViewMode != ViewList || m.Templatize != nil {
+		t.Errorf("still reviewing after save (view %d)", m.ViewMode)
+	}
+
+	// One undo restores the literal command
+	press("u")
+	if got := m.Data.Cards[0].Content; got != "ssh -p 2222 admin@10.0.0.5 -i ~/.ssh/key" {
+		t.Errorf("after undo %q", got)
+	}
+
+	// Esc leaves without saving; cards without parameters don't open the review
+	press("z", "a", "esc")
+	if m.ViewMode != ViewList || strings.Contains(m.Data.Cards[0].Content, "{{") {
+		t.Errorf("cancel changed the card: %q", m.Data.Cards[0].Content)
+	}
+	m.Data.Cards[0].Content = "just some notes"
+	m.updateFilteredCards()
+	press("z")
+	if m.ViewMode != ViewList {
+		t.Errorf("review opened without suggestions")
+	}
+}
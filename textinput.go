@@ -0,0 +1,415 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// textinput.go - Text Input Widget
+// Purpose: Cursor editing for the create form and the template form
+//
+// Values stay where they always lived (NewCardTitle, TemplateVars...); a textInput
+// holds only the editing state of one field - cursor and undo history - and applies
+// keys to the value. Keys it doesn't use (Tab, Esc, Ctrl+S...) are left to the form.
+//
+//	←/→ ↑/↓          Move (↑/↓ only in multi-line inputs)
+//	Ctrl+←/→, Alt+B/F Move by word
+//	Home/End          Line start/end (Ctrl+A/Ctrl+E too); Ctrl+Home/End for the whole value
+//	Backspace/Delete  Delete a character (Ctrl+D deletes forward)
+//	Ctrl+W, Alt+⌫     Delete the word before the cursor (Alt+D: the word after)
+//	Ctrl+U/Ctrl+K     Delete to line start/end
+//	Ctrl+Z            Undo
+//
+// Pastes (bracketed or not) insert as one edit; single-line inputs join pasted lines.
+
+// maxInputUndo bounds each field's undo history
+const maxInputUndo = 100
+
+// textInput is the editing state of one text field
+type textInput struct {
+	Multiline bool   // Enter inserts a newline, content soft-wraps, ↑/↓ move between rows
+	Width     int    // Soft-wrap width of a multi-line input (0 = no wrapping)
+	PasteJoin string // Single-line: what pasted line breaks become ("" = a space)
+
+	cursor int              // Rune offset into the value
+	last   string           // Value after the last update (anything else was set from outside)
+	synced bool             // last is set
+	undo   []textInputState // Earlier values, newest last
+	lastOp string           // Kind of the last edit; a run of one kind undoes in one step
+}
+
+// textInputState is a value and cursor to go back to with Ctrl+Z
+type textInputState struct {
+	value  string
+	cursor int
+}
+
+// inputRow is one displayed row of a multi-line input, as rune offsets
+// (end excludes the newline; a soft-wrapped row ends where the next begins)
+type inputRow struct {
+	start, end int
+}
+
+// input returns the editing state of a form field, creating it on first use
+func (m *Model) input(key string) *textInput {
+	if m.TextInputs == nil {
+		m.TextInputs = make(map[string]*textInput)
+	}
+	in := m.TextInputs[key]
+	if in == nil {
+		in = &textInput{}
+		m.TextInputs[key] = in
+	}
+	return in
+}
+
+// textInputFocused reports whether a text field has focus, so keys reach it before
+// the app's shortcuts (q, ?, m... are typed)
+func (m *Model) textInputFocused() bool {
+	switch m.ViewMode {
+	case ViewCardCreate:
+		field := m.focusedSchemaField()
+		return m.CreateFormField <= 1 || field != nil && (field.Type == FieldText || field.Type == FieldURL)
+	case ViewDetail:
+		v := m.focusedTemplateVar()
+		return v != nil && v.Type != VarChoice && v.Type != VarBool
+	}
+	return false
+}
+
+// cursorIn returns the cursor for value (at the end when the value was set from outside)
+// Nil-safe so views can look up fields that haven't been edited yet
+func (in *textInput) cursorIn(value string) int {
+	n := len([]rune(value))
+	if in == nil || !in.synced || value != in.last {
+		return n
+	}
+	return min(max(in.cursor, 0), n)
+}
+
+// Update applies a key to *value and reports whether the input used it
+func (in *textInput) Update(value *string, msg tea.KeyMsg) bool {
+	// A value set from outside (history, prefill) is an undo step of its own
+	if in.synced && *value != in.last {
+		in.push(textInputState{in.last, in.cursorIn(in.last)})
+		in.lastOp = ""
+	}
+	in.cursor = in.cursorIn(*value)
+	in.last, in.synced = *value, true
+
+	runes := []rune(*value)
+	cursor := in.cursor
+	lineStart, lineEnd := lineBounds(runes, cursor)
+
+	// replace swaps runes[from:to] for text as one edit of the given kind
+	replace := func(from, to int, text []rune, op string) {
+		if from == to && len(text) == 0 {
+			return
+		}
+		if op != in.lastOp || op == "paste" {
+			in.push(textInputState{*value, in.cursor})
+		}
+		in.lastOp = op
+		edited := append(append(append([]rune{}, runes[:from]...), text...), runes[to:]...)
+		*value = string(edited)
+		in.cursor = from + len(text)
+	}
+	move := func(pos int) {
+		in.cursor = min(max(pos, 0), len(runes))
+		in.lastOp = ""
+	}
+
+	switch {
+	case msg.Type == tea.KeyRunes && (msg.Paste || !msg.Alt):
+		text := msg.Runes
+		op := "type"
+		if msg.Paste || len(text) > 1 {
+			text, op = in.pasteText(text), "paste"
+		}
+		replace(cursor, cursor, text, op)
+
+	case msg.Type == tea.KeySpace:
+		replace(cursor, cursor, []rune{' '}, "type")
+
+	case msg.Type == tea.KeyEnter && in.Multiline:
+		replace(cursor, cursor, []rune{'\n'}, "type")
+
+	default:
+		switch msg.String() {
+		case "left", "ctrl+b":
+			move(cursor - 1)
+		case "right", "ctrl+f":
+			move(cursor + 1)
+		case "ctrl+left", "alt+left", "alt+b":
+			move(wordStart(runes, cursor))
+		case "ctrl+right", "alt+right", "alt+f":
+			move(wordEnd(runes, cursor))
+		case "home", "ctrl+a":
+			move(lineStart)
+		case "end", "ctrl+e":
+			move(lineEnd)
+		case "ctrl+home":
+			move(0)
+		case "ctrl+end":
+			move(len(runes))
+		case "up", "down":
+			if !in.Multiline {
+				return false
+			}
+			delta := 1
+			if msg.String() == "up" {
+				delta = -1
+			}
+			move(in.verticalMove(runes, cursor, delta))
+
+		case "backspace", "ctrl+h":
+			replace(max(cursor-1, 0), cursor, nil, "delete")
+		case "delete", "ctrl+d":
+			replace(cursor, min(cursor+1, len(runes)), nil, "delete forward")
+		case "ctrl+w", "alt+backspace":
+			replace(wordStart(runes, cursor), cursor, nil, "delete word")
+		case "alt+d", "alt+delete":
+			replace(cursor, wordEnd(runes, cursor), nil, "delete word forward")
+		case "ctrl+u":
+			replace(lineStart, cursor, nil, "delete line")
+		case "ctrl+k":
+			replace(cursor, lineEnd, nil, "delete line forward")
+
+		case "ctrl+z":
+			if len(in.undo) > 0 {
+				state := in.undo[len(in.undo)-1]
+				in.undo = in.undo[:len(in.undo)-1]
+				*value, in.cursor = state.value, state.cursor
+				in.lastOp = ""
+			}
+
+		default:
+			return false
+		}
+	}
+
+	in.last = *value
+	return true
+}
+
+// push records a state to undo to
+func (in *textInput) push(state textInputState) {
+	in.undo = append(in.undo, state)
+	if len(in.undo) > maxInputUndo {
+		in.undo = in.undo[len(in.undo)-maxInputUndo:]
+	}
+}
+
+// pasteText prepares pasted text: Windows line endings go, and single-line
+// inputs join the lines
+func (in *textInput) pasteText(text []rune) []rune {
+	s := strings.ReplaceAll(string(text), "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	if !in.Multiline && strings.Contains(s, "\n") {
+		join := in.PasteJoin
+		if join == "" {
+			join = " "
+		}
+		var lines []string
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		s = strings.Join(lines, join)
+	}
+	return []rune(s)
+}
+
+// lineBounds returns the start and end of the line holding pos
+func lineBounds(runes []rune, pos int) (int, int) {
+	start, end := pos, pos
+	for start > 0 && runes[start-1] != '\n' {
+		start--
+	}
+	for end < len(runes) && runes[end] != '\n' {
+		end++
+	}
+	return start, end
+}
+
+// isWordRune reports whether r is part of a word for word moves and deletes
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// wordStart returns the start of the word before pos (skipping separators first)
+func wordStart(runes []rune, pos int) int {
+	for pos > 0 && !isWordRune(runes[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(runes[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordEnd returns the end of the word after pos (skipping separators first)
+func wordEnd(runes []rune, pos int) int {
+	for pos < len(runes) && !isWordRune(runes[pos]) {
+		pos++
+	}
+	for pos < len(runes) && isWordRune(runes[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// Multi-line layout
+
+// wrapInputRows splits runes into displayed rows: at newlines, and at the last
+// space that fits when a line is wider than width
+func wrapInputRows(runes []rune, width int) []inputRow {
+	var rows []inputRow
+	lineStart := 0
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && runes[i] != '\n' {
+			continue
+		}
+		start := lineStart
+		for width > 0 {
+			cols, end, lastSpace := 0, start, -1
+			for end < i {
+				w := runewidth.RuneWidth(runes[end])
+				if cols+w > width {
+					break
+				}
+				cols += w
+				if runes[end] == ' ' {
+					lastSpace = end
+				}
+				end++
+			}
+			if end >= i {
+				break
+			}
+			// Break after the last space that fits, else mid-word
+			if lastSpace >= start {
+				end = lastSpace + 1
+			}
+			end = max(end, start+1)
+			rows = append(rows, inputRow{start, end})
+			start = end
+		}
+		rows = append(rows, inputRow{start, i})
+		lineStart = i + 1
+	}
+	return rows
+}
+
+// cursorRow returns the row holding pos (a soft-wrap boundary belongs to the next row)
+func cursorRow(rows []inputRow, pos int) int {
+	row := 0
+	for i, r := range rows {
+		if r.start <= pos {
+			row = i
+		}
+	}
+	return row
+}
+
+// verticalMove returns the cursor one row up or down, keeping the column where it can
+func (in *textInput) verticalMove(runes []rune, cursor, delta int) int {
+	rows := wrapInputRows(runes, in.Width)
+	current := cursorRow(rows, cursor)
+	target := current + delta
+	if target < 0 {
+		return 0
+	}
+	if target >= len(rows) {
+		return len(runes)
+	}
+
+	col := runewidth.StringWidth(string(runes[rows[current].start:cursor]))
+	row := rows[target]
+	// The end of a soft-wrapped row is the start of the next one
+	last := row.end
+	if target+1 < len(rows) && rows[target+1].start == row.end {
+		last = row.end - 1
+	}
+	pos, cols := row.start, 0
+	for pos < last {
+		w := runewidth.RuneWidth(runes[pos])
+		if cols+w > col {
+			break
+		}
+		cols += w
+		pos++
+	}
+	return pos
+}
+
+// Rendering
+
+// renderCursorText renders text with a block cursor at rune offset cursor
+// (a reversed character, or █ at the end)
+func renderCursorText(runes []rune, cursor int) string {
+	if cursor >= len(runes) {
+		return styleCardItemSelected.Render(string(runes) + "█")
+	}
+	under := string(runes[cursor])
+	if runes[cursor] == '\n' {
+		under = " "
+	}
+	// Pieces without padding, so the cursor doesn't get spaces around it
+	text := styleCardItemSelected.UnsetPadding()
+	return text.Render(" "+string(runes[:cursor])) +
+		styleInputCursor.Render(under) +
+		text.Render(string(runes[cursor+1:])+" ")
+}
+
+// View renders a single-line input; placeholder shows while the value is empty
+func (in *textInput) View(value, placeholder string, focused bool) string {
+	if value == "" {
+		if focused {
+			return styleCardItemSelected.Render(placeholder + "█")
+		}
+		return placeholder
+	}
+	if !focused {
+		return value
+	}
+	return renderCursorText([]rune(value), in.cursorIn(value))
+}
+
+// ViewRows renders a multi-line input soft-wrapped to width, at most height rows,
+// scrolled to keep the cursor in view, with a line for rows above/below
+func (in *textInput) ViewRows(value string, width, height int, focused bool) []string {
+	runes := []rune(value)
+	rows := wrapInputRows(runes, width)
+	cursor := in.cursorIn(value)
+	current := cursorRow(rows, cursor)
+
+	start := 0
+	if focused && current >= height {
+		start = current - height + 1
+	}
+	end := min(start+height, len(rows))
+
+	var lines []string
+	if start > 0 {
+		lines = append(lines, styleSubtle.Render(fmt.Sprintf("↑ (%d more lines)", start)))
+	}
+	for i := start; i < end; i++ {
+		row := rows[i]
+		text := runes[row.start:row.end]
+		if focused && i == current {
+			lines = append(lines, renderCursorText(text, cursor-row.start))
+		} else {
+			lines = append(lines, string(text))
+		}
+	}
+	if end < len(rows) {
+		lines = append(lines, styleSubtle.Render(fmt.Sprintf("... (%d more lines)", len(rows)-end)))
+	}
+	return lines
+}
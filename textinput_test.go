@@ -0,0 +1,200 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testKeys maps key names to key messages; anything else types its runes
+var testKeys = map[string]tea.KeyMsg{
+	"left":          {Type: tea.KeyLeft},
+	"right":         {Type: tea.KeyRight},
+	"up":            {Type: tea.KeyUp},
+	"down":          {Type: tea.KeyDown},
+	"home":          {Type: tea.KeyHome},
+	"end":           {Type: tea.KeyEnd},
+	"enter":         {Type: tea.KeyEnter},
+	"tab":           {Type: tea.KeyTab},
+	"space":         {Type: tea.KeySpace, Runes: []rune{' '}},
+	"backspace":     {Type: tea.KeyBackspace},
+	"delete":        {Type: tea.KeyDelete},
+	"ctrl+left":     {Type: tea.KeyCtrlLeft},
+	"ctrl+right":    {Type: tea.KeyCtrlRight},
+	"ctrl+w":        {Type: tea.KeyCtrlW},
+	"ctrl+u":        {Type: tea.KeyCtrlU},
+	"ctrl+k":        {Type: tea.KeyCtrlK},
+	"ctrl+z":        {Type: tea.KeyCtrlZ},
+	"alt+d":         {Type: tea.KeyRunes, Runes: []rune{'d'}, Alt: true},
+	"alt+backspace": {Type: tea.KeyBackspace, Alt: true},
+}
+
+// testKey returns the key message for a name in testKeys, "paste:..." or typed text
+func testKey(name string) tea.KeyMsg {
+	if msg, ok := testKeys[name]; ok {
+		return msg
+	}
+	if text, ok := strings.CutPrefix(name, "paste:"); ok {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// withCursor shows the cursor of value as "|"
+func withCursor(in *textInput, value string) string {
+	runes := []rune(value)
+	cursor := in.cursorIn(value)
+	return string(runes[:cursor]) + "|" + string(runes[cursor:])
+}
+
+func TestTextInputUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		multiline bool
+		pasteJoin string
+		start     string
+		keys      []string
+		want      string // Value with the cursor as "|"
+	}{
+		{name: "multi-byte typing and backspace", keys: []string{"h", "é", "🙂", "backspace"}, want: "hé|"},
+		{name: "insert in the middle", start: "abc", keys: []string{"left", "left", "X"}, want: "aX|bc"},
+		{name: "home and end", start: "abc", keys: []string{"home", "X", "end", "Y"}, want: "XabcY|"},
+		{name: "delete forward", start: "abc", keys: []string{"home", "delete"}, want: "|bc"},
+		{name: "ctrl+w deletes the word before", start: "go test ./...", keys: []string{"ctrl+w"}, want: "go |"},
+		{name: "alt+backspace deletes the word before", start: "foo bar", keys: []string{"alt+backspace"}, want: "foo |"},
+		{name: "alt+d deletes the word after", start: "foo bar baz", keys: []string{"home", "alt+d"}, want: "| bar baz"},
+		{name: "word moves", start: "foo bar baz", keys: []string{"ctrl+left", "ctrl+left", "X", "ctrl+right", "Y"}, want: "foo XbarY| baz"},
+		{name: "ctrl+u and ctrl+k", start: "one two", keys: []string{"left", "left", "left", "ctrl+k", "home", "right", "ctrl+u"}, want: "|ne "},
+		{name: "paste inserts at the cursor", start: "ab", keys: []string{"left", "paste:XYZ"}, want: "aXYZ|b"},
+		{name: "single-line paste joins lines", keys: []string{"paste:one\r\ntwo\n\nthree\n"}, want: "one two three|"},
+		{name: "list paste joins with commas", pasteJoin: ", ", keys: []string{"paste:a.go\nb.go\n"}, want: "a.go, b.go|"},
+		{name: "multi-line keeps pasted lines", multiline: true, keys: []string{"paste:a\r\nb"}, want: "a\nb|"},
+		{name: "enter inserts a newline", multiline: true, start: "ab", keys: []string{"left", "enter"}, want: "a\n|b"},
+		{name: "up and down keep the column", multiline: true, start: "abcd\nxy\nlonger", keys: []string{"up", "up", "left", "down", "Z"}, want: "abcd\nxZ|y\nlonger"},
+		{name: "up on the first row goes to the start", multiline: true, start: "abc", keys: []string{"up", "X"}, want: "X|abc"},
+		{name: "undo a run of typing", start: "a", keys: []string{"b", "c", "ctrl+z"}, want: "a|"},
+		{name: "undo after a move undoes one run", keys: []string{"a", "b", "left", "X", "ctrl+z"}, want: "a|b"},
+		{name: "undo each kind of edit", start: "foo", keys: []string{"x", "backspace", "backspace", "ctrl+z", "ctrl+z"}, want: "foo|"},
+		{name: "undo with nothing to undo", keys: []string{"ctrl+z"}, want: "|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &textInput{Multiline: tt.multiline, PasteJoin: tt.pasteJoin}
+			value := tt.start
+			for _, key := range tt.keys {
+				if !in.Update(&value, testKey(key)) {
+					t.Fatalf("key %q not handled", key)
+				}
+			}
+			if got := withCursor(in, value); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextInputUnhandledKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		multiline bool
+		key       string
+	}{
+		{name: "tab", key: "tab"},
+		{name: "enter in a single-line input", key: "enter"},
+		{name: "up in a single-line input", key: "up"},
+		{name: "down in a single-line input", key: "down"},
+		{name: "tab in a multi-line input", multiline: true, key: "tab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := &textInput{Multiline: tt.multiline}
+			value := "abc"
+			if in.Update(&value, testKey(tt.key)) {
+				t.Errorf("key %q was handled", tt.key)
+			}
+			if value != "abc" {
+				t.Errorf("value = %q, want it unchanged", value)
+			}
+		})
+	}
+}
+
+func TestTextInputOutsideChange(t *testing.T) {
+	in := &textInput{}
+	value := ""
+	for _, key := range []string{"a", "b", "left"} {
+		in.Update(&value, testKey(key))
+	}
+
+	// A value set elsewhere (history, prefill) puts the cursor at the end...
+	value = "picked"
+	if got := withCursor(in, value); got != "picked|" {
+		t.Errorf("after an outside change got %q, want the cursor at the end", got)
+	}
+	// ...and undoes in one step
+	in.Update(&value, testKey("X"))
+	in.Update(&value, testKey("ctrl+z"))
+	in.Update(&value, testKey("ctrl+z"))
+	if got := withCursor(in, value); got != "a|b" {
+		t.Errorf("after undoing twice got %q, want %q", got, "a|b")
+	}
+}
+
+func TestWrapInputRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		width int
+		want  []string
+	}{
+		{name: "no wrapping", value: "one\ntwo", width: 0, want: []string{"one", "two"}},
+		{name: "breaks after spaces", value: "hello world foo", width: 11, want: []string{"hello ", "world foo"}},
+		{name: "breaks long words", value: "abcdefgh", width: 3, want: []string{"abc", "def", "gh"}},
+		{name: "wide runes count double", value: "日本語テキスト", width: 6, want: []string{"日本語", "テキス", "ト"}},
+		{name: "empty lines", value: "a\n\nb\n", width: 5, want: []string{"a", "", "b", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runes := []rune(tt.value)
+			var got []string
+			for _, row := range wrapInputRows(runes, tt.width) {
+				got = append(got, string(runes[row.start:row.end]))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormFieldsGetKeysFirst(t *testing.T) {
+	// Shortcut keys type into a focused create form field
+	m := testModel(&CellBlocksData{})
+	m.ViewMode = ViewCardCreate
+	for _, key := range []string{"q", "?", "m", "g"} {
+		model, cmd := m.handleKeyPress(testKey(key))
+		m = model.(Model)
+		if cmd != nil {
+			t.Errorf("key %q returned a command, want it typed", key)
+		}
+	}
+	if m.NewCardTitle != "q?mg" || m.ShowHelp {
+		t.Errorf("title = %q (help shown: %v), want the keys typed", m.NewCardTitle, m.ShowHelp)
+	}
+
+	// ...and into a template field, where "c" used to copy and "t" hide the form
+	m = testModel(&CellBlocksData{Cards: []Card{{ID: "a", Title: "A", Content: "cd {{dir}}"}}})
+	m.openDetailView()
+	m.TemplateVars["dir"] = ""
+	for _, key := range []string{"c", "t", "é"} {
+		model, _ := m.handleKeyPress(testKey(key))
+		m = model.(Model)
+	}
+	if got := m.TemplateVars["dir"]; got != "cté" || !m.ShowTemplateForm {
+		t.Errorf("dir = %q (form shown: %v), want the keys typed", got, m.ShowTemplateForm)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trash.go - Recoverable Card Deletion
+// Purpose: Move cards into the data file's trash section, restore and purge them
+
+// trashCard moves a card from the library into the trash
+// Returns the trash entry, or nil if the card wasn't found
+func trashCard(data *CellBlocksData, cardID string, now time.Time) *TrashedCard {
+	for i, card := range data.Cards {
+		if card.ID == cardID {
+			entry := TrashedCard{
+				Card:      card,
+				DeletedAt: now.UnixMilli(),
+				position:  i + 1,
+			}
+			data.Cards = append(data.Cards[:i], data.Cards[i+1:]...)
+			data.Trash = append(data.Trash, entry)
+			return &entry
+		}
+	}
+	return nil
+}
+
+// restoreCard moves a trashed card back into the library with its original category
+// Cards trashed this session go back to their old position, others to the end
+// Returns the restored card, or nil if it wasn't in the trash
+func restoreCard(data *CellBlocksData, cardID string) *Card {
+	for i, entry := range data.Trash {
+		if entry.ID == cardID {
+			card := entry.Card
+			data.Trash = append(data.Trash[:i], data.Trash[i+1:]...)
+			at := len(data.Cards)
+			if entry.position > 0 && entry.position <= len(data.Cards) {
+				at = entry.position - 1
+			}
+			data.Cards = append(data.Cards[:at], append([]Card{card}, data.Cards[at:]...)...)
+			return &card
+		}
+	}
+	return nil
+}
+
+// purgeCard permanently removes a card from the trash
+// Returns the removed entry, or nil if it wasn't in the trash
+func purgeCard(data *CellBlocksData, cardID string) *TrashedCard {
+	for i, entry := range data.Trash {
+		if entry.ID == cardID {
+			data.Trash = append(data.Trash[:i], data.Trash[i+1:]...)
+			return &entry
+		}
+	}
+	return nil
+}
+
+// purgeExpiredTrash removes trash entries older than the retention period
+// Returns the number of cards purged (retention <= 0 disables purging)
+func purgeExpiredTrash(data *CellBlocksData, retention time.Duration, now time.Time) int {
+	if retention <= 0 || len(data.Trash) == 0 {
+		return 0
+	}
+
+	cutoff := now.Add(-retention).UnixMilli()
+	kept := data.Trash[:0]
+	purged := 0
+	for _, entry := range data.Trash {
+		if entry.DeletedAt < cutoff {
+			purged++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	data.Trash = kept
+
+	return purged
+}
+
+// trashRetention converts the configured retention days to a duration
+func (m *Model) trashRetention() time.Duration {
+	return time.Duration(m.Config.TrashRetentionDays) * 24 * time.Hour
+}
+
+// deleteCard moves a card to the trash and saves (undoable)
+func (m *Model) deleteCard(cardID string) tea.Cmd {
+	if m.Data == nil {
+		return nil
+	}
+
+	i := findCardIndex(m.Data.Cards, cardID)
+	if i < 0 {
+		return nil
+	}
+
+	card := m.Data.Cards[i]
+	return m.execute(trashCardCommand{card: card, deletedAt: time.Now().UnixMilli()},
+		fmt.Sprintf("🗑 Moved '%s' to trash (u to undo)", card.Title))
+}
+
+// restoreTrashedCard restores a card from the trash and saves (undoable)
+func (m *Model) restoreTrashedCard(entry TrashedCard) tea.Cmd {
+	cmd := m.execute(restoreCardCommand{entry: entry},
+		fmt.Sprintf("♻ Restored '%s'", entry.Title))
+	m.clampTrashCursor()
+	return cmd
+}
+
+// purgeTrashedCard permanently deletes a card from the trash and saves
+// Undo keeps it recoverable for the rest of the session only
+func (m *Model) purgeTrashedCard(entry TrashedCard) tea.Cmd {
+	cmd := m.execute(purgeCardCommand{entry: entry},
+		fmt.Sprintf("✗ Permanently deleted '%s'", entry.Title))
+	m.clampTrashCursor()
+	return cmd
+}
+
+// emptyTrash permanently deletes every trashed card and saves
+func (m *Model) emptyTrash() tea.Cmd {
+	if m.Data == nil || len(m.Data.Trash) == 0 {
+		return nil
+	}
+
+	count := len(m.Data.Trash)
+	var commands []libraryCommand
+	for _, entry := range m.Data.Trash {
+		commands = append(commands, purgeCardCommand{entry: entry})
+	}
+
+	m.TrashCursorIndex = 0
+	return m.execute(batchCommand{label: fmt.Sprintf("empty trash (%d card(s))", count), commands: commands},
+		fmt.Sprintf("✗ Emptied trash (%d card(s))", count))
+}
+
+// clampTrashCursor keeps the trash cursor within the trash list
+func (m *Model) clampTrashCursor() {
+	if m.Data == nil {
+		return
+	}
+	if m.TrashCursorIndex >= len(m.Data.Trash) {
+		m.TrashCursorIndex = max(0, len(m.Data.Trash)-1)
+	}
+}
+
+// getTrashedCardAtCursor returns the trash entry under the cursor, or nil if none
+func (m *Model) getTrashedCardAtCursor() *TrashedCard {
+	if m.Data == nil || m.TrashCursorIndex < 0 || m.TrashCursorIndex >= len(m.Data.Trash) {
+		return nil
+	}
+	return &m.Data.Trash[m.TrashCursorIndex]
+}
+
+// trashDaysRemaining returns how many days are left before an entry is auto-purged
+// Returns -1 when auto-purge is disabled
+func (m *Model) trashDaysRemaining(entry *TrashedCard, now time.Time) int {
+	retention := m.trashRetention()
+	if retention <= 0 {
+		return -1
+	}
+	expires := time.UnixMilli(entry.DeletedAt).Add(retention)
+	return max(0, int(expires.Sub(now).Hours()/24))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrashAndRestoreCard(t *testing.T) {
+	data := &CellBlocksData{
+		Cards: []Card{
+			{ID: "a", Title: "Docker Run", CategoryID: "docker"},
+			{ID: "b", Title: "Git Log", CategoryID: "git"},
+		},
+	}
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	entry := trashCard(data, "a", now)
+	if entry == nil {
+		t.Fatal("trashCard() returned nil for existing card")
+	}
+	if len(data.Cards) != 1 || len(data.Trash) != 1 {
+		t.Fatalf("after trash: %d cards, %d trashed, want 1 and 1", len(data.Cards), len(data.Trash))
+	}
+	if data.Trash[0].CategoryID != "docker" || data.Trash[0].DeletedAt != now.UnixMilli() {
+		t.Errorf("trash entry = %+v, want original category and deletion time", data.Trash[0])
+	}
+
+	if trashCard(data, "missing", now) != nil {
+		t.Error("trashCard() should return nil for unknown card")
+	}
+
+	card := restoreCard(data, "a")
+	if card == nil || card.CategoryID != "docker" {
+		t.Fatalf("restoreCard() = %+v, want card in original category", card)
+	}
+	if len(data.Cards) != 2 || len(data.Trash) != 0 {
+		t.Errorf("after restore: %d cards, %d trashed, want 2 and 0", len(data.Cards), len(data.Trash))
+	}
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	data := &CellBlocksData{
+		Trash: []TrashedCard{
+			{Card: Card{ID: "old"}, DeletedAt: now.Add(-40 * 24 * time.Hour).UnixMilli()},
+			{Card: Card{ID: "new"}, DeletedAt: now.Add(-2 * 24 * time.Hour).UnixMilli()},
+		},
+	}
+
+	if purged := purgeExpiredTrash(data, 0, now); purged != 0 {
+		t.Errorf("zero retention purged %d, want 0", purged)
+	}
+
+	purged := purgeExpiredTrash(data, 30*24*time.Hour, now)
+	if purged != 1 {
+		t.Errorf("purgeExpiredTrash() = %d, want 1", purged)
+	}
+	if len(data.Trash) != 1 || data.Trash[0].ID != "new" {
+		t.Errorf("remaining trash = %+v, want only 'new'", data.Trash)
+	}
+}
@@ -7,13 +7,24 @@ import "time"
 
 // Card represents a CellBlocks card (command, prompt, snippet, etc.)
 type Card struct {
-	ID         string `json:"id"`
-	Title      string `json:"title"`
-	Content    string `json:"content"`
-	CategoryID string `json:"categoryId"`
-	CreatedAt  int64  `json:"createdAt"`
-	UpdatedAt  int64  `json:"updatedAt"`
-	ImageID    string `json:"imageId,omitempty"`
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	CategoryID string   `json:"categoryId"`
+	CreatedAt  int64    `json:"createdAt"`
+	UpdatedAt  int64    `json:"updatedAt"`
+	ImageID    string   `json:"imageId,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Starred    bool     `json:"starred,omitempty"`
+	Order      int      `json:"order,omitempty"` // Manual position within category (1-based, 0 = unplaced)
+
+	// ContentType is "markdown", "code" or "plain" (empty = category default, then markdown)
+	ContentType string `json:"contentType,omitempty"`
+	Language    string `json:"language,omitempty"` // Chroma lexer name for code cards (empty = auto-detect)
+
+	// Fields holds custom metadata values keyed by FieldDef.Key (bools are "true"/"false")
+	// Treat as immutable - history commands keep before/after copies of cards
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 // Category represents a card category with color theming
@@ -23,14 +34,42 @@ type Category struct {
 	Color            string `json:"color"`
 	Hidden           bool   `json:"hidden,omitempty"`
 	ParentCategoryID string `json:"parentCategoryId,omitempty"`
+
+	// CopyFirstCodeBlock makes a plain copy (c/Enter) take the first fenced code block
+	CopyFirstCodeBlock bool `json:"copyFirstCodeBlock,omitempty"`
+
+	// Default content type and language for cards that don't set their own
+	ContentType string `json:"contentType,omitempty"`
+	Language    string `json:"language,omitempty"`
+
+	// Fields is the metadata schema for cards in this category
+	Fields []FieldDef `json:"fields,omitempty"`
+}
+
+// FieldDef describes one typed metadata field in a category schema
+type FieldDef struct {
+	Key     string   `json:"key"`
+	Label   string   `json:"label,omitempty"`   // Display name (defaults to Key)
+	Type    string   `json:"type"`              // "text", "enum", "bool" or "url"
+	Options []string `json:"options,omitempty"` // Allowed values for enum fields
+}
+
+// TrashedCard is a deleted card kept in the data file until it is purged
+// The embedded Card keeps its original category so it can be restored
+type TrashedCard struct {
+	Card
+	DeletedAt int64 `json:"deletedAt"`
+
+	position int // 1-based index in Cards before trashing, 0 = unknown (not saved)
 }
 
 // CellBlocksData is the root structure matching cellblocks-data.json
 type CellBlocksData struct {
-	Version    string     `json:"version,omitempty"`
-	ExportedAt string     `json:"exportedAt,omitempty"`
-	Cards      []Card     `json:"cards"`
-	Categories []Category `json:"categories"`
+	Version    string        `json:"version,omitempty"`
+	ExportedAt string        `json:"exportedAt,omitempty"`
+	Cards      []Card        `json:"cards"`
+	Categories []Category    `json:"categories"`
+	Trash      []TrashedCard `json:"trash,omitempty"`
 }
 
 // ViewMode defines the current view layout
@@ -43,11 +82,16 @@ const (
 	ViewDetail
 	ViewCategoryFilter
 	ViewCardCreate
+	ViewTrash
+	ViewStats
+	ViewTemplatize
+	ViewRun
 )
 
 // Model is the main application state (Bubbletea Model)
 type Model struct {
 	// Data
+	Config        Config // Local settings from config.json
 	Data          *CellBlocksData
 	FilteredCards []Card
 	CategoryMap   map[string]Category // Fast category lookup by ID
@@ -58,6 +102,9 @@ type Model struct {
 	PreviewScrollOffset int // Scroll position within preview content
 	ScrollOffset       int
 	SelectedCategories map[string]bool // Set of selected category IDs
+	FavoritesOnly      bool            // Virtual "★ Favorites" category filter
+	SetAsideFilters    *filterState    // Filters cleared to open a hidden card, restored when detail view closes
+	PinFavorites       bool            // Show starred cards first in list/grid views
 
 	// Preview cache - TFE-style approach
 	CachedPreviewContent string // Pre-rendered markdown content
@@ -66,6 +113,8 @@ type Model struct {
 	CachedDetailWidth    int    // Width the detail cache was rendered for
 	PreviewRenderPending bool   // True when async preview render is in progress
 	DetailRenderPending  bool   // True when async detail render is in progress
+	DetailRenderSource   string // Content the detail cache was (or is being) rendered from
+	DetailRenderSeq      int    // Bumped per debounced detail render; stale ticks are dropped
 
 	// View mode
 	ViewMode           ViewMode
@@ -79,19 +128,78 @@ type Model struct {
 	SortDirection string // "asc", "desc"
 
 	// Category filter screen
-	FilterCursorIndex int // Selected category in filter screen
+	FilterCursorIndex int // Selected row in filter screen (0 = ★ Favorites, then categories)
+
+	// Trash screen
+	TrashCursorIndex int // Selected card in trash screen
+
+	// Stats screen
+	StatsScrollOffset int      // Scroll position in stats dashboard
+	StatsLines        []string // Dashboard body, computed when it opens (see refreshStats)
+
+	// Templatize review (nil unless ViewTemplatize, see templatize.go)
+	Templatize *TemplatizeSession
+
+	// Running command card (nil unless ViewRun, see runner.go)
+	Run *RunSession
+
+	// Modal dialogs (nil when closed)
+	Confirm *ConfirmDialog
+	Prompt  *PromptDialog
+	Picker  *PickerDialog
+
+	// Multi-selection for bulk actions
+	MarkedCards map[string]bool // Set of marked card IDs
+
+	// Usage tracking (local sidecar file, see usage.go)
+	Usage    *UsageData
+	ListSort string // List/grid ordering: "" (library order), "frecency" or "manual"
+
+	// Remembered {{variable}} values (local sidecar file, see varhistory.go)
+	VarHistory *VarHistoryData
+
+	// Mouse drag to reorder (list view)
+	DragCardID string // Card being dragged ("" = no drag)
+	DragOverID string // Card currently under the pointer
+
+	// Search
+	SearchQuery  string // Active search query (empty = no search)
+	SearchActive bool   // Whether the search box has keyboard focus
+
+	// Undo/redo history of library mutations (session only)
+	UndoStack []libraryCommand
+	RedoStack []libraryCommand
 
 	// Card creation form
 	NewCardTitle      string
 	NewCardContent    string
 	NewCardCategoryID string
-	CreateFormField   int // 0=title, 1=content, 2=category
+	CreateFormField   int // 0=title, 1=content, 2=category, 3+ = category schema fields
+	NewCardFields     map[string]string // Custom field values being edited
+	CreateFormError   string            // Validation error shown in the form
+	EditingCardID     string            // Card being edited ("" = creating a new card)
+	EditReturnMode    ViewMode          // View to return to after editing
+
+	// Extra table columns (custom field keys)
+	TableFields []string
 
 	// Template editing
 	TemplateVars      map[string]string // Variable name -> user input value
 	DetectedVars      []string          // Variables detected in current card
+	DetectedDecls     []TemplateVar     // Declarations (type, default) for DetectedVars, same order
+	BuiltinResults    []builtinResult   // Resolved {{@built-ins}} of the current card
 	TemplateFormField int               // Currently focused template input field
 	ShowTemplateForm  bool              // Whether template form is visible in detail view
+	VarHistoryIndex   int               // Entry picked from the focused field's history (-1 = dropdown closed)
+	ShowRawTemplate   bool              // Show the template as written instead of filled in (Ctrl+P)
+	ActiveProfile     string            // Variable profile filling the form ("" = none, see profiles.go)
+
+	// Cursor and undo state of the create form's and template form's text fields
+	TextInputs map[string]*textInput // Keyed by field (see textinput.go)
+
+	// Wiki-style links in detail view
+	DetailLinkIndex int      // Link focused with Tab (-1 = none)
+	DetailBackStack []string // Card IDs to return to with b/Backspace
 
 	// Terminal size
 	Width  int
@@ -124,7 +232,9 @@ type dataLoadErrorMsg struct {
 
 // cardCopiedMsg is sent when a card is copied to clipboard
 type cardCopiedMsg struct {
-	cardTitle string
+	cardTitle      string
+	cardIDs        []string // Cards whose content was copied (for usage tracking)
+	templateFilled bool     // Whether a filled template was copied
 }
 
 // copyErrorMsg is sent when clipboard copy fails
@@ -132,11 +242,32 @@ type copyErrorMsg struct {
 	err error
 }
 
+// usageSaveErrorMsg is sent when a local sidecar file (usage, variable history) can't be written
+type usageSaveErrorMsg struct {
+	err error
+}
+
 // cardSavedMsg is sent when a new card is successfully saved
 type cardSavedMsg struct {
 	card *Card
 }
 
+// dataSavedMsg is sent when the data file is written after a library change
+type dataSavedMsg struct {
+	message string // Notification to show (e.g. "Moved 'X' to trash")
+}
+
+// cardsExportedMsg is sent when marked cards were written to an export file
+type cardsExportedMsg struct {
+	path  string
+	count int
+}
+
+// exportErrorMsg is sent when marked cards couldn't be exported
+type exportErrorMsg struct {
+	err error
+}
+
 // cardSaveErrorMsg is sent when card saving fails
 type cardSaveErrorMsg struct {
 	err error
@@ -162,6 +293,12 @@ type previewRenderCompleteMsg struct {
 type detailRenderCompleteMsg struct {
 	content string
 	width   int
+	source  string // Content that was rendered (see detailSource)
+}
+
+// detailRenderDebounceMsg is sent once typing into the template form pauses
+type detailRenderDebounceMsg struct {
+	seq int // Matches Model.DetailRenderSeq unless more typing followed
 }
 
 // resizeDebounceMsg is sent after window resize debounce delay
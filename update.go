@@ -31,6 +31,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case resizeDebounceMsg:
 		// Only re-render if size matches (prevents stale renders)
 		if msg.width == m.Width && msg.height == m.Height {
+			if m.ViewMode == ViewStats {
+				m.refreshStats()
+			}
 			var cmds []tea.Cmd
 			if m.ShowPreview {
 				cmds = append(cmds, m.populatePreviewCacheAsync())
@@ -51,6 +54,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if modTime, err := GetFileModTime(DefaultDataPath); err == nil {
 			m.LastFileModTime = modTime
 		}
+		// Auto-purge trash entries past the retention period
+		if purged := purgeExpiredTrash(m.Data, m.trashRetention(), time.Now()); purged > 0 {
+			return m, tea.Batch(
+				m.saveDataAsync(fmt.Sprintf("🗑 Purged %d expired card(s) from trash", purged)),
+				startFileTicker(),
+			)
+		}
 		// Start the file change checker
 		return m, startFileTicker()
 
@@ -79,6 +89,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	// Library change written to disk
+	case dataSavedMsg:
+		// Update file modification time so our own write isn't treated as external
+		if modTime, err := GetFileModTime(DefaultDataPath); err == nil {
+			m.LastFileModTime = modTime
+		}
+		if msg.message != "" {
+			m.ReloadMessage = msg.message
+			m.ReloadMessageTime = time.Now()
+		}
+		return m, nil
+
+	// Marked cards exported
+	case cardsExportedMsg:
+		m.ReloadMessage = fmt.Sprintf("📤 Exported %d card(s) to %s", msg.count, msg.path)
+		m.ReloadMessageTime = time.Now()
+		return m, nil
+
 	// Card save failed
 	case cardSaveErrorMsg:
 		m.Error = msg.err
@@ -88,6 +116,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case cardCopiedMsg:
 		m.ReloadMessage = "✓ Copied to clipboard"
 		m.ReloadMessageTime = time.Now()
+		return m, m.recordUsage(msg.cardIDs, msg.templateFilled)
+
+	// Usage sidecar couldn't be written - not fatal, just warn
+	case usageSaveErrorMsg:
+		m.ReloadMessage = "⚠ " + msg.err.Error()
+		m.ReloadMessageTime = time.Now()
+		return m, nil
+
+	// Export failed - say so without leaving the card list
+	case exportErrorMsg:
+		m.ReloadMessage = "⚠ " + msg.err.Error()
+		m.ReloadMessageTime = time.Now()
+		return m, nil
+
+	// An interactive command is ready - hand it the terminal
+	case runExecMsg:
+		if m.Run != msg.session {
+			return m, nil
+		}
+		return m, execInteractive(msg)
+
+	// A command card finished running
+	case runFinishedMsg:
+		m.finishRun(msg)
+		return m, nil
+
+	// A {{secret:...}} couldn't be looked up - nothing was copied
+	case secretErrorMsg:
+		m.ReloadMessage = "⚠ " + msg.err.Error()
+		m.ReloadMessageTime = time.Now()
 		return m, nil
 
 	// Clipboard copy failed
@@ -106,8 +164,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.PreviewRenderPending = false
 		return m, nil
 
+	// Built-in template variables resolved
+	case builtinsResolvedMsg:
+		m.applyBuiltinResults(msg)
+		return m, m.scheduleDetailRender()
+
+	// Typing into the template form paused - re-render the filled preview
+	case detailRenderDebounceMsg:
+		if msg.seq == m.DetailRenderSeq && m.ViewMode == ViewDetail {
+			return m, m.populateDetailCacheAsync()
+		}
+		return m, nil
+
 	// Detail markdown rendering completed
 	case detailRenderCompleteMsg:
+		// A newer render is on its way
+		if msg.source != m.DetailRenderSource {
+			return m, nil
+		}
 		m.CachedDetailContent = msg.content
 		m.CachedDetailWidth = msg.width
 		m.DetailRenderPending = false
@@ -125,6 +199,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.Data = msg.data
 		m.buildCategoryMap()
 		m.updateFilteredCards()
+		if m.ViewMode == ViewStats {
+			m.refreshStats()
+		}
 		// Update file modification time
 		if modTime, err := GetFileModTime(DefaultDataPath); err == nil {
 			m.LastFileModTime = modTime
@@ -157,6 +234,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeyPress processes keyboard input
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Modal dialogs capture all input until answered
+	if m.hasDialog() {
+		return m.handleDialogInput(msg)
+	}
+
+	// Search box captures typing while focused
+	if m.SearchActive {
+		return m.handleSearchInput(msg)
+	}
+
+	// Switching the variable profile works everywhere, even while typing in the template form
+	if msg.String() == "ctrl+o" && m.ViewMode != ViewCardCreate {
+		return m, m.cycleProfile()
+	}
+
+	// The templatize review owns the keyboard ("n" rejects a suggestion, Esc cancels)
+	if m.ViewMode == ViewTemplatize && m.Templatize != nil && msg.String() != "ctrl+c" {
+		return m.handleTemplatizeInput(msg)
+	}
+
+	// The run screen owns the keyboard too (Esc stops a running command)
+	if m.ViewMode == ViewRun && m.Run != nil {
+		return m.handleRunInput(msg)
+	}
+
+	// Form text fields get keys before the shortcuts (Esc still leaves the form)
+	if m.textInputFocused() && msg.String() != "esc" && msg.String() != "ctrl+c" {
+		if m.ViewMode == ViewCardCreate {
+			return m.handleCardCreateInput(msg)
+		}
+		return m.handleDetailKey(msg)
+	}
+
 	// Global shortcuts that always work
 	switch msg.String() {
 	case "ctrl+c", "q":
@@ -181,18 +291,43 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		// Exit special screens back to main view
-		if m.ViewMode == ViewCategoryFilter || m.ViewMode == ViewCardCreate || m.ViewMode == ViewDetail {
+		if m.ViewMode == ViewCategoryFilter || m.ViewMode == ViewCardCreate || m.ViewMode == ViewDetail || m.ViewMode == ViewTrash || m.ViewMode == ViewStats {
 			// Reset detail view state when exiting detail mode
 			m.DetailScrollOffset = 0
+			m.StatsLines = nil
 			m.ShowTemplateForm = false
+			m.DetailBackStack = nil
 			// Clear detail cache to free memory
 			if m.ViewMode == ViewDetail {
 				m.CachedDetailContent = ""
 				m.CachedDetailWidth = 0
 				m.DetailRenderPending = false
+				// Back to the list the user filtered (a favorite or link may have cleared it)
+				m.restoreFilters()
+			}
+			// Cancelled edits return to where they started
+			if m.ViewMode == ViewCardCreate && m.EditingCardID != "" {
+				m.EditingCardID = ""
+				if m.EditReturnMode == ViewDetail {
+					return m, m.openDetailView()
+				}
+				m.ViewMode = m.EditReturnMode
+				m.updateFilteredCards()
+				return m, nil
 			}
 			// Return to list view
 			m.ViewMode = ViewList
+			m.updateFilteredCards()
+			return m, nil
+		}
+		// Clear search, then multi-selection
+		if m.SearchQuery != "" {
+			m.SearchQuery = ""
+			m.updateFilteredCards()
+			return m, nil
+		}
+		if len(m.MarkedCards) > 0 {
+			m.clearMarks()
 			return m, nil
 		}
 		return m, nil
@@ -249,6 +384,8 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		default:
 			m.ViewMode = ViewList
 		}
+		// Table view has its own sort order
+		m.updateFilteredCards()
 		return m, nil
 
 	case "f":
@@ -259,87 +396,57 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "n":
-		// Open card creation screen
+	case "/":
+		// Focus the search box
 		if m.ViewMode == ViewList || m.ViewMode == ViewGrid || m.ViewMode == ViewTable {
-			m.ViewMode = ViewCardCreate
-			m.CreateFormField = 0
-			m.NewCardTitle = ""
-			m.NewCardContent = ""
-			// Default to first category if available
-			if m.Data != nil && len(m.Data.Categories) > 0 {
-				m.NewCardCategoryID = m.Data.Categories[0].ID
-			}
+			m.SearchActive = true
 		}
 		return m, nil
 
-	case "1":
-		// Sort by title (table view only)
-		if m.ViewMode == ViewTable {
-			if m.SortColumn == "title" {
-				// Toggle direction
-				if m.SortDirection == "asc" {
-					m.SortDirection = "desc"
-				} else {
-					m.SortDirection = "asc"
-				}
-			} else {
-				m.SortColumn = "title"
-				m.SortDirection = "asc"
-			}
+	case "o":
+		// Cycle list/grid ordering (library order / frecency / manual)
+		if m.ViewMode == ViewList || m.ViewMode == ViewGrid {
+			m.cycleListSort()
+			m.ReloadMessage = "↕ Sorted by " + listSortLabel(m.ListSort)
+			m.ReloadMessageTime = time.Now()
 		}
 		return m, nil
 
-	case "2":
-		// Sort by category (table view only)
-		if m.ViewMode == ViewTable {
-			if m.SortColumn == "category" {
-				// Toggle direction
-				if m.SortDirection == "asc" {
-					m.SortDirection = "desc"
-				} else {
-					m.SortDirection = "asc"
-				}
-			} else {
-				m.SortColumn = "category"
-				m.SortDirection = "asc"
-			}
+	case "T":
+		// Open trash screen
+		if m.ViewMode == ViewList || m.ViewMode == ViewGrid || m.ViewMode == ViewTable {
+			m.ViewMode = ViewTrash
+			m.TrashCursorIndex = 0
 		}
 		return m, nil
 
-	case "3":
-		// Sort by created date (table view only)
-		if m.ViewMode == ViewTable {
-			if m.SortColumn == "created" {
-				// Toggle direction
-				if m.SortDirection == "asc" {
-					m.SortDirection = "desc"
-				} else {
-					m.SortDirection = "asc"
-				}
-			} else {
-				m.SortColumn = "created"
-				m.SortDirection = "asc"
-			}
+	case "S":
+		// Open library statistics dashboard
+		if m.ViewMode == ViewList || m.ViewMode == ViewGrid || m.ViewMode == ViewTable {
+			m.ViewMode = ViewStats
+			m.StatsScrollOffset = 0
+			m.refreshStats()
 		}
 		return m, nil
 
-	case "4":
-		// Sort by updated date (table view only)
-		if m.ViewMode == ViewTable {
-			if m.SortColumn == "updated" {
-				// Toggle direction
-				if m.SortDirection == "asc" {
-					m.SortDirection = "desc"
-				} else {
-					m.SortDirection = "asc"
-				}
-			} else {
-				m.SortColumn = "updated"
-				m.SortDirection = "asc"
+	case "n":
+		// Open card creation screen
+		if m.ViewMode == ViewList || m.ViewMode == ViewGrid || m.ViewMode == ViewTable {
+			m.ViewMode = ViewCardCreate
+			m.CreateFormField = 0
+			m.NewCardTitle = ""
+			m.NewCardContent = ""
+			m.NewCardFields = make(map[string]string)
+			m.TextInputs = nil
+			m.CreateFormError = ""
+			m.EditingCardID = ""
+			// Default to first category if available
+			if m.Data != nil && len(m.Data.Categories) > 0 {
+				m.NewCardCategoryID = m.Data.Categories[0].ID
 			}
 		}
 		return m, nil
+
 	}
 
 	// If help is shown, don't process other keys
@@ -352,6 +459,16 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleCategoryFilterInput(msg)
 	}
 
+	// Trash screen handlers
+	if m.ViewMode == ViewTrash {
+		return m.handleTrashInput(msg)
+	}
+
+	// Stats screen only scrolls
+	if m.ViewMode == ViewStats {
+		return m.handleStatsInput(msg)
+	}
+
 	// Card creation screen handlers
 	if m.ViewMode == ViewCardCreate {
 		return m.handleCardCreateInput(msg)
@@ -359,7 +476,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	// Detail view handlers
 	if m.ViewMode == ViewDetail {
-		return m.handleDetailViewInput(msg)
+		return m.handleDetailKey(msg)
 	}
 
 	// Navigation
@@ -410,7 +527,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "pageup":
+	case "pgup":
 		m.moveSelection(-m.getVisibleCardCount())
 		// In list/table view, update preview
 		if m.ViewMode == ViewList {
@@ -418,7 +535,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "pagedown":
+	case "pgdown":
 		m.moveSelection(m.getVisibleCardCount())
 		// In list/table view, update preview
 		if m.ViewMode == ViewList {
@@ -445,62 +562,127 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "enter":
-		// Enter detail view for selected card
+	case "enter", "d":
+		// Enter detail view for selected card (d is an alternative to Enter)
+		return m, m.openDetailView()
+
+	case "c":
+		// Copy marked cards (concatenated) or the selected card to clipboard
+		if len(m.MarkedCards) > 0 {
+			return m, m.copyMarkedCards()
+		}
 		card := m.getSelectedCard()
 		if card != nil {
-			m.ViewMode = ViewDetail
-			m.DetailScrollOffset = 0
-			m.ShowPreview = false // Disable preview pane when entering detail view
-			// Clear preview cache to free memory
-			m.CachedPreviewContent = ""
-			m.CachedPreviewWidth = 0
-			m.PreviewRenderPending = false
-			// Detect template variables
-			m.DetectedVars = ExtractVariables(card.Content)
-			// Initialize template vars if we have detected vars
-			if len(m.DetectedVars) > 0 && m.TemplateVars == nil {
-				m.TemplateVars = make(map[string]string)
-			}
-			// Populate detail cache (async)
-			cmd := m.populateDetailCacheAsync()
-			// Auto-show template form if variables detected
-			m.ShowTemplateForm = len(m.DetectedVars) > 0
-			return m, cmd
+			return m, copyCardsToClipboard(m.plainCopyText(card), []string{card.ID}, false)
 		}
 		return m, nil
 
-	case "c":
-		// Copy selected card to clipboard
-		card := m.getSelectedCard()
-		if card != nil {
-			return m, copyToClipboard(card.Content)
+	case "B":
+		// Copy one fenced code block of the selected card
+		return m, m.pickCodeBlock(m.getSelectedCard())
+
+	case "v":
+		// Toggle mark on selected card (multi-selection)
+		if card := m.getSelectedCard(); card != nil {
+			m.toggleMark(card.ID)
 		}
 		return m, nil
 
-	case "d":
-		// Also enter detail view (alternative to Enter)
-		card := m.getSelectedCard()
-		if card != nil {
-			m.ViewMode = ViewDetail
-			m.DetailScrollOffset = 0
-			m.ShowPreview = false // Disable preview pane when entering detail view
-			// Clear preview cache to free memory
-			m.CachedPreviewContent = ""
-			m.CachedPreviewWidth = 0
-			m.PreviewRenderPending = false
-			// Detect template variables
-			m.DetectedVars = ExtractVariables(card.Content)
-			// Initialize template vars if we have detected vars
-			if len(m.DetectedVars) > 0 && m.TemplateVars == nil {
-				m.TemplateVars = make(map[string]string)
+	case "ctrl+a":
+		// Mark all filtered cards (or clear if all are marked)
+		m.toggleMarkAll()
+		return m, nil
+
+	case "M":
+		// Move marked (or selected) cards to a category
+		m.pickCategoryForTargets()
+		return m, nil
+
+	case "t":
+		// Add/remove tags on marked (or selected) cards
+		m.promptTagsForTargets()
+		return m, nil
+
+	case "E":
+		// Export marked (or selected) cards to a JSON file
+		return m, m.exportMarkedCards()
+
+	case "s":
+		// Star/unstar selected card
+		return m, m.toggleStar(m.getSelectedCard())
+
+	case "e":
+		// Edit selected card (title, content, category and custom fields)
+		m.openEditForm(m.getSelectedCard())
+		return m, nil
+
+	case "y":
+		// Cycle content type: category default → markdown → code → plain
+		return m, m.cycleContentType(m.getSelectedCard())
+
+	case "Y":
+		// Set the code language (makes the card a code card)
+		m.promptLanguage(m.getSelectedCard())
+		return m, nil
+
+	case "z":
+		// Turn hardcoded values into {{variables}} (review each suggestion)
+		m.startTemplatize(m.getSelectedCard())
+		return m, nil
+
+	case "r":
+		// Run a shell card (asks first; templates are filled in detail view)
+		return m, m.runFromList()
+
+	case "C":
+		// Show/hide custom field columns in table view
+		if m.ViewMode == ViewTable {
+			m.pickTableFields()
+		}
+		return m, nil
+
+	case "alt+up":
+		// Move selected card up in manual order
+		return m, m.moveCardInOrder(-1)
+
+	case "alt+down":
+		// Move selected card down in manual order
+		return m, m.moveCardInOrder(1)
+
+	case "R":
+		// Rename selected card (offers to rewrite [[links]] to it)
+		m.renameCard(m.getSelectedCard())
+		return m, nil
+
+	case "P":
+		// Pin favorites at the top of list/grid views
+		m.PinFavorites = !m.PinFavorites
+		m.updateFilteredCards()
+		return m, nil
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		n := int(msg.String()[0] - '0')
+		// Table view: number keys sort by column
+		if m.ViewMode == ViewTable {
+			if columns := m.tableColumns(); n <= len(columns) {
+				m.setSortColumn(columns[n-1])
 			}
-			// Populate detail cache (async)
-			cmd := m.populateDetailCacheAsync()
-			// Auto-show template form if variables detected
-			m.ShowTemplateForm = len(m.DetectedVars) > 0
-			return m, cmd
+			return m, nil
 		}
+		// Elsewhere: open the nth favorite
+		return m, m.jumpToFavorite(n)
+
+	case "u":
+		// Undo last library change
+		return m, m.undo()
+
+	case "ctrl+r":
+		// Redo last undone change
+		return m, m.redo()
+
+	case "x", "delete":
+		// Move marked (or selected) cards to trash (after confirmation)
+		m.confirmDeleteTargets()
 		return m, nil
 
 	case " ": // Spacebar
@@ -531,15 +713,19 @@ func (m Model) handleCategoryFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "down", "j":
-		if m.FilterCursorIndex < len(m.Data.Categories)-1 {
+		// Row 0 is the virtual favorites entry, categories follow
+		if m.FilterCursorIndex < len(m.Data.Categories) {
 			m.FilterCursorIndex++
 		}
 		return m, nil
 
 	case "enter", " ":
-		// Toggle selected category
-		if m.FilterCursorIndex >= 0 && m.FilterCursorIndex < len(m.Data.Categories) {
-			categoryID := m.Data.Categories[m.FilterCursorIndex].ID
+		// Toggle favorites filter or selected category
+		if m.FilterCursorIndex == 0 {
+			m.FavoritesOnly = !m.FavoritesOnly
+			m.updateFilteredCards()
+		} else if m.FilterCursorIndex <= len(m.Data.Categories) {
+			categoryID := m.Data.Categories[m.FilterCursorIndex-1].ID
 			m.toggleCategory(categoryID)
 		}
 		return m, nil
@@ -556,51 +742,199 @@ func (m Model) handleCategoryFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Clear all filters
 		m.clearFilters()
 		return m, nil
+
+	case "b":
+		// Toggle "copy first code block by default" for the highlighted category
+		if m.FilterCursorIndex > 0 && m.FilterCursorIndex <= len(m.Data.Categories) {
+			return m, m.toggleCopyFirstCodeBlock(m.Data.Categories[m.FilterCursorIndex-1].ID)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// handleSearchInput processes typing while the search box is focused
+// Results update as you type; Enter keeps the query, Esc clears it
+func (m Model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEnter:
+		m.SearchActive = false
+		return m, nil
+
+	case tea.KeyEsc:
+		m.SearchActive = false
+		m.SearchQuery = ""
+
+	case tea.KeyBackspace:
+		if m.SearchQuery == "" {
+			m.SearchActive = false
+			return m, nil
+		}
+		runes := []rune(m.SearchQuery)
+		m.SearchQuery = string(runes[:len(runes)-1])
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.SearchQuery += string(msg.Runes)
+
+	default:
+		return m, nil
+	}
+
+	// New results - start from the best match
+	m.updateFilteredCards()
+	m.SelectedIndex = 0
+	m.PreviewedIndex = 0
+	m.ScrollOffset = 0
+	return m, nil
+}
+
+// handleTrashInput processes input in the trash screen
+func (m Model) handleTrashInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.Data == nil {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.TrashCursorIndex > 0 {
+			m.TrashCursorIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.TrashCursorIndex < len(m.Data.Trash)-1 {
+			m.TrashCursorIndex++
+		}
+		return m, nil
+
+	case "r", "enter":
+		// Restore card to its original category
+		if entry := m.getTrashedCardAtCursor(); entry != nil {
+			return m, m.restoreTrashedCard(*entry)
+		}
+		return m, nil
+
+	case "x", "delete":
+		// Permanently delete card (after confirmation)
+		if entry := m.getTrashedCardAtCursor(); entry != nil {
+			target := *entry
+			m.askConfirm(fmt.Sprintf("Permanently delete '%s'? Only undoable until you quit.", target.Title),
+				func(m *Model) tea.Cmd {
+					return m.purgeTrashedCard(target)
+				})
+		}
+		return m, nil
+
+	case "X":
+		// Empty the whole trash (after confirmation)
+		if len(m.Data.Trash) > 0 {
+			m.askConfirm(fmt.Sprintf("Permanently delete all %d card(s) in trash?", len(m.Data.Trash)),
+				func(m *Model) tea.Cmd {
+					return m.emptyTrash()
+				})
+		}
+		return m, nil
+
+	case "u":
+		return m, m.undo()
+
+	case "ctrl+r":
+		return m, m.redo()
+	}
+
+	return m, nil
+}
+
+// handleStatsInput scrolls the stats dashboard
+func (m Model) handleStatsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.Data == nil {
+		return m, nil
 	}
+	maxOffset := max(0, len(m.StatsLines)-statsVisibleLines(m))
 
+	switch msg.String() {
+	case "up", "k":
+		m.StatsScrollOffset--
+	case "down", "j":
+		m.StatsScrollOffset++
+	case "pgup":
+		m.StatsScrollOffset -= statsVisibleLines(m)
+	case "pgdown":
+		m.StatsScrollOffset += statsVisibleLines(m)
+	case "home":
+		m.StatsScrollOffset = 0
+	case "end":
+		m.StatsScrollOffset = maxOffset
+	}
+	m.StatsScrollOffset = max(0, min(m.StatsScrollOffset, maxOffset))
 	return m, nil
 }
 
+// confirmDeleteCard asks before moving a card to the trash
+func (m *Model) confirmDeleteCard(card *Card) {
+	cardID := card.ID
+	m.askConfirm(fmt.Sprintf("Move '%s' to trash?", card.Title),
+		func(m *Model) tea.Cmd {
+			// Leave detail view - the card is no longer in the library
+			if m.ViewMode == ViewDetail {
+				m.ViewMode = ViewList
+				m.DetailScrollOffset = 0
+				m.ShowTemplateForm = false
+				m.DetailBackStack = nil
+				m.CachedDetailContent = ""
+				m.CachedDetailWidth = 0
+				m.restoreFilters()
+			}
+			return m.deleteCard(cardID)
+		})
+}
+
 // handleCardCreateInput processes input in card creation screen
 func (m Model) handleCardCreateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	field := m.focusedSchemaField()
+	fieldCount := m.createFormFieldCount()
+
+	// Title, content and text fields take editing keys first
+	if m.editCreateField(msg) {
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "tab":
 		// Move to next field
-		m.CreateFormField = (m.CreateFormField + 1) % 3
+		m.CreateFormField = (m.CreateFormField + 1) % fieldCount
 		return m, nil
 
 	case "shift+tab":
 		// Move to previous field
-		m.CreateFormField = (m.CreateFormField + 2) % 3 // +2 is same as -1 mod 3
+		m.CreateFormField = (m.CreateFormField + fieldCount - 1) % fieldCount
 		return m, nil
 
 	case "ctrl+s", "ctrl+enter":
-		// Save card
+		// Save card (new or edited)
+		m.CreateFormError = ""
+		if m.EditingCardID != "" {
+			return m, m.saveEditedCard()
+		}
 		return m, m.saveNewCard()
 
-	case "backspace":
-		// Delete character from current field
-		switch m.CreateFormField {
-		case 0: // Title
-			if len(m.NewCardTitle) > 0 {
-				m.NewCardTitle = m.NewCardTitle[:len(m.NewCardTitle)-1]
-			}
-		case 1: // Content
-			if len(m.NewCardContent) > 0 {
-				m.NewCardContent = m.NewCardContent[:len(m.NewCardContent)-1]
-			}
+	case "enter", " ":
+		// Bool fields toggle
+		if field != nil && field.Type == FieldBool {
+			m.toggleBoolField(field)
 		}
 		return m, nil
 
-	case "enter":
-		// In content field, add newline
-		if m.CreateFormField == 1 {
-			m.NewCardContent += "\n"
+	case "up", "k", "left":
+		// Category and enum fields cycle
+		if field != nil && field.Type == FieldEnum {
+			m.cycleEnumField(field, -1)
+			return m, nil
 		}
-		return m, nil
-
-	case "up", "k":
-		// In category field, move to previous category
 		if m.CreateFormField == 2 && m.Data != nil {
 			for i, cat := range m.Data.Categories {
 				if cat.ID == m.NewCardCategoryID && i > 0 {
@@ -611,8 +945,11 @@ func (m Model) handleCardCreateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "down", "j":
-		// In category field, move to next category
+	case "down", "j", "right":
+		if field != nil && field.Type == FieldEnum {
+			m.cycleEnumField(field, 1)
+			return m, nil
+		}
 		if m.CreateFormField == 2 && m.Data != nil {
 			for i, cat := range m.Data.Categories {
 				if cat.ID == m.NewCardCategoryID && i < len(m.Data.Categories)-1 {
@@ -624,17 +961,6 @@ func (m Model) handleCardCreateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Type characters into current field
-	if len(msg.String()) == 1 {
-		switch m.CreateFormField {
-		case 0: // Title
-			m.NewCardTitle += msg.String()
-		case 1: // Content
-			m.NewCardContent += msg.String()
-		}
-		return m, nil
-	}
-
 	return m, nil
 }
 
@@ -652,6 +978,16 @@ func (m Model) handleCardCreateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // 	return m, nil
 // }
 
+// handleDetailKey handles a detail view key and re-renders the filled preview once typing pauses
+func (m Model) handleDetailKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	model, cmd := m.handleDetailViewInput(msg)
+	if next, ok := model.(Model); ok {
+		cmd = tea.Batch(cmd, next.scheduleDetailRender())
+		return next, cmd
+	}
+	return model, cmd
+}
+
 // handleDetailViewInput processes input in detail view mode
 func (m Model) handleDetailViewInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	card := m.getSelectedCard()
@@ -659,29 +995,81 @@ func (m Model) handleDetailViewInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Any key but ↑/↓ closes the history dropdown
+	if msg.String() != "up" && msg.String() != "down" {
+		m.VarHistoryIndex = -1
+	}
+
+	// The focused text field of the template form takes editing keys first
+	if m.editTemplateField(msg) {
+		return m, nil
+	}
+
 	switch msg.String() {
 	case "up", "k":
-		// Scroll content up
+		// Newer value from the focused field's history, otherwise scroll content up
+		if msg.String() == "up" && m.browseVarHistory(card, -1) {
+			return m, nil
+		}
 		m.DetailScrollOffset = max(0, m.DetailScrollOffset-1)
 		return m, nil
 
 	case "down", "j":
-		// Scroll content down
+		// Older value from the focused field's history, otherwise scroll content down
+		if msg.String() == "down" && m.browseVarHistory(card, 1) {
+			return m, nil
+		}
 		m.DetailScrollOffset++
 		return m, nil
 
-	case "pageup":
+	case "pgup":
 		// Scroll up by page
 		m.DetailScrollOffset = max(0, m.DetailScrollOffset-10)
 		return m, nil
 
-	case "pagedown":
+	case "pgdown":
 		// Scroll down by page
 		m.DetailScrollOffset += 10
 		return m, nil
 
-	case "t":
-		// Toggle template form visibility
+	case "x", "delete":
+		// Move card to trash (after confirmation) - only when not typing into the form
+		if !m.ShowTemplateForm {
+			m.confirmDeleteCard(card)
+			return m, nil
+		}
+
+	case "s":
+		// Star/unstar card - only when not typing into the form
+		if !m.ShowTemplateForm {
+			return m, m.toggleStar(card)
+		}
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		// Jump to another favorite - only when not typing into the form
+		if !m.ShowTemplateForm {
+			return m, m.jumpToFavorite(int(msg.String()[0] - '0'))
+		}
+
+	case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+		// Copy the nth code block (works while typing - text fields ignore Alt+digits)
+		return m, m.copyCodeBlock(card, int(msg.String()[4]-'0'))
+
+	case "B":
+		// Pick a code block to copy - only when not typing into the form
+		if !m.ShowTemplateForm {
+			return m, m.pickCodeBlock(card)
+		}
+
+	case "ctrl+p":
+		// Switch the content between the filled and the raw template
+		if m.ShowTemplateForm {
+			m.ShowRawTemplate = !m.ShowRawTemplate
+		}
+		return m, nil
+
+	case "t", "ctrl+t":
+		// Toggle template form visibility (Ctrl+T while typing - "t" types)
 		if len(m.DetectedVars) > 0 {
 			m.ShowTemplateForm = !m.ShowTemplateForm
 			if m.ShowTemplateForm {
@@ -691,58 +1079,116 @@ func (m Model) handleDetailViewInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "c":
-		// Copy card content (or filled template if form is shown)
-		var contentToCopy string
-		if m.ShowTemplateForm && len(m.DetectedVars) > 0 {
-			// Copy filled template
-			contentToCopy = FillTemplate(card.Content, m.TemplateVars)
-		} else {
-			// Copy raw content
-			contentToCopy = card.Content
+		// Copy card content (or filled template if form is shown - once it's valid - or built-ins/secrets only)
+		if m.ShowTemplateForm && len(m.DetectedVars) > 0 || len(m.DetectedVars) == 0 && (m.hasBuiltinVars() || m.hasSecretVars(card)) {
+			return m, m.copyFilledTemplate(card)
 		}
-		return m, copyToClipboard(contentToCopy)
+		// Copy raw content
+		return m, copyCardsToClipboard(m.plainCopyText(card), []string{card.ID}, false)
 
 	case "enter":
-		// Copy filled template (if template form is shown)
-		if m.ShowTemplateForm && len(m.DetectedVars) > 0 {
-			contentToCopy := FillTemplate(card.Content, m.TemplateVars)
-			return m, copyToClipboard(contentToCopy)
+		// Follow the focused [[link]] (form hidden)
+		if !m.ShowTemplateForm && m.DetailLinkIndex >= 0 {
+			return m, m.followDetailLink(card)
+		}
+		// Copy filled template (if template form is shown and valid)
+		if m.ShowTemplateForm && len(m.DetectedVars) > 0 || len(m.DetectedVars) == 0 && (m.hasBuiltinVars() || m.hasSecretVars(card)) {
+			return m, m.copyFilledTemplate(card)
 		}
 		// Otherwise, just copy raw content
-		return m, copyToClipboard(card.Content)
+		return m, copyCardsToClipboard(m.plainCopyText(card), []string{card.ID}, false)
 
 	case "tab":
 		// Navigate to next template field (if template form is shown)
 		if m.ShowTemplateForm && len(m.DetectedVars) > 0 {
 			m.TemplateFormField = (m.TemplateFormField + 1) % len(m.DetectedVars)
+		} else {
+			// Otherwise cycle through [[links]] and backlinks
+			m.cycleDetailLink(card, 1)
 		}
 		return m, nil
 
 	case "shift+tab":
-		// Navigate to previous template field
+		// Navigate to previous template field (or link)
 		if m.ShowTemplateForm && len(m.DetectedVars) > 0 {
 			m.TemplateFormField = (m.TemplateFormField - 1 + len(m.DetectedVars)) % len(m.DetectedVars)
+		} else {
+			m.cycleDetailLink(card, -1)
 		}
 		return m, nil
 
-	case "backspace":
-		// Delete character from current template field
-		if m.ShowTemplateForm && len(m.DetectedVars) > 0 && m.TemplateFormField < len(m.DetectedVars) {
-			varName := m.DetectedVars[m.TemplateFormField]
-			if len(m.TemplateVars[varName]) > 0 {
-				m.TemplateVars[varName] = m.TemplateVars[varName][:len(m.TemplateVars[varName])-1]
+	case "left", "right":
+		// Pick the previous/next option of a choice field, or toggle a bool field
+		if v := m.focusedTemplateVar(); v != nil {
+			delta := 1
+			if msg.String() == "left" {
+				delta = -1
 			}
+			m.stepTemplateVar(v, delta)
 		}
 		return m, nil
-	}
 
-	// Type characters into current template field
-	if m.ShowTemplateForm && len(m.DetectedVars) > 0 && len(msg.String()) == 1 {
-		if m.TemplateFormField < len(m.DetectedVars) {
-			varName := m.DetectedVars[m.TemplateFormField]
-			m.TemplateVars[varName] += msg.String()
+	case "backspace":
+		// Choice/bool fields go back to their default (text fields handle it above)
+		if v := m.focusedTemplateVar(); v != nil {
+			delete(m.TemplateVars, v.Name)
+			return m, nil
+		}
+		// Otherwise go back to the card we followed a link from
+		return m, m.followBackLink()
+
+	case "b":
+		// Go back to the card we followed a link from - only when not typing into the form
+		if !m.ShowTemplateForm {
+			return m, m.followBackLink()
 		}
+
+	case "e":
+		// Edit card - only when not typing into the form
+		if !m.ShowTemplateForm {
+			m.openEditForm(card)
+			return m, nil
+		}
+
+	case "R":
+		// Rename card (offers to rewrite [[links]] to it) - only when not typing into the form
+		if !m.ShowTemplateForm {
+			m.renameCard(card)
+			return m, nil
+		}
+
+	case "y":
+		// Cycle content type - only when not typing into the form
+		if !m.ShowTemplateForm {
+			return m, m.cycleContentType(card)
+		}
+
+	case "Y":
+		// Set the code language - only when not typing into the form
+		if !m.ShowTemplateForm {
+			m.promptLanguage(card)
+			return m, nil
+		}
+
+	case "z":
+		// Turn hardcoded values into {{variables}}
+		m.startTemplatize(card)
 		return m, nil
+
+	case "r":
+		// Run the (filled) command - only when not typing into the form
+		if !m.ShowTemplateForm {
+			return m, m.startRun(card)
+		}
+
+	case "ctrl+x":
+		// Run the filled command, even while typing
+		return m, m.startRun(card)
+	}
+
+	// Choice and bool fields are picked, not typed (Space steps them)
+	if v := m.focusedTemplateVar(); v != nil && msg.String() == " " {
+		m.stepTemplateVar(v, 1)
 	}
 
 	return m, nil
@@ -28,8 +28,8 @@ func (m Model) handleMouseEventEnhanced(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Don't process mouse events in filter/create screens
-	if m.ViewMode == ViewCategoryFilter || m.ViewMode == ViewCardCreate {
+	// Don't process mouse events in filter/create/trash screens or while a dialog is open
+	if m.ViewMode == ViewCategoryFilter || m.ViewMode == ViewCardCreate || m.ViewMode == ViewTrash || m.ViewMode == ViewStats || m.ViewMode == ViewTemplatize || m.ViewMode == ViewRun || m.hasDialog() {
 		return m, nil
 	}
 
@@ -70,16 +70,60 @@ func (m Model) handleMouseEventEnhanced(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.MouseButtonLeft:
-		if msg.Action == tea.MouseActionRelease {
-			return m.handleLeftClick(msg)
+		switch msg.Action {
+		case tea.MouseActionPress:
+			m.startDrag(msg)
+		case tea.MouseActionMotion:
+			m.updateDrag(msg)
+		case tea.MouseActionRelease:
+			return m.handleLeftRelease(msg)
 		}
 	}
 
 	return m, nil
 }
 
+// startDrag remembers the card under the pointer so it can be dragged (list view only)
+func (m *Model) startDrag(msg tea.MouseMsg) {
+	m.DragCardID, m.DragOverID = "", ""
+	if m.ViewMode != ViewList || msg.Shift {
+		return
+	}
+	if index := m.calculateClickedCardIndex(msg); index >= 0 && index < len(m.FilteredCards) {
+		m.DragCardID = m.FilteredCards[index].ID
+		m.DragOverID = m.DragCardID
+	}
+}
+
+// updateDrag tracks which card a dragged card is over
+func (m *Model) updateDrag(msg tea.MouseMsg) {
+	if m.DragCardID == "" {
+		return
+	}
+	if index := m.calculateClickedCardIndex(msg); index >= 0 && index < len(m.FilteredCards) {
+		m.DragOverID = m.FilteredCards[index].ID
+	}
+}
+
+// handleLeftRelease drops a dragged card, or treats the release as a click
+func (m Model) handleLeftRelease(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	dragged, target := m.DragCardID, m.DragOverID
+	m.DragCardID, m.DragOverID = "", ""
+
+	if dragged != "" && target != "" && dragged != target {
+		m.LastClickIndex = -1
+		return m, m.dropCard(dragged, target)
+	}
+	return m.handleLeftClick(msg)
+}
+
 // handleLeftClick processes left mouse button clicks (single and double-click)
 func (m Model) handleLeftClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// The profile badge in the status bar switches profiles
+	if m.isProfileBadgeClick(msg) {
+		return m, m.cycleProfile()
+	}
+
 	if len(m.FilteredCards) == 0 {
 		return m, nil
 	}
@@ -92,6 +136,19 @@ func (m Model) handleLeftClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Shift+click: mark everything between the current selection and the clicked card
+	if msg.Shift {
+		m.markRange(m.SelectedIndex, clickedIndex)
+		m.SelectedIndex = clickedIndex
+		m.LastClickIndex = -1
+		if m.ViewMode == ViewGrid {
+			m.ensureGridSelectionVisible()
+		} else {
+			m.ensureListSelectionVisible()
+		}
+		return m, nil
+	}
+
 	// Double-click detection: same card clicked within 500ms
 	const doubleClickThreshold = 500 * time.Millisecond
 	now := time.Now()
@@ -103,7 +160,7 @@ func (m Model) handleLeftClick(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		card := &m.FilteredCards[clickedIndex]
 		m.LastClickIndex = -1
 		m.LastClickTime = time.Time{}
-		return m, copyToClipboard(card.Content)
+		return m, copyCardsToClipboard(m.plainCopyText(card), []string{card.ID}, false)
 	} else {
 		// Single-click: select card and update preview
 		m.SelectedIndex = clickedIndex
@@ -168,6 +225,16 @@ func (m Model) calculateListClickIndex(msg tea.MouseMsg, headerOffset int) int {
 		return -1
 	}
 
+	// Account for the pinned-favorites separator line (matches renderListViewWithHeight)
+	if separatorLine := m.listSeparatorLine(availableHeight); separatorLine >= 0 {
+		if clickedLine == separatorLine {
+			return -1
+		}
+		if clickedLine > separatorLine {
+			clickedLine--
+		}
+	}
+
 	// Calculate the card index accounting for scroll offset
 	clickedIndex := m.ScrollOffset + clickedLine
 
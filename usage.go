@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// usage.go - Usage Tracking and Frecency
+// Purpose: Record per-card copies in a local sidecar file and rank cards by frecency
+//
+// Usage lives outside cellblocks-data.json on purpose: copying a card happens far
+// more often than editing one, and shouldn't churn the synced data file.
+
+const (
+	// DefaultUsagePath is the local usage sidecar file location
+	DefaultUsagePath = "~/.config/cellblocks-tui/usage.json"
+)
+
+// usageSaves orders background saves of the usage file
+var usageSaves saveQueue
+
+// CardUsage tracks how often and how recently a card was used
+type CardUsage struct {
+	CopyCount     int   `json:"copyCount"`
+	TemplateFills int   `json:"templateFills"` // Copies of a filled template (subset of CopyCount)
+	LastUsedAt    int64 `json:"lastUsedAt"`    // Unix milliseconds
+}
+
+// UsageData is the root structure of usage.json
+type UsageData struct {
+	Cards map[string]CardUsage `json:"cards"`
+}
+
+// newUsageData returns an empty usage record
+func newUsageData() *UsageData {
+	return &UsageData{Cards: make(map[string]CardUsage)}
+}
+
+// LoadUsage reads the usage sidecar file
+// A missing file is not an error - empty usage is returned
+func LoadUsage(path string) (*UsageData, error) {
+	content, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newUsageData(), nil
+		}
+		return newUsageData(), fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	usage := newUsageData()
+	if err := json.Unmarshal(content, usage); err != nil {
+		return newUsageData(), fmt.Errorf("failed to parse usage file: %w", err)
+	}
+	if usage.Cards == nil {
+		usage.Cards = make(map[string]CardUsage)
+	}
+
+	return usage, nil
+}
+
+// SaveUsage writes the usage sidecar file, creating its directory if needed
+func SaveUsage(path string, usage *UsageData) error {
+	fullPath := expandPath(path)
+
+	content, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+	if err := writeFileAtomic(fullPath, content); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+
+	return nil
+}
+
+// recordCopy counts one use of a card
+func (u *UsageData) recordCopy(cardID string, templateFilled bool, now time.Time) {
+	entry := u.Cards[cardID]
+	entry.CopyCount++
+	if templateFilled {
+		entry.TemplateFills++
+	}
+	entry.LastUsedAt = now.UnixMilli()
+	u.Cards[cardID] = entry
+}
+
+// frecencyScore combines how often a card was used with how recently
+// Recency buckets follow the classic browser-history approach
+func frecencyScore(usage CardUsage, now time.Time) float64 {
+	if usage.CopyCount == 0 {
+		return 0
+	}
+
+	age := now.Sub(time.UnixMilli(usage.LastUsedAt))
+	var weight float64
+	switch {
+	case age < 4*24*time.Hour:
+		weight = 100
+	case age < 14*24*time.Hour:
+		weight = 70
+	case age < 31*24*time.Hour:
+		weight = 50
+	case age < 90*24*time.Hour:
+		weight = 30
+	default:
+		weight = 10
+	}
+
+	return float64(usage.CopyCount) * weight
+}
+
+// sortByFrecency orders cards by frecency (highest first); unused cards keep library order
+func sortByFrecency(cards []Card, usage *UsageData, now time.Time) []Card {
+	sorted := make([]Card, len(cards))
+	copy(sorted, cards)
+
+	if usage == nil {
+		return sorted
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return frecencyScore(usage.Cards[sorted[i].ID], now) > frecencyScore(usage.Cards[sorted[j].ID], now)
+	})
+
+	return sorted
+}
+
+// frecencyOf returns a card's current frecency score (used as a search tie-breaker)
+func (m *Model) frecencyOf(card Card) float64 {
+	if m.Usage == nil {
+		return 0
+	}
+	return frecencyScore(m.Usage.Cards[card.ID], time.Now())
+}
+
+// recordUsage counts copies of cards and saves the sidecar file in the background
+func (m *Model) recordUsage(cardIDs []string, templateFilled bool) tea.Cmd {
+	if m.Usage == nil || len(cardIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, id := range cardIDs {
+		m.Usage.recordCopy(id, templateFilled, now)
+	}
+
+	// Frecency order may have changed
+	if m.ListSort == "frecency" {
+		m.updateFilteredCards()
+	}
+
+	// Marshal now so the goroutine doesn't race with later copies
+	snapshot := &UsageData{Cards: make(map[string]CardUsage, len(m.Usage.Cards))}
+	for id, entry := range m.Usage.Cards {
+		snapshot.Cards[id] = entry
+	}
+
+	ticket := usageSaves.ticket()
+	return func() tea.Msg {
+		if err := usageSaves.save(ticket, func() error { return SaveUsage(DefaultUsagePath, snapshot) }); err != nil {
+			return usageSaveErrorMsg{err: err}
+		}
+		return nil
+	}
+}
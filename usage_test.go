@@ -0,0 +1,134 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFrecencyScore(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) int64 {
+		return now.Add(-time.Duration(days) * 24 * time.Hour).UnixMilli()
+	}
+
+	tests := []struct {
+		name  string
+		usage CardUsage
+		want  float64
+	}{
+		{"never used", CardUsage{}, 0},
+		{"used today", CardUsage{CopyCount: 2, LastUsedAt: daysAgo(0)}, 200},
+		{"used last week", CardUsage{CopyCount: 2, LastUsedAt: daysAgo(7)}, 140},
+		{"used last month", CardUsage{CopyCount: 2, LastUsedAt: daysAgo(20)}, 100},
+		{"used this quarter", CardUsage{CopyCount: 2, LastUsedAt: daysAgo(60)}, 60},
+		{"used long ago", CardUsage{CopyCount: 2, LastUsedAt: daysAgo(400)}, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frecencyScore(tt.usage, now); got != tt.want {
+				t.Errorf("frecencyScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortByFrecency(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cards := []Card{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	usage := newUsageData()
+	usage.recordCopy("c", false, now)
+	usage.recordCopy("c", true, now)
+	// Used more often, but long ago - recent use wins
+	for i := 0; i < 3; i++ {
+		usage.recordCopy("d", false, now.Add(-200*24*time.Hour))
+	}
+
+	sorted := sortByFrecency(cards, usage, now)
+	want := []string{"c", "d", "a", "b"}
+	for i, id := range want {
+		if sorted[i].ID != id {
+			t.Fatalf("sortByFrecency() order = %v, want %v", cardIDs(sorted), want)
+		}
+	}
+	if cards[0].ID != "a" {
+		t.Error("sortByFrecency() modified its input")
+	}
+	if usage.Cards["c"].TemplateFills != 1 || usage.Cards["c"].CopyCount != 2 {
+		t.Errorf("usage for c = %+v, want 2 copies and 1 template fill", usage.Cards["c"])
+	}
+}
+
+func TestSearchCardsRanking(t *testing.T) {
+	cards := []Card{
+		{ID: "content", Title: "Cleanup", Content: "docker system prune"},
+		{ID: "tag", Title: "Compose up", Tags: []string{"docker"}},
+		{ID: "contains", Title: "Run docker image"},
+		{ID: "prefix", Title: "Docker build"},
+		{ID: "prefix2", Title: "Docker logs"},
+		{ID: "none", Title: "Git log"},
+	}
+
+	got := searchCards(cards, "Docker", nil, nil)
+	want := []string{"prefix", "prefix2", "contains", "tag", "content"}
+	if ids := cardIDs(got); !equalStrings(ids, want) {
+		t.Errorf("searchCards() = %v, want %v", ids, want)
+	}
+
+	// Frecency breaks ties between equally good matches
+	tieBreak := func(card Card) float64 {
+		if card.ID == "prefix2" {
+			return 100
+		}
+		return 0
+	}
+	got = searchCards(cards, "docker", nil, tieBreak)
+	want = []string{"prefix2", "prefix", "contains", "tag", "content"}
+	if ids := cardIDs(got); !equalStrings(ids, want) {
+		t.Errorf("searchCards() with tie-break = %v, want %v", ids, want)
+	}
+}
+
+func TestUsageRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "usage.json")
+
+	usage, err := LoadUsage(path)
+	if err != nil || len(usage.Cards) != 0 {
+		t.Fatalf("LoadUsage(missing) = %+v, %v; want empty usage and no error", usage, err)
+	}
+
+	usage.recordCopy("a", true, time.UnixMilli(1700000000000))
+	if err := SaveUsage(path, usage); err != nil {
+		t.Fatalf("SaveUsage() error: %v", err)
+	}
+
+	loaded, err := LoadUsage(path)
+	if err != nil {
+		t.Fatalf("LoadUsage() error: %v", err)
+	}
+	if loaded.Cards["a"] != usage.Cards["a"] {
+		t.Errorf("loaded usage = %+v, want %+v", loaded.Cards["a"], usage.Cards["a"])
+	}
+}
+
+func cardIDs(cards []Card) []string {
+	ids := make([]string, len(cards))
+	for i, card := range cards {
+		ids[i] = card.ID
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// varhistory.go - Template Variable History
+// Purpose: Remember recent {{variable}} values per name (globally and per card)
+//
+// Like usage.json this is a local sidecar file - values typed into templates are
+// often machine-specific (hostnames, ports) and don't belong in the synced data file.
+
+const (
+	// DefaultVarHistoryPath is the local variable history file location
+	DefaultVarHistoryPath = "~/.config/cellblocks-tui/var-history.json"
+
+	// maxVarHistory is how many values are kept per variable
+	maxVarHistory = 10
+
+	// maxVarHistoryShown is how many history entries the dropdown shows at once
+	maxVarHistoryShown = 5
+)
+
+// varHistorySaves orders background saves of the variable history file
+var varHistorySaves saveQueue
+
+// VarHistoryData is the root structure of var-history.json
+// Values are most recent first
+type VarHistoryData struct {
+	Global map[string][]string            `json:"global"` // Variable name -> values
+	Cards  map[string]map[string][]string `json:"cards"`  // Card ID -> variable name -> values
+}
+
+// newVarHistoryData returns an empty history
+func newVarHistoryData() *VarHistoryData {
+	return &VarHistoryData{
+		Global: make(map[string][]string),
+		Cards:  make(map[string]map[string][]string),
+	}
+}
+
+// LoadVarHistory reads the variable history file
+// A missing file is not an error - empty history is returned
+func LoadVarHistory(path string) (*VarHistoryData, error) {
+	content, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newVarHistoryData(), nil
+		}
+		return newVarHistoryData(), fmt.Errorf("failed to read variable history: %w", err)
+	}
+
+	history := newVarHistoryData()
+	if err := json.Unmarshal(content, history); err != nil {
+		return newVarHistoryData(), fmt.Errorf("failed to parse variable history: %w", err)
+	}
+	if history.Global == nil {
+		history.Global = make(map[string][]string)
+	}
+	if history.Cards == nil {
+		history.Cards = make(map[string]map[string][]string)
+	}
+
+	return history, nil
+}
+
+// SaveVarHistory writes the variable history file, creating its directory if needed
+func SaveVarHistory(path string, history *VarHistoryData) error {
+	fullPath := expandPath(path)
+
+	content, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal variable history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create variable history directory: %w", err)
+	}
+	if err := writeFileAtomic(fullPath, content); err != nil {
+		return fmt.Errorf("failed to write variable history: %w", err)
+	}
+
+	return nil
+}
+
+// pushRecent puts value first in values, dropping an older copy and anything past maxVarHistory
+func pushRecent(values []string, value string) []string {
+	result := []string{value}
+	for _, existing := range values {
+		if existing != value && len(result) < maxVarHistory {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
+
+// record remembers a value used for a card's variable
+func (h *VarHistoryData) record(cardID, name, value string) {
+	if value == "" {
+		return
+	}
+	h.Global[name] = pushRecent(h.Global[name], value)
+	if h.Cards[cardID] == nil {
+		h.Cards[cardID] = make(map[string][]string)
+	}
+	h.Cards[cardID][name] = pushRecent(h.Cards[cardID][name], value)
+}
+
+// suggestions returns remembered values for a variable, most relevant first:
+// this card's values, then values used with the same name on other cards
+func (h *VarHistoryData) suggestions(cardID, name string) []string {
+	if h == nil {
+		return nil
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for _, values := range [][]string{h.Cards[cardID][name], h.Global[name]} {
+		for _, value := range values {
+			if !seen[value] && len(result) < maxVarHistory {
+				seen[value] = true
+				result = append(result, value)
+			}
+		}
+	}
+	return result
+}
+
+// prefillTemplateVars fills empty form fields with their most recent value
+func (m *Model) prefillTemplateVars(card *Card) {
+	for _, decl := range m.DetectedDecls {
+		if m.TemplateVars[decl.Name] != "" {
+			continue
+		}
+		if history := m.VarHistory.suggestions(card.ID, decl.Name); len(history) > 0 {
+			m.TemplateVars[decl.Name] = history[0]
+		}
+	}
+}
+
+// browseVarHistory steps through the focused field's history (delta +1 = older)
+// Returns false when the field has no history, so the key can scroll instead
+func (m *Model) browseVarHistory(card *Card, delta int) bool {
+	v := m.focusedTemplateVar()
+	if v == nil {
+		return false
+	}
+	history := m.VarHistory.suggestions(card.ID, v.Name)
+	if len(history) == 0 {
+		return false
+	}
+
+	// -1 closes the dropdown (going up past the newest value)
+	m.VarHistoryIndex = max(-1, min(len(history)-1, m.VarHistoryIndex+delta))
+	if m.VarHistoryIndex >= 0 {
+		m.TemplateVars[v.Name] = history[m.VarHistoryIndex]
+	}
+	return true
+}
+
+// varHistoryShown returns the slice of the focused field's history the dropdown
+// shows and the offset of its first entry (nil when the dropdown is closed)
+func (m *Model) varHistoryShown(cardID string) ([]string, int) {
+	v := m.focusedTemplateVar()
+	if v == nil || m.VarHistoryIndex < 0 {
+		return nil, 0
+	}
+	history := m.VarHistory.suggestions(cardID, v.Name)
+	if m.VarHistoryIndex >= len(history) {
+		return nil, 0
+	}
+	start := max(0, min(m.VarHistoryIndex-maxVarHistoryShown/2, len(history)-maxVarHistoryShown))
+	end := min(len(history), start+maxVarHistoryShown)
+	return history[start:end], start
+}
+
+// recordVarHistory remembers the values of a copied template and saves the history in the background
+func (m *Model) recordVarHistory(card *Card) tea.Cmd {
+	if m.VarHistory == nil {
+		return nil
+	}
+	for _, decl := range m.DetectedDecls {
+		m.VarHistory.record(card.ID, decl.Name, m.TemplateVars[decl.Name])
+	}
+
+	// Snapshot so the goroutine doesn't race with later copies
+	snapshot := newVarHistoryData()
+	for name, values := range m.VarHistory.Global {
+		snapshot.Global[name] = append([]string(nil), values...)
+	}
+	for cardID, vars := range m.VarHistory.Cards {
+		snapshot.Cards[cardID] = make(map[string][]string, len(vars))
+		for name, values := range vars {
+			snapshot.Cards[cardID][name] = append([]string(nil), values...)
+		}
+	}
+
+	ticket := varHistorySaves.ticket()
+	return func() tea.Msg {
+		if err := varHistorySaves.save(ticket, func() error { return SaveVarHistory(DefaultVarHistoryPath, snapshot) }); err != nil {
+			return usageSaveErrorMsg{err: err}
+		}
+		return nil
+	}
+}
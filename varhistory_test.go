@@ -0,0 +1,78 @@
+package main
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestVarHistoryRecord(t *testing.T) {
+	h := newVarHistoryData()
+	h.record("a", "host", "one")
+	h.record("a", "host", "two")
+	h.record("a", "host", "one") // Moves back to the front
+	h.record("b", "host", "three")
+	h.record("b", "port", "") // Empty values aren't remembered
+
+	tests := []struct {
+		name    string
+		cardID  string
+		varName string
+		want    []string
+	}{
+		{"card values first", "a", "host", []string{"one", "two", "three"}},
+		{"other card", "b", "host", []string{"three", "one", "two"}},
+		{"unused card falls back to global", "c", "host", []string{"three", "one", "two"}},
+		{"unknown variable", "a", "port", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.suggestions(tt.cardID, tt.varName); !equalStrings(got, tt.want) {
+				t.Errorf("suggestions(%q, %q) = %v, want %v", tt.cardID, tt.varName, got, tt.want)
+			}
+		})
+	}
+
+	var nilHistory *VarHistoryData
+	if got := nilHistory.suggestions("a", "host"); got != nil {
+		t.Errorf("nil history suggestions = %v, want nil", got)
+	}
+}
+
+func TestVarHistoryCap(t *testing.T) {
+	h := newVarHistoryData()
+	for i := 0; i < maxVarHistory+5; i++ {
+		h.record("a", "n", strconv.Itoa(i))
+	}
+
+	got := h.suggestions("a", "n")
+	if len(got) != maxVarHistory {
+		t.Fatalf("kept %d values, want %d", len(got), maxVarHistory)
+	}
+	if got[0] != strconv.Itoa(maxVarHistory+4) {
+		t.Errorf("most recent = %q, want %q", got[0], strconv.Itoa(maxVarHistory+4))
+	}
+}
+
+func TestVarHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "var-history.json")
+
+	h, err := LoadVarHistory(path)
+	if err != nil || len(h.Global) != 0 {
+		t.Fatalf("LoadVarHistory(missing) = %+v, %v; want empty history and no error", h, err)
+	}
+
+	h.record("a", "host", "example.com")
+	if err := SaveVarHistory(path, h); err != nil {
+		t.Fatalf("SaveVarHistory() error: %v", err)
+	}
+
+	loaded, err := LoadVarHistory(path)
+	if err != nil {
+		t.Fatalf("LoadVarHistory() error: %v", err)
+	}
+	if got := loaded.suggestions("a", "host"); !equalStrings(got, []string{"example.com"}) {
+		t.Errorf("loaded suggestions = %v, want [example.com]", got)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"hash/fnv"
 	"strings"
 	"time"
 
@@ -23,6 +24,11 @@ func (m Model) View() string {
 		return renderHelp(m)
 	}
 
+	// Modal dialogs take over the screen until answered
+	if m.hasDialog() {
+		return renderDialog(m)
+	}
+
 	if m.Data == nil {
 		return "Loading cards..."
 	}
@@ -37,6 +43,26 @@ func (m Model) View() string {
 		return renderCardCreateScreen(m)
 	}
 
+	// Trash screen
+	if m.ViewMode == ViewTrash {
+		return renderTrashScreen(m)
+	}
+
+	// Stats dashboard
+	if m.ViewMode == ViewStats {
+		return renderStatsScreen(m)
+	}
+
+	// Templatize review
+	if m.ViewMode == ViewTemplatize && m.Templatize != nil {
+		return renderTemplatizeScreen(m)
+	}
+
+	// Running a command card
+	if m.ViewMode == ViewRun && m.Run != nil {
+		return renderRunScreen(m)
+	}
+
 	// Detail view (full-screen card)
 	if m.ViewMode == ViewDetail {
 		return renderDetailView(m)
@@ -125,14 +151,42 @@ func renderHeader(m Model) string {
 		}
 	}
 
+	// Favorites filter and pinning
+	if m.FavoritesOnly {
+		filterText += styleFavorite.Render(" ★ only")
+	} else if m.PinFavorites {
+		filterText += styleFavorite.Render(" ★ pinned")
+	}
+
+	// Search box (cursor shown while typing)
+	if m.SearchActive || m.SearchQuery != "" {
+		query := m.SearchQuery
+		if m.SearchActive {
+			query += "█"
+		}
+		filterText += styleSearchBox.Render(" /" + query)
+	}
+
+	// Non-default list/grid ordering
+	if m.ListSort != "" && (m.ViewMode == ViewList || m.ViewMode == ViewGrid) {
+		filterText += styleSubtle.Render(" ↕ " + listSortLabel(m.ListSort))
+	}
+
 	// Card count
 	count := styleSubtle.Render(fmt.Sprintf("[%d/%d]", len(m.FilteredCards), len(m.Data.Cards)))
 
+	// Multi-selection count
+	markedText := ""
+	if len(m.MarkedCards) > 0 {
+		markedText = styleMarked.Render(fmt.Sprintf(" ✓ %d marked", len(m.MarkedCards)))
+	}
+
 	mainLine := lipgloss.JoinHorizontal(lipgloss.Top,
 		title,
 		filterText,
 		" ",
 		count,
+		markedText,
 	)
 	headerLines = append(headerLines, mainLine)
 
@@ -162,14 +216,22 @@ func renderListViewWithHeight(m Model, availableHeight int) string {
 	visibleCount := max(1, availableHeight)
 	var lines []string
 
-	// Calculate which cards to show
+	// Calculate which cards to show (the pinned-favorites separator takes one line)
+	separatorLine := m.listSeparatorLine(visibleCount)
+	cardCount := visibleCount
+	if separatorLine >= 0 {
+		cardCount--
+	}
 	start := m.ScrollOffset
-	end := min(start+visibleCount, len(m.FilteredCards))
+	end := min(start+cardCount, len(m.FilteredCards))
 
 	for i := start; i < end; i++ {
 		card := m.FilteredCards[i]
 		isSelected := i == m.SelectedIndex
 
+		if i-start == separatorLine {
+			lines = append(lines, renderPinnedSeparator(m.Width))
+		}
 		lines = append(lines, renderCardListItem(m, &card, isSelected))
 	}
 
@@ -208,6 +270,29 @@ func renderCardListItem(m Model, card *Card, selected bool) string {
 		indicator = styleCardTitleSelected.Render(">")
 	}
 
+	// Favorites get a star (with their 1-9 quick-access number)
+	if card.Starred {
+		if n := m.favoriteNumber(card.ID); n > 0 {
+			title = styleFavorite.Render(fmt.Sprintf("★%d", n)) + " " + title
+		} else {
+			title = styleFavorite.Render("★") + " " + title
+		}
+	}
+
+	// Drop target while dragging a card to reorder it
+	if m.DragCardID != "" && card.ID == m.DragOverID && card.ID != m.DragCardID {
+		indicator = styleMarked.Render("⇥")
+	}
+
+	// Multi-selection mark (column only shown while something is marked)
+	if len(m.MarkedCards) > 0 {
+		if m.MarkedCards[card.ID] {
+			indicator += styleMarked.Render("✓")
+		} else {
+			indicator += " "
+		}
+	}
+
 	// Build line
 	line := fmt.Sprintf("%s %s %s",
 		indicator,
@@ -221,6 +306,13 @@ func renderCardListItem(m Model, card *Card, selected bool) string {
 	return styleCardItem.Width(m.Width).Render(line)
 }
 
+// renderPinnedSeparator renders the divider between pinned favorites and other cards
+func renderPinnedSeparator(width int) string {
+	label := " ★ pinned above "
+	line := strings.Repeat("─", 2) + label + strings.Repeat("─", max(0, width-4-runewidth.StringWidth(label)))
+	return styleFavorite.Faint(true).Render(line)
+}
+
 // renderSplitView renders list view on top, preview on bottom
 func renderSplitView(m Model) string {
 	availableHeight := m.Height - 6 // Header + status bar
@@ -331,8 +423,18 @@ func renderGridCard(m Model, card *Card, selected bool) string {
 	// Card has 6 lines available (GridCardHeight = 6)
 	// Strategy: Title (1-2 lines) + Content preview (remaining lines)
 
+	// Marked cards get a check in front of the title
+	marked := m.MarkedCards[card.ID]
+	cardTitle := card.Title
+	if card.Starred {
+		cardTitle = "★ " + cardTitle
+	}
+	if marked {
+		cardTitle = "✓ " + cardTitle
+	}
+
 	// Wrap title to max 2 lines (25 chars = 27 width - 2 for horizontal padding)
-	titleLines := wrapText(card.Title, 25, 2)
+	titleLines := wrapText(cardTitle, 25, 2)
 
 	// Calculate remaining lines for content preview
 	remainingLines := 6 - len(titleLines)
@@ -350,7 +452,18 @@ func renderGridCard(m Model, card *Card, selected bool) string {
 	}
 
 	// Add content preview (dimmed) if we have space
-	if remainingLines > 0 && card.Content != "" {
+	kind, language := m.contentTypeOf(card)
+	if remainingLines > 0 && card.Content != "" && kind == ContentCode {
+		// Code cards show their first lines highlighted, keeping line structure
+		var snippet []string
+		for _, line := range strings.Split(strings.TrimSpace(card.Content), "\n") {
+			if len(snippet) == remainingLines {
+				break
+			}
+			snippet = append(snippet, truncate(strings.ReplaceAll(line, "\t", "  "), 25))
+		}
+		lines = append(lines, strings.Split(highlightCode(strings.Join(snippet, "\n"), language, m.Config.CodeTheme), "\n")...)
+	} else if remainingLines > 0 && card.Content != "" {
 		// Clean content: strip newlines, truncate
 		contentPreview := strings.ReplaceAll(card.Content, "\n", " ")
 		contentPreview = strings.TrimSpace(contentPreview)
@@ -371,7 +484,11 @@ func renderGridCard(m Model, card *Card, selected bool) string {
 	content := strings.Join(lines, "\n")
 
 	// Apply style with category-colored border
-	cardStyle := makeGridCardStyle(categoryColor, selected)
+	cardStyle := makeGridCardStyle(categoryColor, selected, marked)
+	// Pinned favorites get a gold border so the pinned block stands apart
+	if card.Starred && m.isPinningFavorites() && !marked {
+		cardStyle = cardStyle.BorderForeground(colorYellow)
+	}
 	return cardStyle.Render(content)
 }
 
@@ -444,12 +561,16 @@ func renderTableView(m Model) string {
 		return styleSubtle.Render("No cards found. Press 'f' to filter by category.")
 	}
 
-	// Sort cards based on current sort column and direction
-	sortedCards := sortCards(m.FilteredCards, m.CategoryMap, m.SortColumn, m.SortDirection)
+	// FilteredCards is already sorted by column in table view (see updateFilteredCards)
+	sortedCards := m.FilteredCards
 
 	// Calculate column widths based on terminal width
-	// Available width = terminal width - borders and padding
-	availableWidth := m.Width - 4
+	// Available width = terminal width - borders and padding - row indent and " │ " separators
+	availableWidth := m.Width - 4 - 2 - 9
+
+	// Custom field columns (and their separators) come out of the shared width
+	fieldWidth := max(8, availableWidth/10)
+	availableWidth -= len(m.TableFields) * (fieldWidth + 3)
 
 	// Column width distribution (percentages of available width)
 	// Title: 40%, Category: 20%, Created: 20%, Updated: 20%
@@ -484,13 +605,22 @@ func renderTableView(m Model) string {
 	createdHeader = padOrTruncate(createdHeader, createdWidth)
 	updatedHeader = padOrTruncate(updatedHeader, updatedWidth)
 
+	// Custom field column headers (sorted with 5-9)
+	fieldHeaders := ""
+	fieldSeparators := ""
+	for _, key := range m.TableFields {
+		fieldHeaders += " │ " + padOrTruncate(key+getSortIndicator(fieldColumnPrefix+key, m.SortColumn, m.SortDirection), fieldWidth)
+		fieldSeparators += "─┼─" + strings.Repeat("─", fieldWidth)
+	}
+
 	// Style the header (with 2-space indent to match data rows)
 	headerRow := styleTableHeader.Render(
-		fmt.Sprintf("  %s │ %s │ %s │ %s",
+		fmt.Sprintf("  %s │ %s │ %s │ %s%s",
 			titleHeader,
 			categoryHeader,
 			createdHeader,
 			updatedHeader,
+			fieldHeaders,
 		),
 	)
 
@@ -498,7 +628,7 @@ func renderTableView(m Model) string {
 	separator := "  " + strings.Repeat("─", titleWidth) + "─┼─" +
 		strings.Repeat("─", categoryWidth) + "─┼─" +
 		strings.Repeat("─", createdWidth) + "─┼─" +
-		strings.Repeat("─", updatedWidth)
+		strings.Repeat("─", updatedWidth) + fieldSeparators
 
 	var lines []string
 	lines = append(lines, headerRow)
@@ -526,24 +656,41 @@ func renderTableView(m Model) string {
 		}
 
 		// Format data for display
-		title := padOrTruncate(card.Title, titleWidth)
+		cardTitle := card.Title
+		if card.Starred {
+			cardTitle = "★ " + cardTitle
+		}
+		title := padOrTruncate(cardTitle, titleWidth)
 		category := padOrTruncate(categoryName, categoryWidth)
 		created := padOrTruncate(formatDate(card.CreatedAt), createdWidth)
 		updated := padOrTruncate(formatDate(card.UpdatedAt), updatedWidth)
 
+		// Custom field values
+		fieldValues := ""
+		for _, key := range m.TableFields {
+			fieldValues += " │ " + padOrTruncate(card.Fields[key], fieldWidth)
+		}
+
 		// Build row
-		row := fmt.Sprintf("%s │ %s │ %s │ %s",
+		row := fmt.Sprintf("%s │ %s │ %s │ %s%s",
 			title,
 			styleCategoryName(category, categoryColor),
 			created,
 			updated,
+			fieldValues,
 		)
 
+		// Multi-selection mark sits in the indent next to the cursor
+		mark := " "
+		if m.MarkedCards[card.ID] {
+			mark = styleMarked.Render("✓")
+		}
+
 		// Apply selection style
 		if isSelected {
-			row = styleCardItemSelected.Width(m.Width).Render("> " + row)
+			row = styleCardItemSelected.Width(m.Width).Render(">" + mark + row)
 		} else {
-			row = styleCardItem.Width(m.Width).Render("  " + row)
+			row = styleCardItem.Width(m.Width).Render(" " + mark + row)
 		}
 
 		lines = append(lines, row)
@@ -614,10 +761,13 @@ func renderPreviewPaneWithWidth(m Model, height int, width int) string {
 	category := styleCategoryName(categoryName, categoryColor)
 	header := fmt.Sprintf("%s  %s", title, category)
 
-	// Content - use cached or raw content
+	// Content - use cached or raw content (markdown code blocks numbered either way)
 	// TFE-style: never render in View(), always use pre-rendered cache
 	content := card.Content
-	if m.UseMarkdownRender && m.CachedPreviewContent != "" && m.CachedPreviewWidth == width-4 {
+	if kind, _ := m.contentTypeOf(card); kind == ContentMarkdown {
+		content = numberCodeBlocks(content)
+	}
+	if m.needsRender(card) && m.CachedPreviewContent != "" && m.CachedPreviewWidth == width-4 {
 		// Use cached rendered markdown
 		content = m.CachedPreviewContent
 	}
@@ -676,7 +826,21 @@ func renderStatusBar(m Model) string {
 	var hints []string
 
 	// Responsive hints based on screen width
-	if m.Width < 60 {
+	if len(m.MarkedCards) > 0 {
+		// Bulk actions apply to marked cards
+		hints = []string{
+			styleHelpKey.Render("v") + styleHelpDesc.Render(" mark"),
+			styleHelpKey.Render("c") + styleHelpDesc.Render(" copy all"),
+			styleHelpKey.Render("M") + styleHelpDesc.Render(" move"),
+			styleHelpKey.Render("t") + styleHelpDesc.Render(" tag"),
+			styleHelpKey.Render("x") + styleHelpDesc.Render(" delete"),
+			styleHelpKey.Render("E") + styleHelpDesc.Render(" export"),
+			styleHelpKey.Render("Esc") + styleHelpDesc.Render(" clear"),
+		}
+		if m.Width < 60 {
+			hints = hints[1:5]
+		}
+	} else if m.Width < 60 {
 		// Very narrow (mobile) - show only essentials
 		hints = []string{
 			styleHelpKey.Render("n") + styleHelpDesc.Render(" new"),
@@ -737,6 +901,11 @@ func renderStatusBar(m Model) string {
 		}
 	}
 
+	// Active variable profile first, where it's hard to miss
+	if badge := renderProfileBadge(m); badge != "" {
+		hints = append([]string{badge}, hints...)
+	}
+
 	status := strings.Join(hints, "  ")
 	return styleStatusBar.Width(m.Width).Render(" " + status)
 }
@@ -758,32 +927,118 @@ func renderHelp(m Model) string {
 		"  p              Toggle preview pane (list/grid modes)",
 		"                 Side-by-side on wide screens!",
 		"  Space          Update preview to selected card",
+		"  o              Cycle list/grid order (library / frecency / manual)",
+		"  Alt+↑/↓        Move card up/down in manual order",
+		"  Drag           Drag a card onto another to reorder (list view)",
+		"",
+		styleHelpKey.Render("Search:"),
+		"  /              Search titles, tags, fields and content",
+		"                 key:value filters on custom fields (platform:termux)",
+		"  Enter          Keep results and navigate",
+		"  Esc            Clear search",
+		"                 Ties are ranked by frecency (most used recently)",
 		"",
 		styleHelpKey.Render("Table View:"),
 		"  1              Sort by title (press again to reverse)",
 		"  2              Sort by category",
 		"  3              Sort by created date",
 		"  4              Sort by updated date",
+		"  5-9            Sort by custom field columns",
+		"  C              Show/hide custom field columns",
 		"  ↑↓/k/j         Navigate rows",
 		"",
 		styleHelpKey.Render("Actions:"),
 		"  Enter, d       Open card in detail view",
 		"  c              Copy card to clipboard",
+		"  B              Copy one fenced code block (picker)",
 		"  n              Create new card",
+		"  e              Edit card (title, content, category, fields)",
+		"  y              Cycle content type (category / markdown / code / plain)",
+		"  Y              Set code language (empty = auto-detect)",
+		"  z              Templatize: turn IPs, ports, paths... into {{variables}}",
+		"  r              Run a shell card (asks first; templates open to be filled)",
+		"  x, Del         Move card to trash (asks first)",
+		"  u              Undo last change (create/delete/restore...)",
+		"  Ctrl+R         Redo",
 		"  f              Filter by category",
+		"  T              Open trash (restore/purge deleted cards)",
+		"  S              Library stats (categories, usage, templates)",
+		"  R              Rename card (offers to rewrite [[links]])",
+		"",
+		styleHelpKey.Render("Favorites:"),
+		"  s              Star/unstar card",
+		"  P              Pin favorites at top of list/grid",
+		"  1-9            Open favorite #1-9 (not in table view)",
+		"  f → ★          Show only favorites",
+		"",
+		styleHelpKey.Render("Multi-Select:"),
+		"  v              Mark/unmark card",
+		"  Ctrl+A         Mark all filtered cards (again to clear)",
+		"  Shift+Click    Mark range from selection",
+		"  c              Copy marked cards (joined by bulkCopySeparator)",
+		"  M              Move marked cards to a category",
+		"  t              Add/remove tags (-tag removes)",
+		"  x              Move marked cards to trash",
+		"  E              Export marked cards to JSON (exportDir)",
+		"  Esc            Clear marks",
 		"",
 		styleHelpKey.Render("Detail View:"),
 		"  ↑/↓, k/j       Scroll content",
-		"  m              Toggle markdown rendering",
-		"  t              Toggle template form (if templates detected)",
+		"  m              Toggle markdown rendering (code stays highlighted)",
+		"  y, Y           Cycle content type / set code language",
+		"  z              Templatize the card (review suggested variables)",
+		"  r, Ctrl+X      Run the filled command (Ctrl+X while typing)",
+		"  t, Ctrl+T      Toggle template form (Ctrl+T while typing)",
 		"  Tab            Navigate template fields",
-		"  Enter, c       Copy (filled template if editing)",
+		"  ←/→, Space     Pick a choice / toggle a bool field",
+		"  ↑/↓            Recent values of the focused field",
+		"  Ctrl+P         Show the template filled in / as written",
+		"  Ctrl+O         Next variable profile (dev/staging/prod from config)",
+		"  Enter, c       Copy (Enter copies the filled template while typing)",
+		"  x              Move card to trash (form hidden)",
+		"  Alt+1-9, B     Copy numbered code block / pick one",
+		"  1-9            Open favorite #1-9 (form hidden)",
+		"  Tab            Focus next [[link]] or backlink (form hidden)",
+		"  Enter          Follow focused link",
+		"  b, Backspace   Back to the card you came from",
+		"  R              Rename card (offers to rewrite links to it)",
+		"  e              Edit card",
 		"  Esc            Return to list/grid view",
 		"",
+		styleHelpKey.Render("Text Fields (forms):"),
+		"  ←/→, Home/End  Move the cursor (Ctrl+←/→ by word)",
+		"  Ctrl+W, Alt+D  Delete word before / after the cursor",
+		"  Ctrl+U, Ctrl+K Delete to line start / end",
+		"  Ctrl+Z         Undo (per field)",
+		"  Enter          New line in card content",
+		"",
 		styleHelpKey.Render("Mouse/Touch:"),
 		"  Click          Select & pin to preview (grid)",
 		"  Double-click   Copy card to clipboard",
 		"  Mouse wheel    Scroll preview (over preview pane)",
+		"  Click ⎈        Next variable profile (status bar)",
+		"",
+		styleHelpKey.Render("Trash:"),
+		"  r, Enter       Restore card to its original category",
+		"  x              Permanently delete card",
+		"  X              Empty trash",
+		"  u, Ctrl+R      Undo/redo",
+		fmt.Sprintf("                 Auto-purged after %d day(s) (trashRetentionDays)", m.Config.TrashRetentionDays),
+		"",
+		styleHelpKey.Render("Templatize (z):"),
+		"  y, Space       Accept the suggestion ({{name|original}})",
+		"  n, x           Reject it",
+		"  r              Rename the variable",
+		"  a              Accept all remaining",
+		"  Enter, Ctrl+S  Save the card (undo with u)",
+		"  Esc            Cancel",
+		"",
+		styleHelpKey.Render("Run (r):"),
+		"  Enter, y       Run and show stdout/stderr and the exit code",
+		"  i              Run in the terminal (interactive commands)",
+		"  Esc            Cancel / stop a running command / back",
+		"  c, s           Copy the output / save it as a new card",
+		"  r              Run again",
 		"",
 		styleHelpKey.Render("Auto-Reload:"),
 		"  ✨             Checks for new cards every 10 seconds",
@@ -844,12 +1099,15 @@ func renderCategoryFilterScreen(m Model) string {
 	lines = append(lines, "")
 
 	// Instructions
-	instructions := styleSubtle.Render("↑↓: Navigate  Space/Enter: Toggle  A: All  C: Clear  Esc: Back")
+	instructions := styleSubtle.Render("↑↓: Navigate  Space/Enter: Toggle  A: All  C: Clear  B: Copy 1st code block  Esc: Back")
 	lines = append(lines, instructions)
 	lines = append(lines, "")
 
 	// Category list
 	activeCount := len(m.SelectedCategories)
+	if m.FavoritesOnly {
+		activeCount++
+	}
 	filterInfo := fmt.Sprintf("Active filters: %d", activeCount)
 	if activeCount > 0 {
 		filterInfo = styleSearchBox.Render(filterInfo)
@@ -859,9 +1117,22 @@ func renderCategoryFilterScreen(m Model) string {
 	lines = append(lines, filterInfo)
 	lines = append(lines, "")
 
-	// Render each category
+	// Virtual favorites entry (row 0)
+	favCheckbox := "[ ]"
+	if m.FavoritesOnly {
+		favCheckbox = "[✓]"
+	}
+	favName := styleFavorite.Render(fmt.Sprintf("★ Favorites (%d)", len(m.getFavorites())))
+	if m.FilterCursorIndex == 0 {
+		line := fmt.Sprintf("%s %s %s", styleCardTitleSelected.Render(">"), favCheckbox, favName)
+		lines = append(lines, styleCardItemSelected.Render(line))
+	} else {
+		lines = append(lines, styleCardItem.Render(fmt.Sprintf("  %s %s", favCheckbox, favName)))
+	}
+
+	// Render each category (rows 1..n)
 	for i, cat := range m.Data.Categories {
-		isSelected := m.FilterCursorIndex == i
+		isSelected := m.FilterCursorIndex == i+1
 		isActive := m.SelectedCategories[cat.ID]
 
 		// Checkbox
@@ -872,6 +1143,9 @@ func renderCategoryFilterScreen(m Model) string {
 
 		// Category name with color
 		catName := styleCategoryName(cat.Name, cat.Color)
+		if cat.CopyFirstCodeBlock {
+			catName += styleSubtle.Render("  ▸ copies 1st code block")
+		}
 
 		// Build line
 		var line string
@@ -905,11 +1179,14 @@ func renderCardCreateScreen(m Model) string {
 
 	// Title
 	title := styleTitle.Render("Create New Card")
+	if m.EditingCardID != "" {
+		title = styleTitle.Render("Edit Card")
+	}
 	lines = append(lines, title)
 	lines = append(lines, "")
 
 	// Instructions
-	instructions := styleSubtle.Render("Tab: Next field  Ctrl+S: Save  Esc: Cancel")
+	instructions := styleSubtle.Render("Tab: Next field  Ctrl+S: Save  Ctrl+Z: Undo  Esc: Cancel")
 	lines = append(lines, instructions)
 	lines = append(lines, "")
 
@@ -919,13 +1196,7 @@ func renderCardCreateScreen(m Model) string {
 		titleLabel = styleSearchBox.Render("→ Title:")
 	}
 	lines = append(lines, titleLabel)
-	titleValue := m.NewCardTitle
-	if titleValue == "" {
-		titleValue = styleSubtle.Render("(enter title)")
-	}
-	if m.CreateFormField == 0 {
-		titleValue = styleCardItemSelected.Render(titleValue + "█") // Show cursor
-	}
+	titleValue := m.TextInputs["title"].View(m.NewCardTitle, styleSubtle.Render("(enter title)"), m.CreateFormField == 0)
 	lines = append(lines, "  "+titleValue)
 	lines = append(lines, "")
 
@@ -936,24 +1207,12 @@ func renderCardCreateScreen(m Model) string {
 	}
 	lines = append(lines, contentLabel)
 
-	// Show content (multi-line)
-	contentValue := m.NewCardContent
-	if contentValue == "" {
-		contentValue = styleSubtle.Render("(enter content)")
-		lines = append(lines, "  "+contentValue)
+	// Show content (multi-line, soft-wrapped, scrolled to the cursor)
+	if m.NewCardContent == "" {
+		lines = append(lines, "  "+m.TextInputs["content"].View("", styleSubtle.Render("(enter content)"), m.CreateFormField == 1))
 	} else {
-		// Split by newlines and render
-		contentLines := strings.Split(contentValue, "\n")
 		maxLines := 10 // Limit visible lines
-		for i, line := range contentLines {
-			if i >= maxLines {
-				lines = append(lines, "  "+styleSubtle.Render(fmt.Sprintf("... (%d more lines)", len(contentLines)-maxLines)))
-				break
-			}
-			// Show cursor on last line if focused
-			if m.CreateFormField == 1 && i == len(contentLines)-1 {
-				line = styleCardItemSelected.Render(line + "█")
-			}
+		for _, line := range m.TextInputs["content"].ViewRows(m.NewCardContent, createContentWidth(m.Width), maxLines, m.CreateFormField == 1) {
 			lines = append(lines, "  "+line)
 		}
 	}
@@ -984,11 +1243,20 @@ func renderCardCreateScreen(m Model) string {
 		lines = append(lines, "  "+styleSubtle.Render("(no category selected)"))
 	}
 
+	// Fields 3+: custom fields from the category schema
+	for i, field := range m.getCategoryFields(m.NewCardCategoryID) {
+		focused := m.CreateFormField == 3+i
+		lines = append(lines, "")
+		lines = append(lines, renderSchemaFieldInput(field, m.NewCardFields[field.Key], focused, m.TextInputs["field:"+field.Key]))
+	}
+
 	lines = append(lines, "")
 	lines = append(lines, "")
 
 	// Validation hints
-	if m.NewCardTitle == "" || m.NewCardContent == "" {
+	if m.CreateFormError != "" {
+		lines = append(lines, styleError.Render("⚠ "+m.CreateFormError))
+	} else if m.NewCardTitle == "" || m.NewCardContent == "" {
 		hint := styleError.Render("⚠ Title and content are required")
 		lines = append(lines, hint)
 	} else {
@@ -1004,6 +1272,230 @@ func renderCardCreateScreen(m Model) string {
 		content)
 }
 
+// renderSchemaFieldInput renders one custom field in the create/edit form
+// (in is the text field's editing state, nil until it's been typed into)
+func renderSchemaFieldInput(field FieldDef, value string, focused bool, in *textInput) string {
+	label := field.displayLabel() + ":"
+	if focused {
+		label = styleSearchBox.Render("→ " + label)
+	}
+
+	var display, hint string
+	switch field.Type {
+	case FieldBool:
+		display = "[ ]"
+		if value == "true" {
+			display = "[✓]"
+		}
+		hint = " (Space to toggle)"
+	case FieldEnum:
+		display = value
+		if display == "" {
+			display = styleSubtle.Render("(none)")
+		}
+		hint = " (↑↓ " + strings.Join(field.Options, "/") + ")"
+	default:
+		placeholder := ""
+		if !focused {
+			placeholder = styleSubtle.Render("(" + field.Type + ")")
+		}
+		return label + "\n  " + in.View(value, placeholder, focused)
+	}
+
+	if focused {
+		display = styleCardItemSelected.Render(display + hint)
+	}
+	return label + "\n  " + display
+}
+
+// renderTrashScreen renders the list of trashed cards with restore/purge actions
+func renderTrashScreen(m Model) string {
+	if m.Data == nil {
+		return "No data loaded"
+	}
+
+	var lines []string
+
+	// Title
+	title := styleTitle.Render("Trash")
+	lines = append(lines, title)
+	lines = append(lines, "")
+
+	// Instructions
+	instructions := styleSubtle.Render("↑↓: Navigate  R/Enter: Restore  X: Delete forever  Shift+X: Empty  Esc: Back")
+	lines = append(lines, instructions)
+	lines = append(lines, "")
+
+	// Retention info
+	retentionInfo := "Auto-purge disabled"
+	if m.Config.TrashRetentionDays > 0 {
+		retentionInfo = fmt.Sprintf("Cards are purged %d day(s) after deletion", m.Config.TrashRetentionDays)
+	}
+	lines = append(lines, styleSubtle.Render(fmt.Sprintf("%d card(s) in trash · %s", len(m.Data.Trash), retentionInfo)))
+	lines = append(lines, "")
+
+	if len(m.Data.Trash) == 0 {
+		lines = append(lines, styleSubtle.Render("Trash is empty."))
+	}
+
+	// Keep cursor visible: header above uses 6 lines, leave 2 for margin
+	visibleCount := max(1, m.Height-8)
+	start := 0
+	if m.TrashCursorIndex >= visibleCount {
+		start = m.TrashCursorIndex - visibleCount + 1
+	}
+	end := min(start+visibleCount, len(m.Data.Trash))
+
+	now := time.Now()
+	titleWidth := max(10, min(40, m.Width-40))
+	for i := start; i < end; i++ {
+		entry := m.Data.Trash[i]
+		isSelected := m.TrashCursorIndex == i
+
+		// Original category (may have been removed since)
+		categoryName := "(no category)"
+		categoryColor := ""
+		if cat, ok := m.CategoryMap[entry.CategoryID]; ok {
+			categoryName = cat.Name
+			categoryColor = cat.Color
+		}
+
+		// Deletion time and purge countdown
+		deletedInfo := "deleted " + formatDateTime(entry.DeletedAt)
+		if days := m.trashDaysRemaining(&entry, now); days >= 0 {
+			deletedInfo += fmt.Sprintf(" · purge in %dd", days)
+		}
+
+		// Build line
+		var line string
+		if isSelected {
+			indicator := styleCardTitleSelected.Render(">")
+			line = fmt.Sprintf("%s %s %s %s", indicator, padOrTruncate(entry.Title, titleWidth),
+				styleCategoryName(categoryName, categoryColor), styleSubtle.Render(deletedInfo))
+			line = styleCardItemSelected.Render(line)
+		} else {
+			line = fmt.Sprintf("  %s %s %s", padOrTruncate(entry.Title, titleWidth),
+				styleCategoryName(categoryName, categoryColor), styleSubtle.Render(deletedInfo))
+			line = styleCardItem.Render(line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	content := strings.Join(lines, "\n")
+
+	// Center on screen
+	return lipgloss.Place(m.Width, m.Height,
+		lipgloss.Center, lipgloss.Top,
+		content)
+}
+
+// renderStatsScreen renders the library statistics dashboard
+func renderStatsScreen(m Model) string {
+	if m.Data == nil {
+		return "No data loaded"
+	}
+
+	lines := m.StatsLines
+
+	// Scroll body below a fixed title/instructions block
+	header := []string{
+		styleTitle.Render("Library Stats"),
+		styleSubtle.Render("↑↓: Scroll  Esc: Back"),
+	}
+	visibleCount := statsVisibleLines(m)
+	offset := max(0, min(m.StatsScrollOffset, len(lines)-visibleCount))
+	end := min(offset+visibleCount, len(lines))
+
+	content := strings.Join(append(header, lines[offset:end]...), "\n")
+	return lipgloss.NewStyle().Padding(0, 1).Render(content)
+}
+
+// statsVisibleLines is how many dashboard lines fit below the stats header
+func statsVisibleLines(m Model) int {
+	return max(1, m.Height-3)
+}
+
+// renderStatsLines renders the scrollable body of the stats dashboard
+// Sections stack vertically so the screen stays readable on narrow Termux widths
+func renderStatsLines(m Model) []string {
+	stats := computeLibraryStats(m.Data, m.Usage, time.Now())
+	width := max(20, min(m.Width-2, 80))
+	labelWidth := max(8, min(20, width/3))
+
+	var lines []string
+	section := func(title string) {
+		lines = append(lines, "", styleHelpKey.Render(title))
+	}
+
+	// Overview
+	lines = append(lines, fmt.Sprintf("%d cards · %d copies · avg %d chars",
+		stats.TotalCards, stats.TotalCopies, stats.AvgContentLength))
+
+	// Cards per category (bar chart in category colors)
+	section("Cards per Category")
+	maxCount := 0
+	for _, cat := range stats.Categories {
+		maxCount = max(maxCount, cat.Count)
+	}
+	barWidth := max(4, width-labelWidth-6)
+	for _, cat := range stats.Categories {
+		label := styleCategoryName(padOrTruncate(cat.Name, labelWidth), cat.Color)
+		lines = append(lines, fmt.Sprintf("%s %4d %s", label, cat.Count, renderBar(cat.Count, maxCount, barWidth, cat.Color)))
+	}
+
+	// Cards created per week
+	section(fmt.Sprintf("Created per Week (last %d)", statsWeeks))
+	created := 0
+	for _, n := range stats.WeeklyCreated {
+		created += n
+	}
+	lines = append(lines, styleFavorite.Render(sparkline(stats.WeeklyCreated))+styleSubtle.Render(fmt.Sprintf("  %d new, this week %d", created, stats.WeeklyCreated[statsWeeks-1])))
+
+	// Usage
+	titleWidth := max(10, width-16)
+	usageLine := func(entry cardUsageStat) string {
+		return fmt.Sprintf("  %s %s", padOrTruncate(entry.Card.Title, titleWidth),
+			styleSubtle.Render(fmt.Sprintf("%3d× %s", entry.Usage.CopyCount, formatDate(entry.Usage.LastUsedAt))))
+	}
+
+	section("Most Used")
+	if len(stats.MostUsed) == 0 {
+		lines = append(lines, styleSubtle.Render("  Nothing copied yet"))
+	}
+	for _, entry := range stats.MostUsed {
+		lines = append(lines, usageLine(entry))
+	}
+
+	if len(stats.LeastUsed) > 0 {
+		section("Least Used")
+		for _, entry := range stats.LeastUsed {
+			lines = append(lines, usageLine(entry))
+		}
+	}
+
+	section(fmt.Sprintf("Never Copied (%d)", len(stats.NeverCopied)))
+	for i, card := range stats.NeverCopied {
+		if i == statsTopN {
+			lines = append(lines, styleSubtle.Render(fmt.Sprintf("  …and %d more", len(stats.NeverCopied)-statsTopN)))
+			break
+		}
+		lines = append(lines, "  "+truncate(card.Title, width-2))
+	}
+
+	// Template variables
+	section(fmt.Sprintf("Template Variables (%d cards)", stats.TemplateCards))
+	if len(stats.TemplateVars) == 0 {
+		lines = append(lines, styleSubtle.Render("  No {{variables}} in library"))
+	}
+	for _, v := range stats.TemplateVars {
+		lines = append(lines, fmt.Sprintf("  %s %s", padOrTruncate("{{"+v.Name+"}}", titleWidth),
+			styleSubtle.Render(fmt.Sprintf("%3d card(s)", v.Cards))))
+	}
+
+	return lines
+}
+
 // renderDetailView renders full-screen card view with markdown and templates
 func renderDetailView(m Model) string {
 	card := m.getSelectedCard()
@@ -1024,26 +1516,78 @@ func renderDetailView(m Model) string {
 
 	// Header: Title + Category + Markdown indicator + separator
 	title := stylePreviewTitle.Render(card.Title)
+	if card.Starred {
+		title = styleFavorite.Render("★ ") + title
+	}
 	category := styleCategoryName(categoryName, categoryColor)
 
 	var mdIndicator string
-	if m.UseMarkdownRender {
-		mdIndicator = styleSearchBox.Render(" [MD] ")
+	if m.needsRender(card) {
+		mdIndicator = styleSearchBox.Render(" [" + m.contentTypeLabel(card) + "] ")
 	} else {
-		mdIndicator = styleSubtle.Render(" [TXT] ")
+		mdIndicator = styleSubtle.Render(" [" + m.contentTypeLabel(card) + "] ")
 	}
 
 	header := lipgloss.JoinHorizontal(lipgloss.Left, title, "  ", category, mdIndicator)
+	if len(card.Tags) > 0 {
+		header = lipgloss.JoinHorizontal(lipgloss.Left, header, styleSubtle.Render("#"+strings.Join(card.Tags, " #")))
+	}
 
 	separator := strings.Repeat("─", m.Width-4)
 
 	lines = append(lines, header)
+
+	// Custom fields from the category schema
+	fieldSummary := m.cardFieldSummary(card)
+	if len(fieldSummary) > 0 {
+		lines = append(lines, styleSubtle.Render(truncate(strings.Join(fieldSummary, " · "), m.Width-4)))
+	}
+
+	// Cards pulled in with {{> Card Title}} (and broken includes)
+	included, includeErrors := m.includeSummary(card)
+	hasIncludeLine := len(included) > 0 || len(includeErrors) > 0
+	if hasIncludeLine {
+		var parts []string
+		if len(included) > 0 {
+			parts = append(parts, styleSubtle.Render(truncate("Includes: "+strings.Join(included, " · "), m.Width-4)))
+		}
+		if len(includeErrors) > 0 {
+			parts = append(parts, styleError.Render("⚠ "+includeErrors[0]))
+		}
+		lines = append(lines, strings.Join(parts, "  "))
+	}
+
 	lines = append(lines, separator)
 	lines = append(lines, "")
 
 	// Available height for content and template form
 	// Header (title + separator + blank = 3) + footer (blank + footer = 2) = 5 lines total
 	availableHeight := m.Height - 5
+	if len(fieldSummary) > 0 {
+		availableHeight--
+	}
+	if hasIncludeLine {
+		availableHeight--
+	}
+
+	// Links and backlinks (backlinks take 2 lines: blank + "Linked from")
+	links := m.getDetailLinks(card)
+	focusedLink := m.getFocusedDetailLink(card)
+	var backlinks []detailLink
+	for _, link := range links {
+		if link.Backlink {
+			backlinks = append(backlinks, link)
+		}
+	}
+	if len(backlinks) > 0 {
+		availableHeight -= 2
+	}
+
+	// Template lint panel (blank + problems)
+	lintProblems := lintTemplate(card.Content)
+	if len(lintProblems) > 0 {
+		availableHeight -= 1 + lintPanelHeight(lintProblems)
+	}
 
 	// Render content with optional markdown
 	content := card.Content
@@ -1053,10 +1597,25 @@ func renderDetailView(m Model) string {
 	contentWidth := m.Width - 8
 
 	// TFE-style: use cached content if available and width matches
-	if m.UseMarkdownRender && m.CachedDetailContent != "" && m.CachedDetailWidth == contentWidth {
+	// (while typing into the form it lags behind until the debounced re-render)
+	if m.needsRender(card) && m.CachedDetailContent != "" && m.CachedDetailWidth == contentWidth {
 		renderedContent = m.CachedDetailContent
+	} else if kind, _ := m.contentTypeOf(card); kind == ContentMarkdown {
+		renderedContent = numberCodeBlocks(m.detailSource(card))
 	} else {
-		renderedContent = content
+		renderedContent = m.detailSource(card)
+	}
+
+	// Color filled values and still-missing variables of the filled preview
+	renderedContent = highlightFillMarkers(renderedContent)
+
+	// Highlight [[links]] (the focused one inverted)
+	if len(links) > len(backlinks) {
+		focusedTarget := ""
+		if focusedLink != nil && !focusedLink.Backlink {
+			focusedTarget = focusedLink.Label
+		}
+		renderedContent = highlightLinks(renderedContent, m.Data.Cards, focusedTarget)
 	}
 
 	// Check for template variables
@@ -1070,7 +1629,26 @@ func renderDetailView(m Model) string {
 		// Calculate heights based on number of variables
 		numVars := len(m.DetectedVars)
 		// Template form needs: header (1) + blank (1) + vars (n*2) + blank (1) + preview header (1) + preview (3) = 7 + n*2
+		// plus one validation line per invalid variable (no preview when the content above is filled in)
 		templateFormLines := 7 + numVars*2
+		if m.showFilledPreview(card) {
+			templateFormLines -= 5
+		}
+		fieldErrs, blockErr := m.templateFieldErrors(card)
+		for _, err := range fieldErrs {
+			if err != nil {
+				templateFormLines++
+			}
+		}
+		if blockErr != nil {
+			templateFormLines++
+		}
+		if len(m.BuiltinResults) > 0 || m.hasBuiltinVars() {
+			templateFormLines++
+		}
+		if shown, _ := m.varHistoryShown(card.ID); len(shown) > 0 {
+			templateFormLines += len(shown)
+		}
 		templateHeight = min(templateFormLines, availableHeight/2)
 		contentHeight = availableHeight - templateHeight
 	} else {
@@ -1124,14 +1702,32 @@ func renderDetailView(m Model) string {
 
 	lines = append(lines, visibleLines...)
 
+	// Template problems with line:column
+	if len(lintProblems) > 0 {
+		lines = append(lines, "", renderLintPanel(lintProblems, m.Width-4))
+	}
+
 	// Template form (if applicable)
 	if hasTemplates && m.ShowTemplateForm {
 		lines = append(lines, "")
 		lines = append(lines, renderTemplateForm(m, card))
 	}
 
+	// Backlinks
+	if len(backlinks) > 0 {
+		var titles []string
+		for _, link := range backlinks {
+			if focusedLink != nil && focusedLink.Backlink && focusedLink.CardID == link.CardID {
+				titles = append(titles, styleLinkFocused.Render(link.Label))
+			} else {
+				titles = append(titles, styleLink.Render(link.Label))
+			}
+		}
+		lines = append(lines, "", styleSubtle.Render("Linked from: ")+strings.Join(titles, styleSubtle.Render(" · ")))
+	}
+
 	// Footer/instructions
-	footer := buildDetailFooter(m, hasTemplates)
+	footer := buildDetailFooter(m, hasTemplates, len(links) > 0, len(parseCodeBlocks(content)))
 	lines = append(lines, "", footer)
 
 	finalContent := strings.Join(lines, "\n")
@@ -1146,39 +1742,111 @@ func renderDetailView(m Model) string {
 func renderTemplateForm(m Model, card *Card) string {
 	var lines []string
 
-	// Title
-	lines = append(lines, styleHelpKey.Render("Template Variables:"))
+	// Title with the active profile (its values fill the fields)
+	title := styleHelpKey.Render("Template Variables:")
+	if badge := renderProfileBadge(m); badge != "" {
+		title += "  " + badge
+	}
+	lines = append(lines, title)
 	lines = append(lines, "")
 
+	// Field errors (unused fields in untaken {{#if}} branches don't count)
+	fieldErrs, blockErr := m.templateFieldErrors(card)
+	if blockErr != nil {
+		lines = append(lines, styleError.Render("⚠ "+blockErr.Error()))
+	}
+
 	// Render input fields for each variable
-	for i, varName := range m.DetectedVars {
+	for i, decl := range m.DetectedDecls {
 		isSelected := m.TemplateFormField == i
 
-		// Label
-		label := varName + ":"
+		// Label with type hint
+		label := decl.Name
+		if decl.Type != VarText {
+			label += " (" + decl.Type + ")"
+		}
+		label += ":"
 		if isSelected {
 			label = styleSearchBox.Render("→ " + label)
 		} else {
 			label = "  " + label
 		}
 
-		// Value
-		value := m.TemplateVars[varName]
-		if value == "" {
-			value = styleSubtle.Render("(enter value)")
+		// Values still coming from the profile
+		if m.profileFilled(decl.Name) {
+			label += styleSubtle.Render("  ⎈ " + m.ActiveProfile)
 		}
-		if isSelected {
-			value = styleCardItemSelected.Render(value + "█")
+
+		// Hint that ↓ opens this field's history
+		if isSelected && m.VarHistoryIndex < 0 {
+			if n := len(m.VarHistory.suggestions(card.ID, decl.Name)); n > 0 {
+				label += styleSubtle.Render(fmt.Sprintf("  ↓ %d recent", n))
+			}
 		}
 
 		lines = append(lines, label)
-		lines = append(lines, "  "+value)
+		lines = append(lines, "  "+renderTemplateVarInput(decl, m.TemplateVars[decl.Name], isSelected, m.TextInputs["var:"+decl.Name]))
+
+		// History dropdown (↑/↓)
+		if isSelected {
+			shown, start := m.varHistoryShown(card.ID)
+			for j, value := range shown {
+				if start+j == m.VarHistoryIndex {
+					lines = append(lines, "    "+styleCardItemSelected.Render("▸ "+value))
+				} else {
+					lines = append(lines, "    "+styleSubtle.Render("  "+value))
+				}
+			}
+		}
+
+		// Inline validation (empty required fields just get a hint)
+		if err := fieldErrs[i]; err != nil {
+			if m.TemplateVars[decl.Name] == "" && decl.Err == "" {
+				lines = append(lines, "  "+styleSubtle.Render("required"))
+			} else {
+				lines = append(lines, "  "+styleError.Render("⚠ "+err.Error()))
+			}
+		}
+	}
+
+	// Resolved built-ins ({{@date}}, {{@uuid}}...) on one line
+	if len(m.BuiltinResults) > 0 {
+		var parts, failed []string
+		for _, result := range m.BuiltinResults {
+			if result.Err != nil {
+				failed = append(failed, result.Raw+": "+result.Err.Error())
+			} else {
+				parts = append(parts, result.Raw+" = "+result.Value)
+			}
+		}
+		// Failed built-ins block the copy, so they're errors rather than values
+		if len(failed) > 0 {
+			lines = append(lines, styleError.Render(truncate("⚠ Built-in failed: "+strings.Join(failed, ", "), max(20, m.Width-4))))
+		} else {
+			lines = append(lines, styleSubtle.Render(truncate("Built-in: "+strings.Join(parts, ", "), max(20, m.Width-4))))
+		}
+	} else if m.hasBuiltinVars() {
+		lines = append(lines, styleSubtle.Render("Built-in: resolving..."))
+	}
+
+	// Secrets are only looked up when copying - never shown
+	if names := secretNames(m.expandCard(card).Content); len(names) > 0 {
+		var parts []string
+		for _, name := range names {
+			parts = append(parts, name+" = "+secretMask)
+		}
+		lines = append(lines, styleSubtle.Render(truncate("🔒 Secret (looked up on copy from "+
+			strings.Join(m.Config.Secrets.Providers, ", ")+"): "+strings.Join(parts, ", "), max(20, m.Width-4))))
 	}
 
-	// Preview of filled template
+	// Preview of filled template (the content above is filled in unless Ctrl+P shows it raw)
+	if m.showFilledPreview(card) {
+		return strings.Join(lines, "\n")
+	}
 	lines = append(lines, "")
 	lines = append(lines, styleHelpKey.Render("Preview:"))
-	filledContent := FillTemplate(card.Content, m.TemplateVars)
+	content := m.expandCard(card).Content
+	filledContent := FillTemplate(content, maskSecrets(content, m.TemplateVars))
 
 	// Show first few lines of filled content
 	previewLines := strings.Split(filledContent, "\n")
@@ -1193,15 +1861,68 @@ func renderTemplateForm(m Model, card *Card) string {
 	return strings.Join(lines, "\n")
 }
 
+// renderTemplateVarInput renders one template field: choice lists, bool checkbox or text input
+// (in is the text input's editing state, nil until it's been typed into)
+func renderTemplateVarInput(decl TemplateVar, value string, focused bool, in *textInput) string {
+	switch decl.Type {
+	case VarChoice:
+		selected := decl.Resolve(value)
+		var options []string
+		for _, option := range decl.Options {
+			if option == selected {
+				options = append(options, styleCardTitleSelected.Render("(•) "+option))
+			} else {
+				options = append(options, styleSubtle.Render("( ) "+option))
+			}
+		}
+		list := strings.Join(options, "  ")
+		if focused {
+			list += styleSubtle.Render("  ←/→")
+		}
+		return list
+
+	case VarBool:
+		box := "[ ] no"
+		if decl.Resolve(value) == "true" {
+			box = "[x] yes"
+		}
+		if focused {
+			return styleCardItemSelected.Render(box) + styleSubtle.Render("  Space")
+		}
+		return box
+	}
+
+	placeholder := styleSubtle.Render("(enter value)")
+	if decl.Default != "" {
+		placeholder = styleSubtle.Render(decl.Default + " (default)")
+	} else if decl.Type == VarList {
+		placeholder = styleSubtle.Render("(comma-separated)")
+	}
+	return in.View(value, placeholder, focused)
+}
+
 // buildDetailFooter creates the footer with keyboard shortcuts
-func buildDetailFooter(m Model, hasTemplates bool) string {
+func buildDetailFooter(m Model, hasTemplates bool, hasLinks bool, codeBlocks int) string {
 	var hints []string
 
+	// Notifications (copied, can't copy yet, profile switched...) replace the hints for 5 seconds
+	if m.ReloadMessage != "" && time.Since(m.ReloadMessageTime) < 5*time.Second {
+		return styleHelpKey.Render(m.ReloadMessage)
+	}
+
 	if hasTemplates && m.ShowTemplateForm {
 		hints = []string{
 			styleHelpKey.Render("Tab") + styleHelpDesc.Render(" next field"),
 			styleHelpKey.Render("Enter") + styleHelpDesc.Render(" copy filled"),
-			styleHelpKey.Render("t") + styleHelpDesc.Render(" hide form"),
+			styleHelpKey.Render("Ctrl+T") + styleHelpDesc.Render(" hide form"),
+		}
+		if m.ShowRawTemplate {
+			hints = append(hints, styleHelpKey.Render("Ctrl+P") + styleHelpDesc.Render(" filled"))
+		} else {
+			hints = append(hints, styleHelpKey.Render("Ctrl+P") + styleHelpDesc.Render(" raw"))
+		}
+		if len(m.Config.Profiles) > 0 {
+			hints = append(hints, styleHelpKey.Render("Ctrl+O") + styleHelpDesc.Render(" profile"))
 		}
 	} else if hasTemplates {
 		hints = []string{
@@ -1214,6 +1935,38 @@ func buildDetailFooter(m Model, hasTemplates bool) string {
 		}
 	}
 
+	// Code blocks
+	if codeBlocks > 0 && !m.ShowTemplateForm {
+		keys := "Alt+1"
+		if codeBlocks > 1 {
+			keys = fmt.Sprintf("Alt+1-%d", min(codeBlocks, maxCodeBlockKeys))
+		}
+		hints = append(hints, styleHelpKey.Render(keys) + styleHelpDesc.Render(" copy block"))
+	}
+
+	// Running shell cards (r types into the template form while it's shown)
+	if card := m.getSelectedCard(); card != nil {
+		if _, err := m.runnableBlock(card, card.Content); err == nil {
+			key := "r"
+			if m.ShowTemplateForm {
+				key = "Ctrl+X"
+			}
+			hints = append(hints, styleHelpKey.Render(key) + styleHelpDesc.Render(" run"))
+		}
+	}
+
+	// Link navigation (Tab belongs to the template form while it's shown)
+	if hasLinks && !m.ShowTemplateForm {
+		if m.DetailLinkIndex >= 0 {
+			hints = append(hints, styleHelpKey.Render("Enter") + styleHelpDesc.Render(" follow link"))
+		} else {
+			hints = append(hints, styleHelpKey.Render("Tab") + styleHelpDesc.Render(" links"))
+		}
+	}
+	if len(m.DetailBackStack) > 0 && !m.ShowTemplateForm {
+		hints = append(hints, styleHelpKey.Render("b") + styleHelpDesc.Render(" prev card"))
+	}
+
 	// Common shortcuts
 	if m.UseMarkdownRender {
 		hints = append(hints, styleHelpKey.Render("m") + styleHelpDesc.Render(" plain text"))
@@ -1253,17 +2006,11 @@ func renderMarkdown(content string, width int) string {
 		return content
 	}
 
-	// Create cache key from content length + first/last 100 chars
-	// This is more robust than just first 100 chars
-	contentKey := fmt.Sprintf("%d", len(content))
-	if len(content) > 200 {
-		contentKey = fmt.Sprintf("%s_%s_%d",
-			content[:100],
-			content[len(content)-100:],
-			len(content))
-	} else {
-		contentKey = content
-	}
+	// Create cache key from content length + a hash of all of it
+	// (the live template preview changes content in the middle as you type)
+	hash := fnv.New64a()
+	hash.Write([]byte(content))
+	contentKey := fmt.Sprintf("%d_%x", len(content), hash.Sum64())
 
 	// Check cache - must match both content AND width
 	if cached, ok := markdownCache[contentKey]; ok && cached.width == width {